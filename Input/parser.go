@@ -0,0 +1,263 @@
+package Input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// entry is one `key = value` line of a section
+type entry struct {
+	value string
+	line  int
+	used  bool
+}
+
+// section is a `[name]` block of the input file
+type section struct {
+	name    string
+	line    int
+	keys    []string
+	entries map[string]*entry
+	errs    *[]error
+	source  string
+}
+
+// document is the parsed input file: sections of `key = value` pairs, '#' comments
+type document struct {
+	source   string
+	sections map[string]*section
+	order    []string
+	errs     []error
+}
+
+// parse reads the TOML-like input format. Keys outside a section, repeated sections
+// or keys and malformed lines are reported with their line numbers.
+func parse(r io.Reader, source string) (*document, error) {
+	doc := &document{source: source, sections: make(map[string]*section)}
+	var current *section
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed section header %q", source, lineNo, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("%s:%d: empty section name", source, lineNo)
+			}
+			if prev, ok := doc.sections[name]; ok {
+				return nil, fmt.Errorf("%s:%d: section [%s] already defined on line %d", source, lineNo, name, prev.line)
+			}
+			current = &section{name: name, line: lineNo, entries: make(map[string]*entry), errs: &doc.errs, source: source}
+			doc.sections[name] = current
+			doc.order = append(doc.order, name)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected `key = value`, got %q", source, lineNo, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: missing key before '='", source, lineNo)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("%s:%d: missing value for %s", source, lineNo, key)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("%s:%d: %s is outside of any [section]", source, lineNo, key)
+		}
+		if prev, ok := current.entries[key]; ok {
+			return nil, fmt.Errorf("%s:%d: %s already set on line %d", source, lineNo, key, prev.line)
+		}
+		current.entries[key] = &entry{value: unquote(value), line: lineNo}
+		current.keys = append(current.keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", source, err)
+	}
+	return doc, nil
+}
+
+// stripComment removes a trailing '#' comment outside of double quotes and trims the line
+func stripComment(line string) string {
+	inQuotes := false
+	for i, c := range line {
+		switch c {
+		case '"':
+			inQuotes = !inQuotes
+		case '#':
+			if !inQuotes {
+				return strings.TrimSpace(line[:i])
+			}
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// section returns the named section, recording an error when a required one is missing
+func (d *document) section(name string, required bool) *section {
+	s, ok := d.sections[name]
+	if !ok {
+		if required {
+			d.errs = append(d.errs, fmt.Errorf("%s: missing section [%s]", d.source, name))
+		}
+		return nil
+	}
+	return s
+}
+
+// unused reports every section and key that was never read, in file order
+func (d *document) unused(known map[string]bool) {
+	for _, name := range d.order {
+		s := d.sections[name]
+		if !known[name] {
+			d.errs = append(d.errs, fmt.Errorf("%s:%d: unknown section [%s]", d.source, s.line, name))
+			continue
+		}
+		for _, key := range s.keys {
+			if e := s.entries[key]; !e.used {
+				d.errs = append(d.errs, fmt.Errorf("%s:%d: unknown key %s in [%s]", d.source, e.line, key, name))
+			}
+		}
+	}
+}
+
+func (s *section) fail(line int, format string, args ...any) {
+	*s.errs = append(*s.errs, fmt.Errorf("%s:%d: "+format, append([]any{s.source, line}, args...)...))
+}
+
+// lookup returns the entry for key, recording an error when a required key is missing
+func (s *section) lookup(key string, required bool) *entry {
+	e, ok := s.entries[key]
+	if !ok {
+		if required {
+			s.fail(s.line, "[%s] is missing %s", s.name, key)
+		}
+		return nil
+	}
+	e.used = true
+	return e
+}
+
+// onlyFor records an error for each of keys that is set although it only applies to valid,
+// e.g. a finite-difference order in a [kinetic] section of another type
+func (s *section) onlyFor(valid string, keys ...string) {
+	for _, key := range keys {
+		if e := s.lookup(key, false); e != nil {
+			s.fail(e.line, "%s is only valid for %s", key, valid)
+		}
+	}
+}
+
+func (s *section) float(key string, required bool, def float64) float64 {
+	e := s.lookup(key, required)
+	if e == nil {
+		return def
+	}
+	value, err := strconv.ParseFloat(e.value, 64)
+	if err != nil {
+		s.fail(e.line, "%s must be a number, got %q", key, e.value)
+	}
+	return value
+}
+
+func (s *section) positive(key string, required bool, def float64) float64 {
+	value := s.float(key, required, def)
+	if e := s.entries[key]; e != nil && value <= 0 {
+		s.fail(e.line, "%s must be positive, got %g", key, value)
+	}
+	return value
+}
+
+func (s *section) count(key string, required bool, def int) int {
+	e := s.lookup(key, required)
+	if e == nil {
+		return def
+	}
+	value, err := strconv.Atoi(e.value)
+	if err != nil || value <= 0 {
+		s.fail(e.line, "%s must be a positive integer, got %q", key, e.value)
+	}
+	return value
+}
+
+// smallCount is count for values stored in a uint8, which must not exceed 255
+func (s *section) smallCount(key string, required bool, def int) uint8 {
+	value := s.count(key, required, def)
+	if e := s.entries[key]; e != nil && value > math.MaxUint8 {
+		s.fail(e.line, "%s must be at most %d, got %d", key, math.MaxUint8, value)
+		return 0
+	}
+	return uint8(value)
+}
+
+func (s *section) floats(key string, required bool) []float64 {
+	e := s.lookup(key, required)
+	if e == nil {
+		return nil
+	}
+	list := strings.TrimSpace(e.value)
+	if !strings.HasPrefix(list, "[") || !strings.HasSuffix(list, "]") {
+		s.fail(e.line, "%s must be a list like [1, 2, 3], got %q", key, e.value)
+		return nil
+	}
+	var values []float64
+	for _, field := range strings.Split(list[1:len(list)-1], ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			s.fail(e.line, "%s contains %q, which is not a number", key, field)
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// choice returns the value of key, which must be one of options
+func (s *section) choice(key string, required bool, def string, options ...string) string {
+	e := s.lookup(key, required)
+	if e == nil {
+		return def
+	}
+	for _, option := range options {
+		if e.value == option {
+			return e.value
+		}
+	}
+	s.fail(e.line, "%s must be one of %s, got %q", key, strings.Join(options, ", "), e.value)
+	return e.value
+}
+
+func (s *section) text(key string, required bool) (string, int) {
+	e := s.lookup(key, required)
+	if e == nil {
+		return "", s.line
+	}
+	return e.value, e.line
+}
@@ -0,0 +1,302 @@
+package Input
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Simulation is a complete calculation read from one input file:
+//
+//	[grid]        rMin, rMax, nPoints
+//	[time]        macroDt, macroSteps, microSteps        (optional)
+//	[system]      mass
+//	[potential]   type and the fields of the gridData potential, e.g. De, Alpha, Cen
+//	[kinetic]     type = dvr | fourier | fd              (optional, dvr)
+//	              domain = full | half | ring            (dvr only, full)
+//	              order, boundary = dirichlet | periodic (fd only, 4 and dirichlet)
+//	[solver]      type and its parameters                (optional, diagonalize)
+//	[initial]     x0, p0, sigma                          (optional)
+type Simulation struct {
+	Grid      GridInput
+	Time      *TimeInput
+	Mass      float64
+	Potential PotentialInput
+	Kinetic   KineticInput
+	Solver    SolverInput
+	Initial   InitialInput
+}
+
+type GridInput struct {
+	RMin    float64
+	RMax    float64
+	NPoints int
+}
+
+type TimeInput struct {
+	MacroDt    float64
+	MacroSteps int
+	MicroSteps int
+}
+
+// PotentialInput holds the potential type and the constructed potential
+type PotentialInput struct {
+	Type string
+	Op   gridData.PotentialOp[float64]
+}
+
+// KineticInput is the kinetic representation; Domain applies to the sinc DVR, Order and
+// Boundary to finite differences
+type KineticInput struct {
+	Type     string
+	Domain   OperatorAlgebra.Domain
+	Order    int
+	Boundary OperatorAlgebra.Boundary
+}
+
+type SolverInput struct {
+	Type      string
+	NStates   int
+	Tolerance float64
+	MaxIter   int
+	DTau      float64
+}
+
+// InitialInput is the starting Gaussian wavepacket, or the classical initial condition
+type InitialInput struct {
+	X0    float64
+	P0    float64
+	Sigma float64
+}
+
+var knownSections = map[string]bool{
+	"grid": true, "time": true, "system": true, "potential": true,
+	"kinetic": true, "solver": true, "initial": true,
+}
+
+// ReadFile reads and validates a simulation input file. Relative paths inside it,
+// such as a tabulated potential, are resolved against the file's directory.
+func ReadFile(filename string) (*Simulation, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", filename, err)
+	}
+	defer file.Close()
+	return Parse(file, filename, filepath.Dir(filename))
+}
+
+// Parse reads and validates a simulation input; every problem found is reported
+// with its line number
+func Parse(r io.Reader, source, baseDir string) (*Simulation, error) {
+	doc, err := parse(r, source)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{}
+	if s := doc.section("grid", true); s != nil {
+		sim.Grid = GridInput{
+			RMin:    s.float("rMin", true, 0),
+			RMax:    s.float("rMax", true, 0),
+			NPoints: s.count("nPoints", true, 0),
+		}
+		if sim.Grid.RMax <= sim.Grid.RMin && len(doc.errs) == 0 {
+			s.fail(s.entries["rMax"].line, "rMax = %g must be larger than rMin = %g", sim.Grid.RMax, sim.Grid.RMin)
+		}
+	}
+
+	if s := doc.section("time", false); s != nil {
+		sim.Time = &TimeInput{
+			MacroDt:    s.positive("macroDt", true, 0),
+			MacroSteps: s.count("macroSteps", true, 0),
+			MicroSteps: s.count("microSteps", true, 0),
+		}
+	}
+
+	if s := doc.section("system", true); s != nil {
+		sim.Mass = s.positive("mass", true, 0)
+	}
+
+	if s := doc.section("potential", true); s != nil {
+		sim.Potential = parsePotential(s, baseDir)
+	}
+
+	sim.Kinetic = KineticInput{Type: "dvr", Order: 4, Boundary: OperatorAlgebra.Dirichlet}
+	if s := doc.section("kinetic", false); s != nil {
+		sim.Kinetic = parseKinetic(s)
+	}
+
+	sim.Solver = SolverInput{Type: "diagonalize", NStates: 1, Tolerance: 1e-8, MaxIter: 1000}
+	if s := doc.section("solver", false); s != nil {
+		sim.Solver = parseSolver(s)
+	}
+
+	sim.Initial = InitialInput{Sigma: 1}
+	if s := doc.section("initial", false); s != nil {
+		sim.Initial = InitialInput{
+			X0:    s.float("x0", false, 0),
+			P0:    s.float("p0", false, 0),
+			Sigma: s.positive("sigma", false, 1),
+		}
+	}
+
+	doc.unused(knownSections)
+	if len(doc.errs) > 0 {
+		return nil, errors.Join(doc.errs...)
+	}
+	return sim, nil
+}
+
+func parsePotential(s *section, baseDir string) PotentialInput {
+	potType := s.choice("type", true, "", "harmonic", "morse", "softCore", "gaussian",
+		"multiGaussian", "superGaussian", "polynomial", "tabulated")
+
+	var op gridData.PotentialOp[float64]
+	switch potType {
+	case "harmonic":
+		op = gridData.Harmonic[float64]{
+			Cen:        s.float("Cen", false, 0),
+			ForceConst: s.float("ForceConst", true, 0),
+		}
+	case "morse":
+		op = gridData.Morse[float64]{
+			De:    s.float("De", true, 0),
+			Alpha: s.float("Alpha", true, 0),
+			Cen:   s.float("Cen", false, 0),
+		}
+	case "softCore":
+		op = gridData.SoftCore[float64]{
+			Charge:    s.float("Charge", true, 0),
+			Centre:    s.float("Centre", false, 0),
+			SoftParam: s.positive("SoftParam", true, 0),
+		}
+	case "gaussian":
+		op = gridData.Gaussian[float64]{
+			Cen:      s.float("Cen", false, 0),
+			Sigma:    s.positive("Sigma", true, 0),
+			Strength: s.float("Strength", true, 0),
+		}
+	case "multiGaussian":
+		op = gridData.MultiGaussian[float64]{
+			Sigma:    s.positive("Sigma", true, 0),
+			Strength: s.float("Strength", true, 0),
+			NumGauss: s.smallCount("NumGauss", true, 0),
+			Gap:      s.float("Gap", true, 0),
+		}
+	case "superGaussian":
+		op = gridData.SuperGaussian[float64]{
+			Cen:      s.float("Cen", false, 0),
+			Sigma:    s.positive("Sigma", true, 0),
+			Strength: s.float("Strength", true, 0),
+			Order:    s.smallCount("Order", true, 0),
+		}
+	case "polynomial":
+		op = gridData.Polynomial[float64]{Coeffs: s.floats("Coeffs", true)}
+	case "tabulated":
+		op = parseTabulated(s, baseDir)
+	}
+	return PotentialInput{Type: potType, Op: op}
+}
+
+func parseTabulated(s *section, baseDir string) gridData.PotentialOp[float64] {
+	file, line := s.text("file", true)
+	method := gridData.CubicSpline
+	if s.choice("interpolation", false, "cubic", "cubic", "akima") == "akima" {
+		method = gridData.AkimaSpline
+	}
+	extrapolation := map[string]gridData.Extrapolation{
+		"constant": gridData.ExtrapolateConstant,
+		"linear":   gridData.ExtrapolateLinear,
+	}
+	left := s.choice("extrapolateLeft", false, "constant", "constant", "linear")
+	right := s.choice("extrapolateRight", false, "constant", "constant", "linear")
+	if file == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(baseDir, file)
+	}
+	tab, err := gridData.NewTabulatedFromFile(file, method)
+	if err != nil {
+		s.fail(line, "%v", err)
+		return nil
+	}
+	tab.Left, tab.Right = extrapolation[left], extrapolation[right]
+	return tab
+}
+
+// parseKinetic reads [kinetic]; domain is only accepted for the DVR, order and boundary
+// only for finite differences
+func parseKinetic(s *section) KineticInput {
+	kinetic := KineticInput{
+		Type:     s.choice("type", true, "dvr", "dvr", "fourier", "fd"),
+		Order:    4,
+		Boundary: OperatorAlgebra.Dirichlet,
+	}
+	switch kinetic.Type {
+	case "dvr":
+		domains := map[string]OperatorAlgebra.Domain{
+			"full": OperatorAlgebra.FullLine, "half": OperatorAlgebra.HalfLine, "ring": OperatorAlgebra.Ring,
+		}
+		kinetic.Domain = domains[s.choice("domain", false, "full", "full", "half", "ring")]
+		s.onlyFor("type = fd", "order", "boundary")
+		return kinetic
+	case "fourier":
+		s.onlyFor("type = dvr", "domain")
+		s.onlyFor("type = fd", "order", "boundary")
+		return kinetic
+	}
+	s.onlyFor("type = dvr", "domain")
+
+	kinetic.Order = s.count("order", false, 4)
+	if e := s.entries["order"]; e != nil && kinetic.Order%2 != 0 {
+		s.fail(e.line, "order must be even, got %d", kinetic.Order)
+	}
+	if s.choice("boundary", false, "dirichlet", "dirichlet", "periodic") == "periodic" {
+		kinetic.Boundary = OperatorAlgebra.Periodic
+	}
+	return kinetic
+}
+
+func parseSolver(s *section) SolverInput {
+	solver := SolverInput{
+		Type: s.choice("type", true, "", "diagonalize", "lanczos", "davidson", "imaginaryTime",
+			"splitOperator", "md"),
+		NStates:   s.count("nStates", false, 1),
+		Tolerance: s.positive("tolerance", false, 1e-8),
+		MaxIter:   s.count("maxIter", false, 1000),
+	}
+	solver.DTau = s.positive("dTau", solver.Type == "imaginaryTime", 0)
+	return solver
+}
+
+// RadGrid builds the real-space grid
+func (sim *Simulation) RadGrid() (*gridData.RadGrid, error) {
+	return gridData.NewRGrid(sim.Grid.RMin, sim.Grid.RMax, uint32(sim.Grid.NPoints))
+}
+
+// TimeGrid builds the time grid, which requires a [time] section
+func (sim *Simulation) TimeGrid() (*gridData.TimeGrid, error) {
+	if sim.Time == nil {
+		return nil, fmt.Errorf("the input has no [time] section")
+	}
+	return gridData.NewTimeGrid(sim.Time.MacroDt, uint32(sim.Time.MacroSteps), uint32(sim.Time.MicroSteps))
+}
+
+// KineticOp builds the kinetic energy representation on the grid
+func (sim *Simulation) KineticOp(grid *gridData.RadGrid) (OperatorAlgebra.KineticOp, error) {
+	switch sim.Kinetic.Type {
+	case "dvr":
+		return OperatorAlgebra.NewKeDVRDomain(grid, sim.Mass, sim.Kinetic.Domain)
+	case "fourier":
+		return OperatorAlgebra.FFTInit(grid, sim.Mass), nil
+	case "fd":
+		return OperatorAlgebra.NewFiniteDiff(grid, sim.Mass, sim.Kinetic.Order, sim.Kinetic.Boundary)
+	}
+	return nil, fmt.Errorf("unknown kinetic representation %q", sim.Kinetic.Type)
+}
@@ -0,0 +1,85 @@
+package Input
+
+import (
+	"GoProject/gridData"
+	"strings"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	sim, err := ReadFile("testdata/morse.inp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sim.Grid != (GridInput{RMin: -4, RMax: 16, NPoints: 128}) {
+		t.Errorf("unexpected grid %+v", sim.Grid)
+	}
+	if sim.Time == nil || *sim.Time != (TimeInput{MacroDt: 0.5, MacroSteps: 20, MicroSteps: 50}) {
+		t.Errorf("unexpected time grid %+v", sim.Time)
+	}
+	if sim.Mass != 1 || sim.Kinetic.Type != "dvr" {
+		t.Errorf("unexpected mass %v or kinetic %q", sim.Mass, sim.Kinetic.Type)
+	}
+	if morse, ok := sim.Potential.Op.(gridData.Morse[float64]); !ok || morse.De != 10 || morse.Alpha != 0.5 {
+		t.Errorf("unexpected potential %v", sim.Potential.Op)
+	}
+	if sim.Solver != (SolverInput{Type: "lanczos", NStates: 4, Tolerance: 1e-9, MaxIter: 1000}) {
+		t.Errorf("unexpected solver %+v", sim.Solver)
+	}
+	if sim.Initial != (InitialInput{X0: 0.5, Sigma: 0.7}) {
+		t.Errorf("unexpected initial state %+v", sim.Initial)
+	}
+
+	grid, err := sim.RadGrid()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := sim.KineticOp(grid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	const base = "[grid]\nrMin = -1\nrMax = 1\nnPoints = 16\n[system]\nmass = 1\n[potential]\ntype = harmonic\nForceConst = 1\n"
+
+	superGaussian := strings.Replace(base, "harmonic\nForceConst = 1", "superGaussian\nSigma = 1\nStrength = 1", 1)
+
+	cases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"valid":          {base, ""},
+		"bad number":     {strings.Replace(base, "rMin = -1", "rMin = minus", 1), "test.inp:2: rMin must be a number"},
+		"unknown key":    {base + "Sigma = 2\n", "test.inp:10: unknown key Sigma in [potential]"},
+		"unknown type":   {strings.Replace(base, "harmonic", "quartic", 1), "test.inp:8: type must be one of"},
+		"missing key":    {strings.Replace(base, "mass = 1\n", "", 1), "test.inp:5: [system] is missing mass"},
+		"missing grid":   {base[strings.Index(base, "[system]"):], "missing section [grid]"},
+		"duplicate key":  {base + "ForceConst = 2\n", "test.inp:10: ForceConst already set on line 9"},
+		"no section":     {"mass = 1\n" + base, "test.inp:1: mass is outside of any [section]"},
+		"malformed line": {base + "Cen 2\n", "test.inp:10: expected `key = value`"},
+		"bad range":      {strings.Replace(base, "rMax = 1", "rMax = -2", 1), "test.inp:3: rMax = -2 must be larger"},
+		"negative mass":  {strings.Replace(base, "mass = 1", "mass = -1", 1), "test.inp:6: mass must be positive"},
+		"missing dTau":   {base + "[solver]\ntype = imaginaryTime\n", "test.inp:10: [solver] is missing dTau"},
+		"large order":    {superGaussian + "Order = 256\n", "test.inp:11: Order must be at most 255"},
+		"fd kinetic":     {base + "[kinetic]\ntype = fd\norder = 6\nboundary = periodic\n", ""},
+		"odd fd order":   {base + "[kinetic]\ntype = fd\norder = 5\n", "test.inp:12: order must be even"},
+		"order for dvr":  {base + "[kinetic]\ntype = dvr\norder = 4\n", "test.inp:12: order is only valid for type = fd"},
+		"domain for fd":  {base + "[kinetic]\ntype = fd\ndomain = half\n", "test.inp:12: domain is only valid for type = dvr"},
+		"fourier bounds": {base + "[kinetic]\ntype = fourier\nboundary = periodic\n", "test.inp:12: boundary is only valid for type = fd"},
+		"bad domain":     {base + "[kinetic]\ntype = dvr\ndomain = sphere\n", "test.inp:12: domain must be one of"},
+	}
+
+	for name, c := range cases {
+		_, err := Parse(strings.NewReader(c.input), "test.inp", ".")
+		if c.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", name, c.expected, err)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package EquationSolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// StepControl configures the step-size control of the adaptive methods. A step is accepted
+// when the RMS over components of err_i / (Atol_i + Rtol_i max(|y_i|, |y_new_i|)) is at most
+// one. Atol and Rtol hold one value per component or a single value for all components;
+// zero fields take the values of DefaultStepControl.
+type StepControl struct {
+	Atol []float64
+	Rtol []float64
+
+	// InitialStep of zero is estimated from the derivative at the start
+	InitialStep float64
+	MinStep     float64
+	MaxStep     float64
+	MaxSteps    int
+
+	// the step is scaled by Safety err^-Beta1 errPrev^Beta2 within [MinFactor, MaxFactor];
+	// Beta1 and Beta2 default to 0.7/k and 0.4/k with k one above the embedded order
+	Safety    float64
+	MinFactor float64
+	MaxFactor float64
+	Beta1     float64
+	Beta2     float64
+}
+
+// DefaultStepControl returns the control used by the named adaptive methods
+func DefaultStepControl() StepControl {
+	return StepControl{
+		Atol:      []float64{adaptiveTolerance},
+		Rtol:      []float64{adaptiveTolerance},
+		MinStep:   1e-14,
+		MaxSteps:  100000,
+		Safety:    0.9,
+		MinFactor: 0.2,
+		MaxFactor: 5,
+	}
+}
+
+// withDefaults fills the zero fields for an error estimate of the given order
+func (sc StepControl) withDefaults(estimatorOrder int) StepControl {
+	def := DefaultStepControl()
+	if sc.Atol == nil {
+		sc.Atol = def.Atol
+	}
+	if sc.Rtol == nil {
+		sc.Rtol = def.Rtol
+	}
+	if sc.MinStep == 0 {
+		sc.MinStep = def.MinStep
+	}
+	if sc.MaxSteps == 0 {
+		sc.MaxSteps = def.MaxSteps
+	}
+	if sc.Safety == 0 {
+		sc.Safety = def.Safety
+	}
+	if sc.MinFactor == 0 {
+		sc.MinFactor = def.MinFactor
+	}
+	if sc.MaxFactor == 0 {
+		sc.MaxFactor = def.MaxFactor
+	}
+	k := float64(estimatorOrder + 1)
+	if sc.Beta1 == 0 && sc.Beta2 == 0 {
+		sc.Beta1 = 0.7 / k
+		sc.Beta2 = 0.4 / k
+	}
+	return sc
+}
+
+func (sc StepControl) check(n int) error {
+	for name, tol := range map[string][]float64{"Atol": sc.Atol, "Rtol": sc.Rtol} {
+		if len(tol) != 1 && len(tol) != n {
+			return fmt.Errorf("%s has %d values, expected 1 or %d", name, len(tol), n)
+		}
+		for _, v := range tol {
+			if v < 0 {
+				return fmt.Errorf("%s must not be negative", name)
+			}
+		}
+	}
+	return nil
+}
+
+// controller is the PI step-size controller; errPrev carries the error of the last accepted step
+type controller struct {
+	StepControl
+	order    float64
+	errPrev  float64
+	rejected bool
+}
+
+func newController(sc StepControl, estimatorOrder int) controller {
+	return controller{
+		StepControl: sc.withDefaults(estimatorOrder),
+		order:       float64(estimatorOrder + 1),
+		errPrev:     1e-4,
+	}
+}
+
+func tolAt(tol []float64, i int) float64 {
+	if len(tol) == 1 {
+		return tol[0]
+	}
+	return tol[i]
+}
+
+// errorNorm returns the RMS of the scaled difference between the two embedded solutions
+func (c *controller) errorNorm(y, yNew, yLow []float64) float64 {
+	sum := 0.
+	for i := range y {
+		scale := tolAt(c.Atol, i) + tolAt(c.Rtol, i)*math.Max(math.Abs(y[i]), math.Abs(yNew[i]))
+		e := (yNew[i] - yLow[i]) / scale
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(len(y)))
+}
+
+// scaledNorm returns the RMS of v_i / (Atol_i + Rtol_i |y_i|)
+func (c *controller) scaledNorm(v, y []float64) float64 {
+	sum := 0.
+	for i := range v {
+		e := v[i] / (tolAt(c.Atol, i) + tolAt(c.Rtol, i)*math.Abs(y[i]))
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(len(v)))
+}
+
+// accept returns the next step after an accepted step of size h; the step does not grow
+// directly after a rejection
+func (c *controller) accept(errNorm, h float64) float64 {
+	errNorm = math.Max(errNorm, 1e-10)
+	factor := c.Safety * math.Pow(errNorm, -c.Beta1) * math.Pow(c.errPrev, c.Beta2)
+	maxFactor := c.MaxFactor
+	if c.rejected {
+		maxFactor = 1
+	}
+	c.errPrev = math.Max(errNorm, 1e-4)
+	c.rejected = false
+	return h * math.Min(maxFactor, math.Max(c.MinFactor, factor))
+}
+
+// reject returns the shrunk step after a rejected step of size h
+func (c *controller) reject(errNorm, h float64) float64 {
+	c.rejected = true
+	factor := c.Safety * math.Pow(errNorm, -1/c.order)
+	return h * math.Max(c.MinFactor, math.Min(factor, c.Safety))
+}
+
+// IntegrationStats reports the work of an adaptive integration
+type IntegrationStats struct {
+	Accepted    int
+	Rejected    int
+	Evaluations int
+	// Jacobians and Factorizations count the Jacobian evaluations and LU decompositions of
+	// the stiff solvers
+	Jacobians      int
+	Factorizations int
+	// LastStep is the step size proposed for a continuation
+	LastStep float64
+}
+
+// Integrator integrates a system in place over an interval with automatic step-size control
+type Integrator interface {
+	Integrate(sys ODESystem, y []float64, t0, t1 float64) (IntegrationStats, error)
+	Name() string
+}
+
+// AdaptiveIntegrator integrates a system over an interval with an embedded Runge-Kutta pair
+type AdaptiveIntegrator struct {
+	tab  *Tableau
+	ctrl StepControl
+	work stages
+
+	y0 []float64
+	f0 []float64
+	f1 []float64
+}
+
+// NewAdaptiveIntegrator creates an integrator from the embedded pair registered under key
+func NewAdaptiveIntegrator(key string, ctrl StepControl) (*AdaptiveIntegrator, error) {
+	tab, err := LookupTableau(key)
+	if err != nil {
+		return nil, err
+	}
+	if !tab.Adaptive() {
+		return nil, fmt.Errorf("tableau %q has no embedded solution for step-size control", key)
+	}
+	return &AdaptiveIntegrator{tab: tab, ctrl: ctrl.withDefaults(tab.EmbeddedOrder)}, nil
+}
+
+func (ai *AdaptiveIntegrator) Name() string      { return ai.tab.Name }
+func (ai *AdaptiveIntegrator) Tableau() *Tableau { return ai.tab }
+
+// Integrate advances y in place from t0 to t1, which may lie before t0
+func (ai *AdaptiveIntegrator) Integrate(sys ODESystem, y []float64, t0, t1 float64) (IntegrationStats, error) {
+	return ai.integrate(sys, y, t0, t1, nil)
+}
+
+// IntegrateDense advances y in place from t0 to t1 and keeps the accepted steps for
+// interpolation; the tableau must provide dense output
+func (ai *AdaptiveIntegrator) IntegrateDense(sys ODESystem, y []float64, t0, t1 float64) (*DenseSolution, error) {
+	if ai.tab.Dense == nil {
+		return nil, fmt.Errorf("%s has no dense output", ai.tab.Name)
+	}
+	sol := &DenseSolution{tab: ai.tab, t0: t0, t1: t1}
+	stats, err := ai.integrate(sys, y, t0, t1, sol)
+	sol.Stats = stats
+	return sol, err
+}
+
+func (ai *AdaptiveIntegrator) integrate(sys ODESystem, y []float64, t0, t1 float64, sol *DenseSolution) (IntegrationStats, error) {
+	var stats IntegrationStats
+	if err := checkDim(sys, y); err != nil {
+		return stats, err
+	}
+	if err := ai.ctrl.check(len(y)); err != nil {
+		return stats, err
+	}
+	if t1 == t0 {
+		return stats, nil
+	}
+
+	dir := math.Copysign(1, t1-t0)
+	ctrl := newController(ai.ctrl, ai.tab.EmbeddedOrder)
+	maxStep := ctrl.MaxStep
+	if maxStep == 0 {
+		maxStep = math.Abs(t1 - t0)
+	}
+	h := ctrl.InitialStep
+	if h == 0 {
+		if len(ai.f0) != len(y) {
+			ai.f0 = make([]float64, len(y))
+			ai.f1 = make([]float64, len(y))
+		}
+		h = initialStep(sys, y, t0, dir, &ctrl, ai.f0, ai.f1, ai.y0[:0])
+		stats.Evaluations += 2
+	}
+	h = math.Min(math.Abs(h), maxStep)
+
+	t := t0
+	for (t1-t)*dir > 0 {
+		if stats.Accepted+stats.Rejected >= ctrl.MaxSteps {
+			return stats, fmt.Errorf("no convergence to t = %g after %d steps, reached t = %g", t1, ctrl.MaxSteps, t)
+		}
+		last := (t+dir*h-t1)*dir >= 0
+		if last {
+			h = math.Abs(t1 - t)
+		}
+		if h < ctrl.MinStep {
+			return stats, fmt.Errorf("step size %g below the minimum %g at t = %g", h, ctrl.MinStep, t)
+		}
+
+		if sol != nil {
+			ai.y0 = append(ai.y0[:0], y...)
+		}
+		hNew, err := ai.work.adaptiveStep(sys, y, t, dir*h, ai.tab, &ctrl)
+		stats.Evaluations += ai.tab.Stages()
+		if errors.Is(err, ErrStepRejected) {
+			stats.Rejected++
+			h = math.Abs(hNew)
+			continue
+		}
+		if err != nil {
+			return stats, fmt.Errorf("at t = %g: %w", t, err)
+		}
+
+		stats.Accepted++
+		if sol != nil {
+			sol.record(t, dir*h, ai.y0, y, ai.work.k)
+		}
+		if last {
+			t = t1
+		} else {
+			t += dir * h
+		}
+		h = math.Min(math.Abs(hNew), maxStep)
+	}
+	stats.LastStep = h
+	return stats, nil
+}
+
+// initialStep estimates the first step from the size of y, F(t0, y) and its variation
+// along an explicit Euler step (Hairer, Norsett and Wanner, Solving ODEs I, II.4); f0 returns
+// F(t0, y) and f1 and yTmp are work buffers
+func initialStep(sys ODESystem, y []float64, t0, dir float64, ctrl *controller, f0, f1, yTmp []float64) float64 {
+	sys.Derivative(t0, y, f0)
+	d0 := ctrl.scaledNorm(y, y)
+	d1 := ctrl.scaledNorm(f0, y)
+	h0 := 1e-6
+	if d0 >= 1e-5 && d1 >= 1e-5 {
+		h0 = 0.01 * d0 / d1
+	}
+
+	yTmp = append(yTmp[:0], y...)
+	for i := range yTmp {
+		yTmp[i] += dir * h0 * f0[i]
+	}
+	sys.Derivative(t0+dir*h0, yTmp, f1)
+	for i := range f1 {
+		f1[i] -= f0[i]
+	}
+	d2 := ctrl.scaledNorm(f1, y) / h0
+
+	h1 := math.Max(1e-6, 1e-3*h0)
+	if dMax := math.Max(d1, d2); dMax > 1e-15 {
+		h1 = math.Pow(0.01/dMax, 1/ctrl.order)
+	}
+	return math.Min(100*h0, h1)
+}
+
+// DenseSolution interpolates an adaptive integration between its accepted steps
+type DenseSolution struct {
+	Stats IntegrationStats
+
+	tab    *Tableau
+	t0, t1 float64
+	steps  []denseStep
+}
+
+type denseStep struct {
+	t, h   float64
+	y0, y1 []float64
+	k      [][]float64
+}
+
+func (sol *DenseSolution) record(t, h float64, y0, y1 []float64, k [][]float64) {
+	step := denseStep{
+		t:  t,
+		h:  h,
+		y0: append([]float64(nil), y0...),
+		y1: append([]float64(nil), y1...),
+		k:  make([][]float64, len(k)),
+	}
+	for i := range k {
+		step.k[i] = append([]float64(nil), k[i]...)
+	}
+	sol.steps = append(sol.steps, step)
+}
+
+// Span returns the interval of the integration
+func (sol *DenseSolution) Span() (t0, t1 float64) { return sol.t0, sol.t1 }
+
+// Times returns the boundaries of the accepted steps
+func (sol *DenseSolution) Times() []float64 {
+	times := []float64{sol.t0}
+	for _, step := range sol.steps {
+		times = append(times, step.t+step.h)
+	}
+	if len(sol.steps) > 0 {
+		times[len(times)-1] = sol.t1
+	}
+	return times
+}
+
+// At writes the interpolated solution at t within Span into out
+func (sol *DenseSolution) At(t float64, out []float64) error {
+	if len(sol.steps) == 0 {
+		return fmt.Errorf("no steps recorded")
+	}
+	dir := math.Copysign(1, sol.t1-sol.t0)
+	if (t-sol.t0)*dir < 0 || (t-sol.t1)*dir > 0 {
+		return fmt.Errorf("t = %g is outside the integration interval [%g, %g]", t, sol.t0, sol.t1)
+	}
+	if len(out) != len(sol.steps[0].y0) {
+		return fmt.Errorf("output length %d doesn't match system dimension %d", len(out), len(sol.steps[0].y0))
+	}
+
+	i := sort.Search(len(sol.steps), func(i int) bool {
+		step := sol.steps[i]
+		return (step.t+step.h-t)*dir >= 0
+	})
+	i = min(i, len(sol.steps)-1)
+	step := sol.steps[i]
+	theta := (t - step.t) / step.h
+	sol.tab.Dense(math.Min(1, math.Max(0, theta)), step.h, step.y0, step.y1, step.k, out)
+	return nil
+}
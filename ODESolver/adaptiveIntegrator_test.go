@@ -0,0 +1,138 @@
+package EquationSolver
+
+import (
+	"math"
+	"testing"
+)
+
+func riccatiAt(t float64) []float64 { return []float64{1 / (1 + t*t), math.Atan(t)} }
+
+func TestAdaptiveIntegrator_Accuracy(t *testing.T) {
+	const t1 = 3.
+	exact := riccatiAt(t1)
+	for _, key := range []string{"heun-euler", "bogacki-shampine", "fehlberg45", "cash-karp", "dormand-prince", "verner65", "tsitouras54"} {
+		var previous IntegrationStats
+		for _, tol := range []float64{1e-6, 1e-9} {
+			ai, err := NewAdaptiveIntegrator(key, StepControl{Atol: []float64{tol}, Rtol: []float64{tol}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			y := []float64{1, 0}
+			stats, err := ai.Integrate(riccati, y, 0, t1)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", key, err)
+			}
+
+			if e := math.Hypot(y[0]-exact[0], y[1]-exact[1]); e > 100*tol {
+				t.Errorf("%s, tol %g: expected y(%g) = %v, off by %v", key, tol, t1, exact, e)
+			}
+			if expected := 2 + (stats.Accepted+stats.Rejected)*ai.Tableau().Stages(); stats.Evaluations != expected {
+				t.Errorf("%s: expected %d evaluations, got %d", key, expected, stats.Evaluations)
+			}
+			if stats.Accepted <= previous.Accepted {
+				t.Errorf("%s: expected a tighter tolerance to take more steps than %d, got %d", key, previous.Accepted, stats.Accepted)
+			}
+			previous = stats
+		}
+	}
+}
+
+func TestAdaptiveIntegrator_Control(t *testing.T) {
+	// a too large first step is rejected before the controller settles
+	ai, err := NewAdaptiveIntegrator("dormand-prince", StepControl{InitialStep: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	y := []float64{1, 0}
+	stats, err := ai.Integrate(oscillator, y, 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Rejected == 0 || stats.Accepted == 0 || stats.LastStep <= 0 {
+		t.Errorf("expected accepted and rejected steps and a step proposal, got %+v", stats)
+	}
+
+	// integrating back to the start recovers the initial state
+	if _, err := ai.Integrate(oscillator, y, 10, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(y[0]-1) > 1e-6 || math.Abs(y[1]) > 1e-6 {
+		t.Errorf("expected to return to (1, 0), got %v", y)
+	}
+
+	// a loose tolerance on the second component lets its error grow but not the first one's
+	loose, err := NewAdaptiveIntegrator("bogacki-shampine", StepControl{Atol: []float64{1e-10, 1e-3}, Rtol: []float64{1e-10, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	y = []float64{1, 0}
+	if _, err := loose.Integrate(riccati, y, 0, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(y[0]-0.5) > 1e-8 {
+		t.Errorf("expected the tight component within 1e-8 of 0.5, got %v", y[0])
+	}
+
+	if _, err := loose.Integrate(SystemFunc{N: 3, F: func(t float64, y, dydt []float64) {}}, make([]float64, 3), 0, 1); err == nil {
+		t.Errorf("expected an error for tolerances of the wrong length")
+	}
+	limited, err := NewAdaptiveIntegrator("heun-euler", StepControl{MaxSteps: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := limited.Integrate(oscillator, []float64{1, 0}, 0, 10); err == nil {
+		t.Errorf("expected an error when MaxSteps is exceeded")
+	}
+	if _, err := NewAdaptiveIntegrator("rk4", StepControl{}); err == nil {
+		t.Errorf("expected an error for a tableau without an embedded solution")
+	}
+}
+
+func TestAdaptiveIntegrator_DenseOutput(t *testing.T) {
+	for _, key := range []string{"bogacki-shampine", "dormand-prince"} {
+		ai, err := NewAdaptiveIntegrator(key, StepControl{Atol: []float64{1e-9}, Rtol: []float64{1e-9}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		y := []float64{1, 0}
+		sol, err := ai.IntegrateDense(riccati, y, 0, 3)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", key, err)
+		}
+
+		times := sol.Times()
+		if len(times) != sol.Stats.Accepted+1 || times[0] != 0 || times[len(times)-1] != 3 {
+			t.Fatalf("%s: expected %d step boundaries from 0 to 3, got %v", key, sol.Stats.Accepted+1, times)
+		}
+		out := make([]float64, 2)
+		if err := sol.At(3, out); err != nil || out[0] != y[0] || out[1] != y[1] {
+			t.Errorf("%s: expected the interpolant to end at the final state %v, got %v (%v)", key, y, out, err)
+		}
+
+		// the interpolant is accurate between the step boundaries, not only at them
+		maxErr := 0.
+		for i := 0; i <= 1000; i++ {
+			tt := 3 * float64(i) / 1000
+			if err := sol.At(tt, out); err != nil {
+				t.Fatalf("%s: unexpected error: %v", key, err)
+			}
+			exact := riccatiAt(tt)
+			maxErr = math.Max(maxErr, math.Hypot(out[0]-exact[0], out[1]-exact[1]))
+		}
+		if maxErr > 1e-8 {
+			t.Errorf("%s: expected dense output within 1e-8, off by %v", key, maxErr)
+		}
+
+		if err := sol.At(3.5, out); err == nil {
+			t.Errorf("%s: expected an error outside the integration interval", key)
+		}
+	}
+
+	ck, err := NewAdaptiveIntegrator("cash-karp", StepControl{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ck.IntegrateDense(riccati, []float64{1, 0}, 0, 1); err == nil {
+		t.Errorf("expected an error for a tableau without dense output")
+	}
+}
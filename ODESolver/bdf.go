@@ -0,0 +1,352 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	bdfMaxOrder   = 5
+	bdfNewtonIter = 4
+)
+
+// BDF is the variable-order, variable-step backward differentiation formula of orders 1 to
+// maxOrder in the quasi-constant step form of Shampine and Reichelt, the method of MATLAB's
+// ode15s without its NDF modification. The solution history is kept as backward differences
+// that are rescaled whenever the step changes; the order moves by one at most once every
+// order + 1 steps of equal size, towards the order allowing the largest next step.
+type BDF struct {
+	maxOrder int
+	ctrl     StepControl
+	jw       jacobianWork
+	im       iterationMatrix
+
+	gamma      []float64
+	errorConst []float64
+	d          [][]float64
+	dNew       [][]float64
+
+	f     []float64
+	yPred []float64
+	yNew  []float64
+	psi   []float64
+	dy    []float64
+	corr  []float64
+	scale []float64
+	tmp   []float64
+}
+
+// NewBDF creates the BDF integrator of orders 1 to maxOrder, at most 5
+func NewBDF(maxOrder int, ctrl StepControl) (*BDF, error) {
+	if maxOrder < 1 || maxOrder > bdfMaxOrder {
+		return nil, fmt.Errorf("BDF order must be between 1 and %d, got %d", bdfMaxOrder, maxOrder)
+	}
+	bdf := &BDF{maxOrder: maxOrder, ctrl: ctrl.withDefaults(1)}
+	// gamma_k = Sum_{j <= k} 1/j weighs the differences in the corrector of order k, whose
+	// local error is 1/(k + 1) times the difference of order k + 1
+	bdf.gamma = make([]float64, maxOrder+2)
+	bdf.errorConst = make([]float64, maxOrder+2)
+	for k := range bdf.gamma {
+		if k > 0 {
+			bdf.gamma[k] = bdf.gamma[k-1] + 1/float64(k)
+		}
+		bdf.errorConst[k] = 1 / float64(k+1)
+	}
+	return bdf, nil
+}
+
+func (bdf *BDF) Name() string { return fmt.Sprintf("BDF (1-%d)", bdf.maxOrder) }
+
+func (bdf *BDF) allocate(n int) {
+	if len(bdf.f) != n {
+		bdf.d = make([][]float64, bdf.maxOrder+3)
+		bdf.dNew = make([][]float64, bdf.maxOrder+3)
+		for i := range bdf.d {
+			bdf.d[i] = make([]float64, n)
+			bdf.dNew[i] = make([]float64, n)
+		}
+		bdf.f = make([]float64, n)
+		bdf.yPred = make([]float64, n)
+		bdf.yNew = make([]float64, n)
+		bdf.psi = make([]float64, n)
+		bdf.dy = make([]float64, n)
+		bdf.corr = make([]float64, n)
+		bdf.scale = make([]float64, n)
+		bdf.tmp = make([]float64, n)
+	}
+}
+
+// rescale changes the differences up to the given order to those of a step factor times
+// the current one
+func (bdf *BDF) rescale(order int, factor float64) {
+	ru := bdfRU(order, factor)
+	for i := 0; i <= order; i++ {
+		for r := range bdf.dNew[i] {
+			sum := 0.
+			for j := 0; j <= order; j++ {
+				sum += ru[j][i] * bdf.d[j][r]
+			}
+			bdf.dNew[i][r] = sum
+		}
+	}
+	for i := 0; i <= order; i++ {
+		copy(bdf.d[i], bdf.dNew[i])
+	}
+}
+
+// bdfRU returns R(factor) U with U = R(1), where R_ij = prod_{m <= i} (m - 1 - factor j)/m
+// maps differences on the old grid to the grid scaled by factor
+func bdfRU(order int, factor float64) [][]float64 {
+	r := func(f float64) [][]float64 {
+		m := make([][]float64, order+1)
+		for i := range m {
+			m[i] = make([]float64, order+1)
+			for j := range m[i] {
+				m[i][j] = 1
+				for k := 1; k <= i; k++ {
+					m[i][j] *= (float64(k) - 1 - f*float64(j)) / float64(k)
+				}
+			}
+		}
+		return m
+	}
+	rf, u := r(factor), r(1)
+	ru := make([][]float64, order+1)
+	for i := range ru {
+		ru[i] = make([]float64, order+1)
+		for j := range ru[i] {
+			for k := range u {
+				ru[i][j] += rf[i][k] * u[k][j]
+			}
+		}
+	}
+	return ru
+}
+
+// newton solves the corrector equation at tNew from bdf.yPred with the factorized matrix
+// I - c J, leaving the solution in bdf.yNew and its distance from the predictor in bdf.corr
+func (bdf *BDF) newton(sys ODESystem, tNew, c, tol float64, stats *IntegrationStats) (converged bool, iterations int, err error) {
+	copy(bdf.yNew, bdf.yPred)
+	for r := range bdf.corr {
+		bdf.corr[r] = 0
+	}
+	normPrev, rate := 0., 0.
+	for k := 0; k < bdfNewtonIter; k++ {
+		iterations = k + 1
+		sys.Derivative(tNew, bdf.yNew, bdf.f)
+		stats.Evaluations++
+		if checkFinite(bdf.f) != nil {
+			return false, iterations, nil
+		}
+		for r := range bdf.dy {
+			bdf.dy[r] = c*bdf.f[r] - bdf.psi[r] - bdf.corr[r]
+		}
+		if err := bdf.im.solve(bdf.dy); err != nil {
+			return false, iterations, err
+		}
+		norm := rmsScaled(bdf.dy, bdf.scale)
+		if k > 0 {
+			rate = norm / normPrev
+			if rate >= 1 || math.Pow(rate, float64(bdfNewtonIter-k))/(1-rate)*norm > tol {
+				return false, iterations, nil
+			}
+		}
+		floatsAddScaled(bdf.yNew, 1, bdf.dy)
+		floatsAddScaled(bdf.corr, 1, bdf.dy)
+		if norm == 0 || (k > 0 && rate/(1-rate)*norm < tol) {
+			return true, iterations, nil
+		}
+		normPrev = norm
+	}
+	return false, iterations, nil
+}
+
+// Integrate advances y in place from t0 to t1, which may lie before t0
+func (bdf *BDF) Integrate(sys ODESystem, y []float64, t0, t1 float64) (IntegrationStats, error) {
+	var stats IntegrationStats
+	if err := checkDim(sys, y); err != nil {
+		return stats, err
+	}
+	if err := bdf.ctrl.check(len(y)); err != nil {
+		return stats, err
+	}
+	if t1 == t0 {
+		return stats, nil
+	}
+	bdf.allocate(len(y))
+
+	dir := math.Copysign(1, t1-t0)
+	ctrl := newController(bdf.ctrl, 1)
+	maxStep := ctrl.MaxStep
+	if maxStep == 0 {
+		maxStep = math.Abs(t1 - t0)
+	}
+	h := ctrl.InitialStep
+	if h == 0 {
+		h = initialStep(sys, y, t0, dir, &ctrl, bdf.f, bdf.tmp, bdf.yNew)
+		stats.Evaluations += 2
+	}
+	h = math.Min(math.Abs(h), maxStep)
+	tol := newtonTolerance(ctrl.Rtol)
+
+	sys.Derivative(t0, y, bdf.f)
+	stats.Evaluations += 1 + bdf.jw.evaluate(sys, t0, y, bdf.f)
+	stats.Jacobians++
+	for i := range bdf.d {
+		for r := range bdf.d[i] {
+			bdf.d[i][r] = 0
+		}
+	}
+	copy(bdf.d[0], y)
+	for r := range y {
+		bdf.d[1][r] = dir * h * bdf.f[r]
+	}
+
+	t, order, nEqual := t0, 1, 0
+	current, factorized := true, false
+	// a singular iteration matrix halves the step like a failed Newton iteration, lastFailure
+	// keeps its error for the error returned when the step size or step count runs out
+	var lastFailure error
+	for (t1-t)*dir > 0 {
+		var (
+			errNorm, safety float64
+			tNew            float64
+		)
+		for {
+			if stats.Accepted+stats.Rejected >= ctrl.MaxSteps {
+				return stats, withLastFailure(fmt.Errorf("no convergence to t = %g after %d steps, reached t = %g", t1, ctrl.MaxSteps, t), lastFailure)
+			}
+			if h < ctrl.MinStep {
+				return stats, withLastFailure(fmt.Errorf("step size %g below the minimum %g at t = %g", h, ctrl.MinStep, t), lastFailure)
+			}
+			if h > maxStep {
+				bdf.rescale(order, maxStep/h)
+				h, nEqual, factorized = maxStep, 0, false
+			}
+			tNew = t + dir*h
+			if (tNew-t1)*dir > 0 {
+				bdf.rescale(order, math.Abs(t1-t)/h)
+				tNew, h, nEqual, factorized = t1, math.Abs(t1-t), 0, false
+			}
+
+			// predict from the differences and solve the corrector
+			for r := range y {
+				bdf.yPred[r], bdf.psi[r] = 0, 0
+				for i := 0; i <= order; i++ {
+					bdf.yPred[r] += bdf.d[i][r]
+				}
+				for i := 1; i <= order; i++ {
+					bdf.psi[r] += bdf.d[i][r] * bdf.gamma[i]
+				}
+				bdf.psi[r] /= bdf.gamma[order]
+				bdf.scale[r] = tolAt(ctrl.Atol, r) + tolAt(ctrl.Rtol, r)*math.Abs(bdf.yPred[r])
+			}
+			c := dir * h / bdf.gamma[order]
+
+			var (
+				converged  bool
+				iterations int
+				err        error
+			)
+			for {
+				if !factorized {
+					bdf.im.factorize(1, c, bdf.jw.jac)
+					stats.Factorizations++
+					factorized = true
+				}
+				converged, iterations, err = bdf.newton(sys, tNew, c, tol, &stats)
+				if converged || err != nil || current {
+					break
+				}
+				// retry with a Jacobian at the predicted solution before shrinking the step
+				sys.Derivative(tNew, bdf.yPred, bdf.f)
+				stats.Evaluations += 1 + bdf.jw.evaluate(sys, tNew, bdf.yPred, bdf.f)
+				stats.Jacobians++
+				current, factorized = true, false
+			}
+			if err != nil {
+				lastFailure = err
+			}
+			if !converged {
+				stats.Rejected++
+				bdf.rescale(order, 0.5)
+				h, nEqual, factorized = h*0.5, 0, false
+				continue
+			}
+
+			safety = 0.9 * (2*bdfNewtonIter + 1) / float64(2*bdfNewtonIter+iterations)
+			for r := range y {
+				bdf.scale[r] = tolAt(ctrl.Atol, r) + tolAt(ctrl.Rtol, r)*math.Abs(bdf.yNew[r])
+				bdf.tmp[r] = bdf.errorConst[order] * bdf.corr[r]
+			}
+			errNorm = rmsScaled(bdf.tmp, bdf.scale)
+			if errNorm <= 1 {
+				break
+			}
+			stats.Rejected++
+			factor := math.Max(ctrl.MinFactor, safety*math.Pow(errNorm, -1/float64(order+1)))
+			bdf.rescale(order, factor)
+			h, nEqual = h*factor, 0
+		}
+
+		stats.Accepted++
+		nEqual++
+		t = tNew
+		lastFailure = nil
+		copy(y, bdf.yNew)
+		current = false
+
+		// the corrector difference completes the differences of the new point
+		for r := range y {
+			bdf.d[order+2][r] = bdf.corr[r] - bdf.d[order+1][r]
+			bdf.d[order+1][r] = bdf.corr[r]
+		}
+		for i := order; i >= 0; i-- {
+			floatsAddScaled(bdf.d[i], 1, bdf.d[i+1])
+		}
+		if nEqual < order+1 {
+			continue
+		}
+
+		// compare the steps allowed at orders order - 1, order and order + 1
+		errs := [3]float64{math.Inf(1), errNorm, math.Inf(1)}
+		if order > 1 {
+			for r := range y {
+				bdf.tmp[r] = bdf.errorConst[order-1] * bdf.d[order][r]
+			}
+			errs[0] = rmsScaled(bdf.tmp, bdf.scale)
+		}
+		if order < bdf.maxOrder {
+			for r := range y {
+				bdf.tmp[r] = bdf.errorConst[order+1] * bdf.d[order+2][r]
+			}
+			errs[2] = rmsScaled(bdf.tmp, bdf.scale)
+		}
+		best, bestFactor := 0, 0.
+		for i, e := range errs {
+			f := math.Inf(1)
+			if e > 0 {
+				f = math.Pow(e, -1/float64(order+i))
+			}
+			if f > bestFactor {
+				best, bestFactor = i, f
+			}
+		}
+		order += best - 1
+		factor := math.Min(ctrl.MaxFactor, safety*bestFactor)
+		bdf.rescale(order, factor)
+		h, nEqual, factorized = h*factor, 0, false
+	}
+	stats.LastStep = h
+	return stats, nil
+}
+
+// rmsScaled returns the RMS of v_i / scale_i
+func rmsScaled(v, scale []float64) float64 {
+	sum := 0.
+	for i := range v {
+		e := v[i] / scale[i]
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(len(v)))
+}
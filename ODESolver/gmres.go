@@ -0,0 +1,125 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+const (
+	gmresRestart = 50
+	// gmresTolerance is the residual reduction asked of each linear solve of an inexact
+	// Newton iteration, above the accuracy of the difference quotients of the matrix products
+	gmresTolerance = 1e-6
+)
+
+// gmresWork solves A x = b by restarted GMRES, needing only products of A with vectors
+type gmresWork struct {
+	v      [][]float64
+	hess   [][]float64
+	cs, sn []float64
+	g      []float64
+	w      []float64
+}
+
+func (gw *gmresWork) allocate(n int) {
+	m := min(gmresRestart, n)
+	if len(gw.w) != n || len(gw.cs) != m {
+		gw.v = make([][]float64, m+1)
+		for i := range gw.v {
+			gw.v[i] = make([]float64, n)
+		}
+		gw.hess = make([][]float64, m+1)
+		for i := range gw.hess {
+			gw.hess[i] = make([]float64, m)
+		}
+		gw.cs = make([]float64, m)
+		gw.sn = make([]float64, m)
+		gw.g = make([]float64, m+1)
+		gw.w = make([]float64, n)
+	}
+}
+
+// solve sets x to the solution of A x = b, with apply(v, out) computing out = A v, until the
+// residual is reduced by gmresTolerance or maxIter products have been spent
+func (gw *gmresWork) solve(apply func(v, out []float64), b, x []float64, maxIter int) error {
+	n := len(b)
+	gw.allocate(n)
+	m := len(gw.cs)
+	for i := range x {
+		x[i] = 0
+	}
+	bNorm := floats.Norm(b, 2)
+	if bNorm == 0 {
+		return nil
+	}
+
+	for iter := 0; iter < maxIter; {
+		apply(x, gw.w)
+		for i := range gw.w {
+			gw.v[0][i] = b[i] - gw.w[i]
+		}
+		beta := floats.Norm(gw.v[0], 2)
+		if beta <= gmresTolerance*bNorm {
+			return nil
+		}
+		floats.Scale(1/beta, gw.v[0])
+		for i := range gw.g {
+			gw.g[i] = 0
+		}
+		gw.g[0] = beta
+
+		k := 0
+		for k < m && iter < maxIter {
+			iter++
+			apply(gw.v[k], gw.w)
+			for i := 0; i <= k; i++ {
+				gw.hess[i][k] = floats.Dot(gw.w, gw.v[i])
+				floats.AddScaled(gw.w, -gw.hess[i][k], gw.v[i])
+			}
+			next := floats.Norm(gw.w, 2)
+			gw.hess[k+1][k] = next
+			if next > 0 {
+				floats.ScaleTo(gw.v[k+1], 1/next, gw.w)
+			}
+
+			// rotate the new column of the Hessenberg matrix to upper triangular form
+			for i := 0; i < k; i++ {
+				hi, hn := gw.hess[i][k], gw.hess[i+1][k]
+				gw.hess[i][k] = gw.cs[i]*hi + gw.sn[i]*hn
+				gw.hess[i+1][k] = -gw.sn[i]*hi + gw.cs[i]*hn
+			}
+			r := math.Hypot(gw.hess[k][k], gw.hess[k+1][k])
+			if r == 0 {
+				return fmt.Errorf("singular matrix: GMRES breakdown after %d iterations", iter)
+			}
+			gw.cs[k], gw.sn[k] = gw.hess[k][k]/r, gw.hess[k+1][k]/r
+			gw.hess[k][k], gw.hess[k+1][k] = r, 0
+			gw.g[k+1] = -gw.sn[k] * gw.g[k]
+			gw.g[k] = gw.cs[k] * gw.g[k]
+			k++
+			if math.Abs(gw.g[k]) <= gmresTolerance*bNorm || next == 0 {
+				break
+			}
+		}
+
+		// x += V y with the triangular solve H y = g, y stored over g
+		for i := k - 1; i >= 0; i-- {
+			for j := i + 1; j < k; j++ {
+				gw.g[i] -= gw.hess[i][j] * gw.g[j]
+			}
+			gw.g[i] /= gw.hess[i][i]
+		}
+		for i := 0; i < k; i++ {
+			floats.AddScaled(x, gw.g[i], gw.v[i])
+		}
+	}
+
+	apply(x, gw.w)
+	floats.SubTo(gw.w, b, gw.w)
+	if floats.Norm(gw.w, 2) <= gmresTolerance*bNorm {
+		return nil
+	}
+	return fmt.Errorf("GMRES did not converge after %d iterations", maxIter)
+}
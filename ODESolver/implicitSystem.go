@@ -0,0 +1,287 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// implicitScheme is the one-step rule z = y + h Phi(z) solved for z = y(t + h)
+type implicitScheme int
+
+const (
+	// backwardEuler Phi(z) = F(t + h, z)
+	backwardEuler implicitScheme = iota
+	// trapezoidal Phi(z) = (F(t, y) + F(t + h, z)) / 2, the implicit Heun method
+	trapezoidal
+	// implicitMidpoint Phi(z) = F(t + h/2, (y + z) / 2)
+	implicitMidpoint
+)
+
+// LinearSolver selects how the Newton iterations of the implicit methods solve for their
+// correction (I - h theta J) dz = -G(z), with J the Jacobian of the right-hand side
+type LinearSolver int
+
+const (
+	// AutoLinearSolver is SparseLU for a SparseSystem and DenseLU otherwise
+	AutoLinearSolver LinearSolver = iota
+	// DenseLU factorizes the dense Jacobian of a JacobianSystem or of finite differences
+	DenseLU
+	// SparseLU factorizes the Jacobian of a SparseSystem as a band matrix
+	SparseLU
+	// NewtonGMRES never forms the Jacobian: GMRES only needs its products with vectors,
+	// taken as directional differences of the right-hand side
+	NewtonGMRES
+)
+
+// implicitWork is the workspace of the implicit methods applied to a coupled system
+type implicitWork struct {
+	f0   []float64
+	fz   []float64
+	arg  []float64
+	z    []float64
+	zNew []float64
+	res  []float64
+	dz   []float64
+	jv   []float64
+
+	jw jacobianWork
+	im iterationMatrix
+	sw sparseJacobianWork
+	lu bandLU
+	gw gmresWork
+
+	// sparse is the system whose pattern and coloring sw holds
+	sparse SparseSystem
+}
+
+func (w *implicitWork) allocate(n int) {
+	if len(w.z) != n {
+		w.f0 = make([]float64, n)
+		w.fz = make([]float64, n)
+		w.arg = make([]float64, n)
+		w.z = make([]float64, n)
+		w.zNew = make([]float64, n)
+		w.res = make([]float64, n)
+		w.dz = make([]float64, n)
+		w.jv = make([]float64, n)
+	}
+}
+
+// update computes out = y + h Phi(z); f0 must hold F(t, y) for the trapezoidal rule
+func (w *implicitWork) update(scheme implicitScheme, sys ODESystem, y, z []float64, t, h float64, out []float64) {
+	switch scheme {
+	case backwardEuler:
+		sys.Derivative(t+h, z, w.fz)
+		for i := range out {
+			out[i] = y[i] + h*w.fz[i]
+		}
+	case trapezoidal:
+		sys.Derivative(t+h, z, w.fz)
+		for i := range out {
+			out[i] = y[i] + 0.5*h*(w.f0[i]+w.fz[i])
+		}
+	case implicitMidpoint:
+		for i := range w.arg {
+			w.arg[i] = 0.5 * (y[i] + z[i])
+		}
+		sys.Derivative(t+0.5*h, w.arg, w.fz)
+		for i := range out {
+			out[i] = y[i] + h*w.fz[i]
+		}
+	}
+}
+
+// linearization returns the weight theta, time tau and argument xi of dG/dz = I - h theta J(tau, xi);
+// after update, w.fz holds F(tau, xi)
+func (w *implicitWork) linearization(scheme implicitScheme, z []float64, t, h float64) (float64, float64, []float64) {
+	switch scheme {
+	case trapezoidal:
+		return 0.5, t + h, z
+	case implicitMidpoint:
+		return 0.5, t + 0.5*h, w.arg
+	default:
+		return 1, t + h, z
+	}
+}
+
+// residual computes G(z) = z - y - h Phi(z)
+func (w *implicitWork) residual(scheme implicitScheme, sys ODESystem, y, z []float64, t, h float64, res []float64) {
+	w.update(scheme, sys, y, z, t, h, res)
+	for i := range res {
+		res[i] = z[i] - res[i]
+	}
+}
+
+// start checks the state and sets the explicit Euler predictor z = y + h F(t, y)
+func (w *implicitWork) start(sys ODESystem, y []float64, t, h float64) error {
+	if err := checkDim(sys, y); err != nil {
+		return err
+	}
+	w.allocate(len(y))
+	sys.Derivative(t, y, w.f0)
+	for i := range w.z {
+		w.z[i] = y[i] + h*w.f0[i]
+	}
+	return nil
+}
+
+// fixedPoint iterates z <- y + h Phi(z) until successive iterates agree to tolerance,
+// which converges when h times the Lipschitz constant of F is below one
+func (w *implicitWork) fixedPoint(scheme implicitScheme, sys ODESystem, y []float64, t, h float64) error {
+	if err := w.start(sys, y, t, h); err != nil {
+		return err
+	}
+	for iter := 0; iter < maxIter; iter++ {
+		w.update(scheme, sys, y, w.z, t, h, w.zNew)
+		if err := checkFinite(w.zNew); err != nil {
+			return err
+		}
+		change := floats.Distance(w.zNew, w.z, 2)
+		copy(w.z, w.zNew)
+		if change <= tolerance {
+			copy(y, w.z)
+			return nil
+		}
+	}
+	return fmt.Errorf("implicit step did not converge after %d iterations", maxIter)
+}
+
+// newton solves G(z) = 0 with Newton's method, the correction of every iteration from the
+// Jacobian of F evaluated at the current iterate and the given linear solver
+func (w *implicitWork) newton(scheme implicitScheme, sys ODESystem, y []float64, t, h float64, linear LinearSolver) error {
+	if err := w.start(sys, y, t, h); err != nil {
+		return err
+	}
+	sparse, isSparse := sys.(SparseSystem)
+	if linear == AutoLinearSolver {
+		linear = DenseLU
+		if isSparse {
+			linear = SparseLU
+		}
+	}
+	if linear == SparseLU {
+		if !isSparse {
+			return fmt.Errorf("sparse Newton solve needs a SparseSystem")
+		}
+		if err := w.setSparsity(sparse, len(y)); err != nil {
+			return err
+		}
+	}
+
+	for iter := 0; iter < maxIter; iter++ {
+		w.residual(scheme, sys, y, w.z, t, h, w.res)
+		if err := checkFinite(w.res); err != nil {
+			return err
+		}
+		if floats.Norm(w.res, 2) < tolerance {
+			copy(y, w.z)
+			return nil
+		}
+
+		theta, tau, xi := w.linearization(scheme, w.z, t, h)
+		for i := range w.dz {
+			w.dz[i] = -w.res[i]
+		}
+		switch linear {
+		case DenseLU:
+			w.jw.evaluate(sys, tau, xi, w.fz)
+			w.im.factorize(1, h*theta, w.jw.jac)
+			if err := w.im.solve(w.dz); err != nil {
+				return fmt.Errorf("singular Jacobian at iteration %d: %w", iter, err)
+			}
+		case SparseLU:
+			if err := w.sparseSolve(sparse, tau, xi, h*theta); err != nil {
+				return fmt.Errorf("singular Jacobian at iteration %d: %w", iter, err)
+			}
+		case NewtonGMRES:
+			err := w.gw.solve(func(v, out []float64) {
+				w.jacobianTimes(sys, tau, xi, v, out)
+				for i := range out {
+					out[i] = v[i] - h*theta*out[i]
+				}
+			}, w.res, w.dz, 2*len(y)+gmresRestart)
+			if err != nil {
+				return err
+			}
+			floats.Scale(-1, w.dz)
+		default:
+			return fmt.Errorf("unknown linear solver %d", linear)
+		}
+
+		floats.Add(w.z, w.dz)
+		if floats.Norm(w.dz, 2) < tolerance {
+			copy(y, w.z)
+			return nil
+		}
+	}
+	return fmt.Errorf("implicit step did not converge after %d iterations", maxIter)
+}
+
+// setSparsity checks and colors the pattern of the sparse system unless sw already holds
+// it for the same system and dimension, so stepping one system does so only once
+func (w *implicitWork) setSparsity(sys SparseSystem, n int) error {
+	if w.sparse != nil && len(w.sw.pattern) == n && sameSystem(w.sparse, sys) {
+		return nil
+	}
+	w.sparse = nil
+	if err := w.sw.setPattern(sys.JacobianPattern(), n); err != nil {
+		return err
+	}
+	w.sparse = sys
+	return nil
+}
+
+// sameSystem reports whether a and b are the same system. Grid systems of one size share
+// their diagonal pattern whatever their equations; other systems compare by value, which
+// needs them to be comparable.
+func sameSystem(a, b SparseSystem) bool {
+	if ga, ok := a.(gridSystem); ok {
+		gb, ok := b.(gridSystem)
+		return ok && ga.nPoints == gb.nPoints
+	}
+	return reflect.ValueOf(a).Comparable() && a == b
+}
+
+// sparseSolve overwrites w.dz with the solution of (I - c J) x = w.dz for the sparse
+// Jacobian J at (t, y), whose derivative w.fz holds
+func (w *implicitWork) sparseSolve(sys SparseSystem, t float64, y []float64, c float64) error {
+	w.sw.evaluate(sys, t, y, w.fz)
+	kl, ku := w.sw.pattern.bandwidths()
+	w.lu.reset(len(y), kl, ku)
+	for i := range y {
+		*w.lu.at(i, i) = 1
+	}
+	for i, row := range w.sw.pattern {
+		for k, j := range row {
+			*w.lu.at(i, j) -= c * w.sw.values[i][k]
+		}
+	}
+	if err := w.lu.factorize(); err != nil {
+		return err
+	}
+	w.lu.solve(w.dz)
+	return nil
+}
+
+// jacobianTimes sets out = J(t, y) v from the directional difference of F along v, with
+// w.fz holding F(t, y)
+func (w *implicitWork) jacobianTimes(sys ODESystem, t float64, y, v, out []float64) {
+	vNorm := floats.Norm(v, 2)
+	if vNorm == 0 {
+		for i := range out {
+			out[i] = 0
+		}
+		return
+	}
+	eps := math.Sqrt(machineEps) * (1 + floats.Norm(y, 2)) / vNorm
+	for i := range w.jv {
+		w.jv[i] = y[i] + eps*v[i]
+	}
+	sys.Derivative(t, w.jv, out)
+	for i := range out {
+		out[i] = (out[i] - w.fz[i]) / eps
+	}
+}
@@ -0,0 +1,91 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// JacobianSystem is an ODESystem that also supplies its Jacobian J_ij = dF_i/dy_j; the Jacobian
+// of a system that doesn't implement it is taken from forward differences
+type JacobianSystem interface {
+	ODESystem
+	Jacobian(t float64, y []float64, jac *mat.Dense)
+}
+
+// jacobianWork evaluates the dense Jacobian of a system
+type jacobianWork struct {
+	jac   *mat.Dense
+	yPert []float64
+	fPert []float64
+}
+
+// evaluate sets jw.jac to dF/dy at (t, y), where f holds F(t, y), and returns the number of
+// derivative evaluations spent on finite differences
+func (jw *jacobianWork) evaluate(sys ODESystem, t float64, y, f []float64) int {
+	n := len(y)
+	if len(jw.yPert) != n {
+		jw.jac = mat.NewDense(n, n, nil)
+		jw.yPert = make([]float64, n)
+		jw.fPert = make([]float64, n)
+	}
+	if js, ok := sys.(JacobianSystem); ok {
+		jw.jac.Zero()
+		js.Jacobian(t, y, jw.jac)
+		return 0
+	}
+
+	copy(jw.yPert, y)
+	for j := 0; j < n; j++ {
+		jw.yPert[j] = y[j] + math.Sqrt(machineEps)*math.Max(1, math.Abs(y[j]))
+		step := jw.yPert[j] - y[j]
+		sys.Derivative(t, jw.yPert, jw.fPert)
+		jw.yPert[j] = y[j]
+		for i := 0; i < n; i++ {
+			jw.jac.Set(i, j, (jw.fPert[i]-f[i])/step)
+		}
+	}
+	return n
+}
+
+const machineEps = 2.220446049250313e-16
+
+// iterationMatrix holds the LU factors of a I - b J, the matrix of the Newton iterations
+// and linear stage equations of the stiff solvers
+type iterationMatrix struct {
+	m  *mat.Dense
+	lu mat.LU
+	x  *mat.VecDense
+}
+
+func (im *iterationMatrix) factorize(a, b float64, jac *mat.Dense) {
+	n, _ := jac.Dims()
+	if im.m == nil || im.x.Len() != n {
+		im.m = mat.NewDense(n, n, nil)
+		im.x = mat.NewVecDense(n, nil)
+	}
+	im.m.Scale(-b, jac)
+	for i := 0; i < n; i++ {
+		im.m.Set(i, i, im.m.At(i, i)+a)
+	}
+	im.lu.Factorize(im.m)
+}
+
+// solve overwrites rhs with the solution x of (a I - b J) x = rhs
+func (im *iterationMatrix) solve(rhs []float64) error {
+	if err := im.lu.SolveVecTo(im.x, false, mat.NewVecDense(len(rhs), rhs)); err != nil {
+		return fmt.Errorf("singular iteration matrix: %w", err)
+	}
+	copy(rhs, im.x.RawVector().Data)
+	return nil
+}
+
+// withLastFailure adds the error of the last failed linear solve to the error of a stiff
+// solver giving up, so a singular iteration matrix isn't mistaken for slow Newton convergence
+func withLastFailure(err, lastFailure error) error {
+	if lastFailure == nil {
+		return err
+	}
+	return fmt.Errorf("%w after %w", err, lastFailure)
+}
@@ -17,12 +17,19 @@ type EulerImplicitFixPoint struct {
 	xPre   []float64
 	xNew   []float64
 	diff   []float64
+	work   implicitWork
 }
 
 func (eImFP *EulerImplicitFixPoint) Name() string {
 	return "Euler Method"
 }
 
+// StepSystem advances the coupled system dy/dt = F(t, y) in place by one step, solving the
+// implicit equations with fixed-point iteration
+func (eImFP *EulerImplicitFixPoint) StepSystem(sys ODESystem, y []float64, t float64) error {
+	return eImFP.work.fixedPoint(backwardEuler, sys, y, t, eImFP.DeltaT)
+}
+
 func (eImFP *EulerImplicitFixPoint) NextStep(xt, t float64) (float64, error) {
 	xPre := xt
 
@@ -84,12 +91,19 @@ type HeunsImplicitFixPoint struct {
 	fxt       []float64
 	jacobian  []float64
 	diff      []float64
+	work      implicitWork
 }
 
 func (hIm *HeunsImplicitFixPoint) Name() string {
 	return "Heun's Method (Improved Euler method)"
 }
 
+// StepSystem advances the coupled system dy/dt = F(t, y) in place by one step, solving the
+// implicit equations with fixed-point iteration
+func (hIm *HeunsImplicitFixPoint) StepSystem(sys ODESystem, y []float64, t float64) error {
+	return hIm.work.fixedPoint(trapezoidal, sys, y, t, hIm.DeltaT)
+}
+
 func (hIm *HeunsImplicitFixPoint) PredictIni(xt, fxt, xP []float64) {
 	nPoints := len(xP)
 	xP = slices.Clone(xt)
@@ -188,12 +202,19 @@ type MidPointFixPoint struct {
 	xMid   []float64
 	xPre   []float64
 	fxt    []float64
+	work   implicitWork
 }
 
 func (mIm *MidPointFixPoint) Name() string {
 	return "MidPoint Implicit with Fix-Point Method"
 }
 
+// StepSystem advances the coupled system dy/dt = F(t, y) in place by one step, solving the
+// implicit equations with fixed-point iteration
+func (mIm *MidPointFixPoint) StepSystem(sys ODESystem, y []float64, t float64) error {
+	return mIm.work.fixedPoint(implicitMidpoint, sys, y, t, mIm.DeltaT)
+}
+
 func (mIm *MidPointFixPoint) NextStep(xt, t float64) (float64, error) {
 	tMid := t + mIm.DeltaT/2
 	xPre := xt
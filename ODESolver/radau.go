@@ -0,0 +1,353 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// radauC and radauA are the nodes and coefficients of the 3-stage Radau IIA method
+var (
+	radauC = [3]float64{(4 - math.Sqrt(6)) / 10, (4 + math.Sqrt(6)) / 10, 1}
+	radauA = [3][3]float64{
+		{(88 - 7*math.Sqrt(6)) / 360, (296 - 169*math.Sqrt(6)) / 1800, (-2 + 3*math.Sqrt(6)) / 225},
+		{(296 + 169*math.Sqrt(6)) / 1800, (88 + 7*math.Sqrt(6)) / 360, (-2 - 3*math.Sqrt(6)) / 225},
+		{(16 - math.Sqrt(6)) / 36, (16 + math.Sqrt(6)) / 36, 1. / 9},
+	}
+	// radauE and radauGamma0 give the error estimate of Hairer and Wanner's RADAU5,
+	// (gamma0/h I - J)^-1 (F(t, y) + Sum_i e_i z_i / h)
+	radauE      = [3]float64{-(13 + 7*math.Sqrt(6)) / 3, (-13 + 7*math.Sqrt(6)) / 3, -1. / 3}
+	radauGamma0 = 30 / (6 + math.Cbrt(81) - math.Cbrt(9))
+)
+
+const (
+	radauNewtonIter = 7
+	radauMaxRate    = 0.99
+	// the Jacobian is kept for the next step while the iterations contract faster than this
+	radauJacobianRate = 1e-3
+)
+
+// Radau is the implicit 3-stage Radau IIA method of order 5, L-stable and stiffly accurate.
+// The stage equations are solved by simplified Newton iterations with the 3n x 3n matrix
+// I - h A (x) J; as in RADAU5 the Jacobian is reused across steps while the iterations
+// converge quickly and is re-evaluated before a failed step is shrunk.
+type Radau struct {
+	ctrl StepControl
+	jw   jacobianWork
+
+	big    *mat.Dense
+	bigLU  mat.LU
+	bigX   *mat.VecDense
+	errMat iterationMatrix
+
+	z     [3][]float64
+	f     [3][]float64
+	f0    []float64
+	yNew  []float64
+	yLow  []float64
+	yTmp  []float64
+	fTmp  []float64
+	dz    []float64
+	rate0 float64
+	theta float64
+
+	// zPrev holds the stages of the last accepted step of size hPrev, zero before the first
+	zPrev [3][]float64
+	hPrev float64
+}
+
+// NewRadau creates the Radau IIA integrator
+func NewRadau(ctrl StepControl) *Radau {
+	return &Radau{ctrl: ctrl.withDefaults(3), rate0: 1}
+}
+
+func (ra *Radau) Name() string { return "Radau IIA (5)" }
+
+func (ra *Radau) allocate(n int) {
+	if len(ra.f0) != n {
+		for i := range ra.z {
+			ra.z[i] = make([]float64, n)
+			ra.f[i] = make([]float64, n)
+			ra.zPrev[i] = make([]float64, n)
+		}
+		ra.f0 = make([]float64, n)
+		ra.yNew = make([]float64, n)
+		ra.yLow = make([]float64, n)
+		ra.yTmp = make([]float64, n)
+		ra.fTmp = make([]float64, n)
+		ra.dz = make([]float64, 3*n)
+		ra.big = mat.NewDense(3*n, 3*n, nil)
+		ra.bigX = mat.NewVecDense(3*n, nil)
+	}
+}
+
+// prepare evaluates F at the start of a step and, if jacobian is set, its Jacobian
+func (ra *Radau) prepare(sys ODESystem, t float64, y []float64, jacobian bool, stats *IntegrationStats) {
+	ra.allocate(len(y))
+	sys.Derivative(t, y, ra.f0)
+	stats.Evaluations++
+	if jacobian {
+		stats.Evaluations += ra.jw.evaluate(sys, t, y, ra.f0)
+		stats.Jacobians++
+	}
+}
+
+// factorize decomposes I - h A (x) J for the Newton iterations and gamma0/h I - J for the
+// error estimate
+func (ra *Radau) factorize(h float64, stats *IntegrationStats) {
+	n := len(ra.f0)
+	jac := ra.jw.jac.RawMatrix()
+	ra.big.Zero()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for r := 0; r < n; r++ {
+				for c := 0; c < n; c++ {
+					ra.big.Set(i*n+r, j*n+c, -h*radauA[i][j]*jac.Data[r*jac.Stride+c])
+				}
+			}
+		}
+	}
+	for i := 0; i < 3*n; i++ {
+		ra.big.Set(i, i, ra.big.At(i, i)+1)
+	}
+	ra.bigLU.Factorize(ra.big)
+	ra.errMat.factorize(radauGamma0/h, 1, ra.jw.jac)
+	stats.Factorizations += 2
+}
+
+// newton solves the stage equations z_i = h Sum_j A_ij F(t + c_j h, y + z_j) for a step of
+// size h into ra.z and reports whether the iterations converged within tol
+func (ra *Radau) newton(sys ODESystem, y []float64, t, h, tol float64, stats *IntegrationStats) (bool, error) {
+	n := len(y)
+	ra.startingValues(h)
+	ctrl := controller{StepControl: ra.ctrl}
+	rate := math.Pow(math.Max(ra.rate0, machineEps), 0.8)
+	normPrev := 0.
+	ra.theta = 0
+
+	for iter := 0; iter < radauNewtonIter; iter++ {
+		for i := range ra.z {
+			for r := range y {
+				ra.yTmp[r] = y[r] + ra.z[i][r]
+			}
+			sys.Derivative(t+radauC[i]*h, ra.yTmp, ra.f[i])
+			stats.Evaluations++
+			if err := checkFinite(ra.f[i]); err != nil {
+				return false, nil
+			}
+		}
+		for i := range ra.z {
+			for r := 0; r < n; r++ {
+				g := ra.z[i][r]
+				for j := range ra.f {
+					g -= h * radauA[i][j] * ra.f[j][r]
+				}
+				ra.dz[i*n+r] = -g
+			}
+		}
+		if err := ra.bigLU.SolveVecTo(ra.bigX, false, mat.NewVecDense(3*n, ra.dz)); err != nil {
+			return false, fmt.Errorf("singular iteration matrix: %w", err)
+		}
+
+		sum := 0.
+		for i := range ra.z {
+			dzi := ra.bigX.RawVector().Data[i*n : (i+1)*n]
+			norm := ctrl.scaledNorm(dzi, y)
+			sum += norm * norm
+			floatsAddScaled(ra.z[i], 1, dzi)
+		}
+		norm := math.Sqrt(sum / 3)
+		if iter > 0 {
+			ra.theta = norm / normPrev
+			if ra.theta >= radauMaxRate {
+				return false, nil
+			}
+			rate = ra.theta / (1 - ra.theta)
+		}
+		if rate*norm <= tol {
+			ra.rate0 = rate
+			return true, nil
+		}
+		normPrev = norm
+	}
+	return false, nil
+}
+
+// startingValues sets ra.z for a step of size h by extrapolating the collocation polynomial
+// of the last accepted step, which passes through 0 and its stages at the nodes
+func (ra *Radau) startingValues(h float64) {
+	if ra.hPrev == 0 {
+		for i := range ra.z {
+			for r := range ra.z[i] {
+				ra.z[i][r] = 0
+			}
+		}
+		return
+	}
+	nodes := [4]float64{0, radauC[0], radauC[1], radauC[2]}
+	for i := range ra.z {
+		// the new node in units of the last step, from the start of the last step
+		s := 1 + h/ra.hPrev*radauC[i]
+		var w [4]float64
+		for k := range nodes {
+			w[k] = 1
+			for m := range nodes {
+				if m != k {
+					w[k] *= (s - nodes[m]) / (nodes[k] - nodes[m])
+				}
+			}
+		}
+		for r := range ra.z[i] {
+			ra.z[i][r] = w[1]*ra.zPrev[0][r] + w[2]*ra.zPrev[1][r] + w[3]*ra.zPrev[2][r] - ra.zPrev[2][r]
+		}
+	}
+}
+
+// keepStages saves the stages of an accepted step of size h for the next starting values
+func (ra *Radau) keepStages(h float64) {
+	for i := range ra.z {
+		copy(ra.zPrev[i], ra.z[i])
+	}
+	ra.hPrev = h
+}
+
+// estimate sets ra.yNew = y + z_3 and ra.yLow so that ra.yNew - ra.yLow is the error estimate
+func (ra *Radau) estimate(sys ODESystem, y []float64, t, h float64, refine bool, stats *IntegrationStats) error {
+	for r := range y {
+		ra.yNew[r] = y[r] + ra.z[2][r]
+		ra.yTmp[r] = (radauE[0]*ra.z[0][r] + radauE[1]*ra.z[1][r] + radauE[2]*ra.z[2][r]) / h
+		ra.yLow[r] = ra.f0[r] + ra.yTmp[r]
+	}
+	if err := ra.errMat.solve(ra.yLow); err != nil {
+		return err
+	}
+	if refine {
+		// a second solve damps the estimate of stiff components after a rejection
+		for r := range y {
+			ra.yLow[r] += y[r]
+		}
+		sys.Derivative(t, ra.yLow, ra.fTmp)
+		stats.Evaluations++
+		for r := range y {
+			ra.yLow[r] = ra.fTmp[r] + ra.yTmp[r]
+		}
+		if err := ra.errMat.solve(ra.yLow); err != nil {
+			return err
+		}
+	}
+	for r := range y {
+		ra.yLow[r] = ra.yNew[r] - ra.yLow[r]
+	}
+	return checkFinite(ra.yNew)
+}
+
+// Integrate advances y in place from t0 to t1, which may lie before t0
+func (ra *Radau) Integrate(sys ODESystem, y []float64, t0, t1 float64) (IntegrationStats, error) {
+	var stats IntegrationStats
+	if err := checkDim(sys, y); err != nil {
+		return stats, err
+	}
+	if err := ra.ctrl.check(len(y)); err != nil {
+		return stats, err
+	}
+	if t1 == t0 {
+		return stats, nil
+	}
+	ra.allocate(len(y))
+
+	dir := math.Copysign(1, t1-t0)
+	ctrl := newController(ra.ctrl, 3)
+	maxStep := ctrl.MaxStep
+	if maxStep == 0 {
+		maxStep = math.Abs(t1 - t0)
+	}
+	h := ctrl.InitialStep
+	if h == 0 {
+		h = initialStep(sys, y, t0, dir, &ctrl, ra.f0, ra.yNew, ra.yTmp)
+		stats.Evaluations += 2
+	}
+	h = math.Min(math.Abs(h), maxStep)
+	tol := newtonTolerance(ctrl.Rtol)
+
+	t, fresh, current, first := t0, true, false, true
+	ra.rate0, ra.theta, ra.hPrev = 1, 0, 0
+	// a singular iteration matrix halves the step like a diverging Newton iteration, lastFailure
+	// keeps its error for the error returned when the step size or step count runs out
+	var lastFailure error
+	for (t1-t)*dir > 0 {
+		if stats.Accepted+stats.Rejected >= ctrl.MaxSteps {
+			return stats, withLastFailure(fmt.Errorf("no convergence to t = %g after %d steps, reached t = %g", t1, ctrl.MaxSteps, t), lastFailure)
+		}
+		last := (t+dir*h-t1)*dir >= 0
+		if last {
+			h = math.Abs(t1 - t)
+		}
+		if h < ctrl.MinStep {
+			return stats, withLastFailure(fmt.Errorf("step size %g below the minimum %g at t = %g", h, ctrl.MinStep, t), lastFailure)
+		}
+
+		if fresh {
+			current = first || ra.theta > radauJacobianRate
+			ra.prepare(sys, t, y, current, &stats)
+			fresh = false
+		}
+		ra.factorize(dir*h, &stats)
+		converged, err := ra.newton(sys, y, t, dir*h, tol, &stats)
+		if err == nil && !converged && !current {
+			// retry with a Jacobian at the start of this step before shrinking it
+			stats.Evaluations += ra.jw.evaluate(sys, t, y, ra.f0)
+			stats.Jacobians++
+			current = true
+			continue
+		}
+		errNorm := math.Inf(1)
+		if err == nil && converged {
+			if err = ra.estimate(sys, y, t, dir*h, false, &stats); err == nil {
+				errNorm = ctrl.errorNorm(y, ra.yNew, ra.yLow)
+				if errNorm > 1 && (first || ctrl.rejected) && ra.estimate(sys, y, t, dir*h, true, &stats) == nil {
+					errNorm = ctrl.errorNorm(y, ra.yNew, ra.yLow)
+				}
+			}
+		}
+		if err != nil {
+			lastFailure = err
+		}
+		if errNorm > 1 {
+			stats.Rejected++
+			if math.IsInf(errNorm, 1) {
+				h *= 0.5
+				ra.rate0 = 1
+				ctrl.rejected = true
+			} else {
+				h = math.Abs(ctrl.reject(errNorm, h))
+			}
+			continue
+		}
+
+		stats.Accepted++
+		lastFailure = nil
+		copy(y, ra.yNew)
+		ra.keepStages(dir * h)
+		if last {
+			t = t1
+		} else {
+			t += dir * h
+		}
+		h = math.Min(math.Abs(ctrl.accept(errNorm, h)), maxStep)
+		fresh, first = true, false
+	}
+	stats.LastStep = h
+	return stats, nil
+}
+
+// newtonTolerance is the convergence threshold of the Newton iterations in the norm of the
+// error control, tighter for small relative tolerances
+func newtonTolerance(rtol []float64) float64 {
+	r := math.Inf(1)
+	for _, v := range rtol {
+		r = math.Min(r, v)
+	}
+	r = math.Max(r, 100*machineEps)
+	return math.Max(10*machineEps/r, math.Min(0.03, math.Sqrt(r)))
+}
@@ -0,0 +1,252 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// rosenbrockCoefs are the coefficients of an s-stage Rosenbrock method in the form of Hairer
+// and Wanner, Solving ODEs II, IV.7: stage i solves
+// (I - gamma h J) k_i = h F(t + alpha_i h, y + Sum_j alpha_ij k_j) + h J Sum_j gamma_ij k_j + gamma_i h^2 dF/dt
+// with alpha_i = Sum_j alpha_ij and gamma_i = gamma + Sum_j gamma_ij, j < i
+type rosenbrockCoefs struct {
+	name          string
+	order         int
+	embeddedOrder int
+
+	gamma  float64
+	alpha  [][]float64
+	gammas [][]float64
+	b      []float64
+	bLow   []float64
+}
+
+// ros34pw2 is the stiffly accurate W-method of Rang and Angermann, BIT 45 (2005) 761, of
+// order 3 with an embedded order 2 solution for any approximation J of the Jacobian
+var ros34pw2 = rosenbrockCoefs{
+	name:          "Rosenbrock-W ROS34PW2",
+	order:         3,
+	embeddedOrder: 2,
+	gamma:         4.3586652150845900e-01,
+	alpha: [][]float64{
+		{},
+		{8.7173304301691801e-01},
+		{8.4457060015369423e-01, -1.1299064236484185e-01},
+		{0, 0, 1},
+	},
+	gammas: [][]float64{
+		{},
+		{-8.7173304301691801e-01},
+		{-9.0338057013044082e-01, 5.4180672388095326e-02},
+		{2.4212380706095346e-01, -1.2232505839045147e+00, 5.4526025533510214e-01},
+	},
+	b:    []float64{2.4212380706095346e-01, -1.2232505839045147e+00, 1.5452602553351020e+00, 4.3586652150845900e-01},
+	bLow: []float64{3.7810903145819369e-01, -9.6042292212423178e-02, 0.5, 2.1793326075422950e-01},
+}
+
+// Rosenbrock is a linearly implicit Rosenbrock-W method for stiff systems. Every stage solves
+// a linear system with the same matrix I - gamma h J, so a step costs one LU decomposition
+// and no Newton iterations; the Jacobian is evaluated once per accepted step.
+type Rosenbrock struct {
+	coefs *rosenbrockCoefs
+	ctrl  StepControl
+	jw    jacobianWork
+	im    iterationMatrix
+
+	k      [][]float64
+	f0     []float64
+	ft     []float64
+	yStage []float64
+	gk     []float64
+	jgk    []float64
+	yNew   []float64
+	yLow   []float64
+}
+
+// NewRosenbrockW creates the order 3 Rosenbrock-W method ROS34PW2
+func NewRosenbrockW(ctrl StepControl) *Rosenbrock {
+	return &Rosenbrock{coefs: &ros34pw2, ctrl: ctrl.withDefaults(ros34pw2.embeddedOrder)}
+}
+
+func (rb *Rosenbrock) Name() string { return rb.coefs.name }
+
+func (rb *Rosenbrock) allocate(n int) {
+	if len(rb.f0) != n {
+		rb.k = make([][]float64, len(rb.coefs.b))
+		for i := range rb.k {
+			rb.k[i] = make([]float64, n)
+		}
+		rb.f0 = make([]float64, n)
+		rb.ft = make([]float64, n)
+		rb.yStage = make([]float64, n)
+		rb.gk = make([]float64, n)
+		rb.jgk = make([]float64, n)
+		rb.yNew = make([]float64, n)
+		rb.yLow = make([]float64, n)
+	}
+}
+
+// prepare evaluates F, its Jacobian and its time derivative at the start of a step
+func (rb *Rosenbrock) prepare(sys ODESystem, t float64, y []float64, stats *IntegrationStats) {
+	rb.allocate(len(y))
+	sys.Derivative(t, y, rb.f0)
+	stats.Evaluations += 1 + rb.jw.evaluate(sys, t, y, rb.f0)
+	stats.Jacobians++
+
+	dt := math.Sqrt(machineEps) * math.Max(1, math.Abs(t))
+	sys.Derivative(t+dt, y, rb.ft)
+	stats.Evaluations++
+	for i := range rb.ft {
+		rb.ft[i] = (rb.ft[i] - rb.f0[i]) / dt
+	}
+}
+
+// step computes the solution rb.yNew and the embedded rb.yLow after a step of size h
+func (rb *Rosenbrock) step(sys ODESystem, y []float64, t, h float64, stats *IntegrationStats) error {
+	c := rb.coefs
+	rb.im.factorize(1, c.gamma*h, rb.jw.jac)
+	stats.Factorizations++
+
+	for i := range c.b {
+		copy(rb.yStage, y)
+		for j := range rb.gk {
+			rb.gk[j] = 0
+		}
+		alphaI, gammaI := 0., c.gamma
+		for j := 0; j < i; j++ {
+			alphaI += c.alpha[i][j]
+			gammaI += c.gammas[i][j]
+			floatsAddScaled(rb.yStage, c.alpha[i][j], rb.k[j])
+			floatsAddScaled(rb.gk, c.gammas[i][j], rb.k[j])
+		}
+
+		ki := rb.k[i]
+		if i == 0 {
+			copy(ki, rb.f0)
+		} else {
+			sys.Derivative(t+alphaI*h, rb.yStage, ki)
+			stats.Evaluations++
+		}
+		mulVec(rb.jw.jac, rb.gk, rb.jgk)
+		for r := range ki {
+			ki[r] = h*ki[r] + h*rb.jgk[r] + gammaI*h*h*rb.ft[r]
+		}
+		if err := rb.im.solve(ki); err != nil {
+			return err
+		}
+	}
+
+	copy(rb.yNew, y)
+	copy(rb.yLow, y)
+	for i := range c.b {
+		floatsAddScaled(rb.yNew, c.b[i], rb.k[i])
+		floatsAddScaled(rb.yLow, c.bLow[i], rb.k[i])
+	}
+	if err := checkFinite(rb.yNew); err != nil {
+		return err
+	}
+	return checkFinite(rb.yLow)
+}
+
+// Integrate advances y in place from t0 to t1, which may lie before t0
+func (rb *Rosenbrock) Integrate(sys ODESystem, y []float64, t0, t1 float64) (IntegrationStats, error) {
+	var stats IntegrationStats
+	if err := checkDim(sys, y); err != nil {
+		return stats, err
+	}
+	if err := rb.ctrl.check(len(y)); err != nil {
+		return stats, err
+	}
+	if t1 == t0 {
+		return stats, nil
+	}
+	rb.allocate(len(y))
+
+	dir := math.Copysign(1, t1-t0)
+	ctrl := newController(rb.ctrl, rb.coefs.embeddedOrder)
+	maxStep := ctrl.MaxStep
+	if maxStep == 0 {
+		maxStep = math.Abs(t1 - t0)
+	}
+	h := ctrl.InitialStep
+	if h == 0 {
+		h = initialStep(sys, y, t0, dir, &ctrl, rb.f0, rb.ft, rb.yStage)
+		stats.Evaluations += 2
+	}
+	h = math.Min(math.Abs(h), maxStep)
+
+	t, fresh := t0, true
+	var lastFailure error
+	for (t1-t)*dir > 0 {
+		if stats.Accepted+stats.Rejected >= ctrl.MaxSteps {
+			return stats, withLastFailure(fmt.Errorf("no convergence to t = %g after %d steps, reached t = %g", t1, ctrl.MaxSteps, t), lastFailure)
+		}
+		last := (t+dir*h-t1)*dir >= 0
+		if last {
+			h = math.Abs(t1 - t)
+		}
+		if h < ctrl.MinStep {
+			return stats, withLastFailure(fmt.Errorf("step size %g below the minimum %g at t = %g", h, ctrl.MinStep, t), lastFailure)
+		}
+
+		if fresh {
+			rb.prepare(sys, t, y, &stats)
+			fresh = false
+		}
+		// a singular matrix or an overflow is treated like a large error, and kept for the
+		// error returned when the step size or step count runs out
+		errNorm := math.Inf(1)
+		if err := rb.step(sys, y, t, dir*h, &stats); err == nil {
+			errNorm = ctrl.errorNorm(y, rb.yNew, rb.yLow)
+		} else {
+			lastFailure = err
+		}
+		if errNorm > 1 {
+			stats.Rejected++
+			if math.IsInf(errNorm, 1) {
+				h *= ctrl.MinFactor
+			} else {
+				h = math.Abs(ctrl.reject(errNorm, h))
+			}
+			continue
+		}
+
+		stats.Accepted++
+		lastFailure = nil
+		copy(y, rb.yNew)
+		if last {
+			t = t1
+		} else {
+			t += dir * h
+		}
+		h = math.Min(math.Abs(ctrl.accept(errNorm, h)), maxStep)
+		fresh = true
+	}
+	stats.LastStep = h
+	return stats, nil
+}
+
+// floatsAddScaled sets dst += alpha s
+func floatsAddScaled(dst []float64, alpha float64, s []float64) {
+	if alpha == 0 {
+		return
+	}
+	for i, v := range s {
+		dst[i] += alpha * v
+	}
+}
+
+// mulVec sets out = m x
+func mulVec(m *mat.Dense, x, out []float64) {
+	raw := m.RawMatrix()
+	for r := 0; r < raw.Rows; r++ {
+		row := raw.Data[r*raw.Stride : r*raw.Stride+raw.Cols]
+		sum := 0.
+		for c, v := range row {
+			sum += v * x[c]
+		}
+		out[r] = sum
+	}
+}
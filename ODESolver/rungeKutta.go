@@ -0,0 +1,150 @@
+package EquationSolver
+
+import (
+	"GoProject/gridData"
+	"fmt"
+)
+
+// RungeKutta is an explicit Runge-Kutta method driven by its Tableau, stepping a scalar
+// equation, a grid of independent equations or a coupled system with the same coefficients
+type RungeKutta struct {
+	tab       *Tableau
+	timeFunc  gridData.TDPotentialOp
+	deltaTime float64
+
+	point pointSystem
+	x     [1]float64
+	work  stages
+}
+
+// pointSystem is the scalar equation dx/dt = f(x, t) as a system of dimension one
+type pointSystem struct {
+	tdFunc gridData.TDPotentialOp
+}
+
+func (p *pointSystem) Dim() int { return 1 }
+func (p *pointSystem) Derivative(t float64, y, dydt []float64) {
+	dydt[0] = p.tdFunc.EvaluateAt(y[0], t)
+}
+
+func newRungeKutta(tab *Tableau, dt float64, tdFunc gridData.TDPotentialOp) RungeKutta {
+	return RungeKutta{
+		tab:       tab,
+		timeFunc:  tdFunc,
+		deltaTime: dt,
+		point:     pointSystem{tdFunc: tdFunc},
+	}
+}
+
+// NewRungeKutta creates a fixed-step method from the tableau registered under key;
+// an adaptive tableau steps with its higher-order weights
+func NewRungeKutta(key string, dt float64, tdFunc gridData.TDPotentialOp) (*RungeKutta, error) {
+	tab, err := LookupTableau(key)
+	if err != nil {
+		return nil, err
+	}
+	rk := newRungeKutta(tab, dt, tdFunc)
+	return &rk, nil
+}
+
+// NewRungeKuttaFromTableau creates a fixed-step method from an unregistered tableau
+func NewRungeKuttaFromTableau(tab *Tableau, dt float64, tdFunc gridData.TDPotentialOp) (*RungeKutta, error) {
+	if err := tab.Validate(); err != nil {
+		return nil, err
+	}
+	rk := newRungeKutta(tab, dt, tdFunc)
+	return &rk, nil
+}
+
+func (rk *RungeKutta) Name() string      { return rk.tab.Name }
+func (rk *RungeKutta) Tableau() *Tableau { return rk.tab }
+func (rk *RungeKutta) DeltaT() float64   { return rk.deltaTime }
+
+func (rk *RungeKutta) ReDefine(dt float64, tdFunc gridData.TDPotentialOp) {
+	rk.deltaTime = dt
+	rk.timeFunc = tdFunc
+	rk.point.tdFunc = tdFunc
+}
+
+func (rk *RungeKutta) NextStep(xt, t float64) (float64, error) {
+	rk.x[0] = xt
+	if err := rk.StepSystem(&rk.point, rk.x[:], t); err != nil {
+		return xt, err
+	}
+	return rk.x[0], nil
+}
+
+func (rk *RungeKutta) NextStepOnGrid(xt []float64, t float64) error {
+	return rk.StepSystem(GridSystem(rk.timeFunc, len(xt)), xt, t)
+}
+
+// StepSystem advances the coupled system dy/dt = F(t, y) in place by one step
+func (rk *RungeKutta) StepSystem(sys ODESystem, y []float64, t float64) error {
+	return rk.work.explicitStep(sys, y, t, rk.deltaTime, rk.tab)
+}
+
+// adaptiveRKBase contains the common implementation for adaptive RK methods
+type adaptiveRKBase struct {
+	RungeKutta
+	ctrl controller
+}
+
+func newAdaptiveRKBase(tab *Tableau, dt float64, tdFunc gridData.TDPotentialOp) adaptiveRKBase {
+	return adaptiveRKBase{
+		RungeKutta: newRungeKutta(tab, dt, tdFunc),
+		ctrl:       newController(DefaultStepControl(), tab.EmbeddedOrder),
+	}
+}
+
+// SetStepControl replaces the tolerances and controller settings of the step-by-step methods
+func (ark *adaptiveRKBase) SetStepControl(sc StepControl) {
+	ark.ctrl = newController(sc, ark.tab.EmbeddedOrder)
+}
+
+func (ark *adaptiveRKBase) adaptiveDt(dt float64) {
+	ark.deltaTime = dt
+}
+
+// NextStep returns the higher-order solution when the step is accepted; a rejected step
+// returns xt unchanged with ErrStepRejected. Either way DeltaT is the next step size.
+func (ark *adaptiveRKBase) NextStep(xt, t float64) (float64, error) {
+	ark.x[0] = xt
+	if err := ark.StepSystem(&ark.point, ark.x[:], t); err != nil {
+		return xt, err
+	}
+	return ark.x[0], nil
+}
+
+func (ark *adaptiveRKBase) NextStepOnGrid(xt []float64, t float64) error {
+	return ark.StepSystem(GridSystem(ark.timeFunc, len(xt)), xt, t)
+}
+
+// StepSystem tries one step of the coupled system dy/dt = F(t, y) with the current step size.
+// A rejected step leaves y unchanged, shrinks the step size and returns ErrStepRejected;
+// an accepted step may enlarge it.
+func (ark *adaptiveRKBase) StepSystem(sys ODESystem, y []float64, t float64) error {
+	if err := ark.ctrl.check(len(y)); err != nil {
+		return err
+	}
+	dt, err := ark.work.adaptiveStep(sys, y, t, ark.deltaTime, ark.tab, &ark.ctrl)
+	ark.adaptiveDt(dt)
+	return err
+}
+
+// AdaptiveRungeKutta is an embedded Runge-Kutta pair stepped one attempt at a time, see
+// AdaptiveIntegrator to integrate over an interval
+type AdaptiveRungeKutta struct {
+	adaptiveRKBase
+}
+
+// NewAdaptiveRungeKutta creates an adaptive method from the embedded pair registered under key
+func NewAdaptiveRungeKutta(key string, dt float64, tdFunc gridData.TDPotentialOp) (*AdaptiveRungeKutta, error) {
+	tab, err := LookupTableau(key)
+	if err != nil {
+		return nil, err
+	}
+	if !tab.Adaptive() {
+		return nil, fmt.Errorf("tableau %q has no embedded solution for step-size control", key)
+	}
+	return &AdaptiveRungeKutta{newAdaptiveRKBase(tab, dt, tdFunc)}, nil
+}
@@ -0,0 +1,176 @@
+package EquationSolver
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// riccati is y0' = -2 t y0^2, y1' = y0 with y0 = 1/(1 + t^2) and y1 = atan(t), a nonlinear
+// non-autonomous test that a linear problem could pass with too few order conditions
+var riccati = SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+	dydt[0] = -2 * t * y[0] * y[0]
+	dydt[1] = y[0]
+}}
+
+// logistic is dx/dt = x (1 - x) with x(t) = 1/(1 + exp(-t)) from x(0) = 1/2; a linear test
+// problem would not do here, as the Bogacki-Shampine error estimate vanishes on it
+type logistic struct{}
+
+func (logistic) EvaluateAt(x, t float64) float64 { return x * (1 - x) }
+func (l logistic) EvaluateOnRGrid(x []float64, t float64) []float64 {
+	res := make([]float64, len(x))
+	l.EvaluateOnRGridInPlace(x, res, t)
+	return res
+}
+func (l logistic) EvaluateOnRGridInPlace(x, res []float64, t float64) {
+	for i, v := range x {
+		res[i] = l.EvaluateAt(v, t)
+	}
+}
+
+func observedOrder(t *testing.T, tab *Tableau) float64 {
+	errorAt := func(nSteps int) float64 {
+		rk, err := NewRungeKuttaFromTableau(tab, 1/float64(nSteps), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		y := []float64{1, 0}
+		integrate(t, rk, riccati, y, rk.DeltaT(), nSteps)
+		return math.Hypot(y[0]-0.5, y[1]-math.Pi/4)
+	}
+	return math.Log2(errorAt(20) / errorAt(40))
+}
+
+func TestTableaux_Order(t *testing.T) {
+	for _, key := range TableauKeys() {
+		tab, err := LookupTableau(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tab.Validate(); err != nil {
+			t.Errorf("%s: %v", key, err)
+			continue
+		}
+		if order := observedOrder(t, tab); math.Abs(order-float64(tab.Order)) > 0.35 {
+			t.Errorf("%s: expected order %d, got %.2f", key, tab.Order, order)
+		}
+		if tab.Adaptive() {
+			low := &Tableau{Name: tab.Name, Order: tab.EmbeddedOrder, C: tab.C, A: tab.A, B: tab.BLow}
+			// Fehlberg 1(2) has an embedded error constant of 1/512 and reaches order 1 only for small steps
+			if order := observedOrder(t, low); order < float64(tab.EmbeddedOrder)-0.35 {
+				t.Errorf("%s: expected at least embedded order %d, got %.2f", key, tab.EmbeddedOrder, order)
+			}
+		}
+	}
+}
+
+func TestRungeKutta_ScalarGridSystem(t *testing.T) {
+	xt := []float64{1, -2, 0.5}
+	for _, key := range TableauKeys() {
+		rk, err := NewRungeKutta(key, 0.1, decay{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		grid := append([]float64(nil), xt...)
+		if err := rk.NextStepOnGrid(grid, 0); err != nil {
+			t.Fatalf("%s: unexpected error: %v", key, err)
+		}
+		for i, x := range xt {
+			scalar, err := rk.NextStep(x, 0)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", key, err)
+			}
+			if math.Abs(scalar-grid[i]) > 1e-15 {
+				t.Errorf("%s: expected the scalar step %v to match the grid step %v", key, scalar, grid[i])
+			}
+			if e := math.Abs(scalar - x*math.Exp(-0.1)); e > math.Pow(0.1, float64(rk.Tableau().Order)) {
+				t.Errorf("%s: expected exp(-h) x = %v, got %v", key, x*math.Exp(-0.1), scalar)
+			}
+		}
+	}
+}
+
+func TestRungeKutta_NamedMethodsScalar(t *testing.T) {
+	// the named methods share the tableau engine, so their scalar steps are exact to the same order
+	explicit := []struct {
+		solver ODESolver
+		order  int
+	}{
+		{new(Ralston3Order).NewDefine(0.1, decay{}), 3},
+		{new(Ralston4Order).NewDefine(0.1, decay{}), 4},
+		{new(Nystrom5Explicit).NewDefine(0.1, decay{}), 5},
+	}
+	for _, m := range explicit {
+		got, err := m.solver.NextStep(1, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if e := math.Abs(got - math.Exp(-0.1)); e > math.Pow(0.1, float64(m.order+1)) {
+			t.Errorf("%T: expected exp(-h) = %v, got %v", m.solver, math.Exp(-0.1), got)
+		}
+	}
+
+	// a rejected scalar step returns x unchanged with ErrStepRejected and a smaller step size
+	adaptive := []interface {
+		ODESolver
+		DeltaT() float64
+	}{
+		new(BogackiShampine).NewDefine(1, logistic{}),
+		new(CashKarp).NewDefine(1, logistic{}),
+		new(DormandPrince).NewDefine(1, logistic{}),
+	}
+	for _, m := range adaptive {
+		h := m.DeltaT()
+		got, err := m.NextStep(0.5, 0)
+		for attempt := 0; errors.Is(err, ErrStepRejected) && attempt < 100; attempt++ {
+			if got != 0.5 || m.DeltaT() >= h {
+				t.Fatalf("%T: expected a rejection to keep x and shrink the step %v, got %v and %v", m, h, got, m.DeltaT())
+			}
+			h = m.DeltaT()
+			got, err = m.NextStep(0.5, 0)
+		}
+		if err != nil {
+			t.Fatalf("%T: unexpected error: %v", m, err)
+		}
+		if h == 1 {
+			t.Errorf("%T: expected the first step to be rejected", m)
+		}
+		if e := math.Abs(got - 1/(1+math.Exp(-h))); e > 2*adaptiveTolerance {
+			t.Errorf("%T: expected x(h) = %v, got %v", m, 1/(1+math.Exp(-h)), got)
+		}
+	}
+}
+
+func TestRegisterTableau(t *testing.T) {
+	if _, err := NewRungeKutta("no-such-method", 0.1, nil); err == nil {
+		t.Errorf("expected an error for an unregistered method")
+	}
+	if _, err := NewAdaptiveRungeKutta("rk4", 0.1, nil); err == nil {
+		t.Errorf("expected an error for an adaptive method without an embedded solution")
+	}
+	if err := RegisterTableau("rk4", &rk4Tableau); err == nil {
+		t.Errorf("expected an error for a duplicate key")
+	}
+
+	inconsistent := &Tableau{Name: "bad", Order: 2, C: []float64{0, 0.5}, A: [][]float64{{1}}, B: []float64{0, 1}}
+	if err := RegisterTableau("test-bad", inconsistent); err == nil {
+		t.Errorf("expected an error for rows of A that don't sum to the nodes")
+	}
+
+	// generic second-order family with c2 = 1/4, registered as data only
+	ralston := &Tableau{Name: "alpha = 1/4", Order: 2, C: []float64{0, 0.25}, A: [][]float64{{0.25}}, B: []float64{-1, 2}}
+	if err := RegisterTableau("test-alpha-quarter", ralston); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer delete(tableaux, "test-alpha-quarter")
+	rk, err := NewRungeKutta("test-alpha-quarter", 0.1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	y := []float64{1, 0}
+	integrate(t, rk, oscillator, y, 0.1, 10)
+	if e := math.Hypot(y[0]-math.Cos(1), y[1]+math.Sin(1)); e > 1e-2 {
+		t.Errorf("expected the registered method to integrate the oscillator, off by %v", e)
+	}
+}
@@ -0,0 +1,209 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+)
+
+// SparsityPattern lists for every row i of a Jacobian the columns j where dF_i/dy_j may be
+// nonzero
+type SparsityPattern [][]int
+
+// SparseSystem is an ODESystem with a sparse Jacobian. The Jacobian values are taken from
+// finite differences in which columns without common rows are perturbed together, so a
+// banded system of bandwidth w costs about w evaluations instead of one per component.
+// The implicit methods read the pattern once while they step the same comparable system,
+// so it must not change between steps.
+type SparseSystem interface {
+	ODESystem
+	JacobianPattern() SparsityPattern
+}
+
+// SparseJacobianSystem also supplies the Jacobian values, values[i][k] = dF_i/dy_j for
+// j = pattern[i][k]; values arrives zeroed with the shape of the pattern
+type SparseJacobianSystem interface {
+	SparseSystem
+	SparseJacobian(t float64, y []float64, values [][]float64)
+}
+
+// sparseJacobianWork evaluates a sparse Jacobian in the layout of its pattern
+type sparseJacobianWork struct {
+	pattern SparsityPattern
+	values  [][]float64
+	// groups are the sets of columns perturbed together, colRows the rows of every column
+	groups  [][]int
+	colRows [][]int
+	yPert   []float64
+	fPert   []float64
+	steps   []float64
+}
+
+// setPattern checks the pattern of an n-dimensional system and colors its columns
+func (sw *sparseJacobianWork) setPattern(pattern SparsityPattern, n int) error {
+	if len(pattern) != n {
+		return fmt.Errorf("sparsity pattern has %d rows, expected %d", len(pattern), n)
+	}
+	sw.pattern = pattern
+	sw.values = make([][]float64, n)
+	sw.colRows = make([][]int, n)
+	for i, row := range pattern {
+		sw.values[i] = make([]float64, len(row))
+		for _, j := range row {
+			if j < 0 || j >= n {
+				return fmt.Errorf("sparsity pattern column %d out of range in row %d", j, i)
+			}
+			sw.colRows[j] = append(sw.colRows[j], i)
+		}
+	}
+
+	// greedy coloring: a column joins the first group none of whose columns shares a row with it
+	color := make([]int, n)
+	mark := make([]int, n+1)
+	for j := range mark {
+		mark[j] = -1
+	}
+	sw.groups = sw.groups[:0]
+	for j := 0; j < n; j++ {
+		for _, i := range sw.colRows[j] {
+			for _, c := range pattern[i] {
+				if c < j {
+					mark[color[c]] = j
+				}
+			}
+		}
+		g := 0
+		for mark[g] == j {
+			g++
+		}
+		color[j] = g
+		if g == len(sw.groups) {
+			sw.groups = append(sw.groups, nil)
+		}
+		sw.groups[g] = append(sw.groups[g], j)
+	}
+
+	sw.yPert = make([]float64, n)
+	sw.fPert = make([]float64, n)
+	sw.steps = make([]float64, n)
+	return nil
+}
+
+// evaluate sets sw.values to dF/dy at (t, y), where f holds F(t, y), and returns the number
+// of derivative evaluations spent on finite differences
+func (sw *sparseJacobianWork) evaluate(sys SparseSystem, t float64, y, f []float64) int {
+	for _, row := range sw.values {
+		for k := range row {
+			row[k] = 0
+		}
+	}
+	if js, ok := sys.(SparseJacobianSystem); ok {
+		js.SparseJacobian(t, y, sw.values)
+		return 0
+	}
+
+	copy(sw.yPert, y)
+	for _, group := range sw.groups {
+		for _, j := range group {
+			sw.yPert[j] = y[j] + math.Sqrt(machineEps)*math.Max(1, math.Abs(y[j]))
+			sw.steps[j] = sw.yPert[j] - y[j]
+		}
+		sys.Derivative(t, sw.yPert, sw.fPert)
+		for _, j := range group {
+			sw.yPert[j] = y[j]
+			for _, i := range sw.colRows[j] {
+				for k, c := range sw.pattern[i] {
+					if c == j {
+						sw.values[i][k] = (sw.fPert[i] - f[i]) / sw.steps[j]
+					}
+				}
+			}
+		}
+	}
+	return len(sw.groups)
+}
+
+// bandwidths returns the numbers of sub- and superdiagonals of the pattern
+func (p SparsityPattern) bandwidths() (kl, ku int) {
+	for i, row := range p {
+		for _, j := range row {
+			kl = max(kl, i-j)
+			ku = max(ku, j-i)
+		}
+	}
+	return kl, ku
+}
+
+// bandLU is the LU decomposition with partial pivoting of a matrix with kl sub- and ku
+// superdiagonals. Column j is stored from row j - ku - kl on, leaving room for the fill-in
+// of the row interchanges as in LAPACK's dgbtrf.
+type bandLU struct {
+	n, kl, ku int
+	ab        []float64
+	piv       []int
+}
+
+func (b *bandLU) reset(n, kl, ku int) {
+	b.n, b.kl, b.ku = n, kl, ku
+	size := n * (2*kl + ku + 1)
+	if cap(b.ab) < size {
+		b.ab = make([]float64, size)
+		b.piv = make([]int, n)
+	}
+	b.ab = b.ab[:size]
+	b.piv = b.piv[:n]
+	for i := range b.ab {
+		b.ab[i] = 0
+	}
+}
+
+func (b *bandLU) at(i, j int) *float64 {
+	return &b.ab[j*(2*b.kl+b.ku+1)+i-j+b.ku+b.kl]
+}
+
+func (b *bandLU) factorize() error {
+	for k := 0; k < b.n; k++ {
+		last, lastCol := min(b.n-1, k+b.kl), min(b.n-1, k+b.ku+b.kl)
+		p := k
+		for i := k + 1; i <= last; i++ {
+			if math.Abs(*b.at(i, k)) > math.Abs(*b.at(p, k)) {
+				p = i
+			}
+		}
+		b.piv[k] = p
+		if *b.at(p, k) == 0 {
+			return fmt.Errorf("singular matrix: zero pivot in column %d", k)
+		}
+		if p != k {
+			for j := k; j <= lastCol; j++ {
+				*b.at(k, j), *b.at(p, j) = *b.at(p, j), *b.at(k, j)
+			}
+		}
+		for i := k + 1; i <= last; i++ {
+			l := *b.at(i, k) / *b.at(k, k)
+			*b.at(i, k) = l
+			for j := k + 1; j <= lastCol; j++ {
+				*b.at(i, j) -= l * *b.at(k, j)
+			}
+		}
+	}
+	return nil
+}
+
+// solve overwrites x with the solution of A x = x
+func (b *bandLU) solve(x []float64) {
+	for k := 0; k < b.n; k++ {
+		if p := b.piv[k]; p != k {
+			x[k], x[p] = x[p], x[k]
+		}
+		for i := k + 1; i <= min(b.n-1, k+b.kl); i++ {
+			x[i] -= *b.at(i, k) * x[k]
+		}
+	}
+	for k := b.n - 1; k >= 0; k-- {
+		sum := x[k]
+		for j := k + 1; j <= min(b.n-1, k+b.ku+b.kl); j++ {
+			sum -= *b.at(k, j) * x[j]
+		}
+		x[k] = sum / *b.at(k, k)
+	}
+}
@@ -0,0 +1,284 @@
+package EquationSolver
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// robertson is the stiff chemical kinetics problem of Robertson with rate constants spanning
+// nine orders of magnitude; robertsonY40 is its solution at t = 40 from Hairer and Wanner
+var robertson = SystemFunc{N: 3, F: func(t float64, y, dydt []float64) {
+	dydt[0] = -0.04*y[0] + 1e4*y[1]*y[2]
+	dydt[2] = 3e7 * y[1] * y[1]
+	dydt[1] = -dydt[0] - dydt[2]
+}}
+
+var robertsonY40 = []float64{0.7158270687193941, 9.185534764557e-06, 0.2841637457458}
+
+// robertsonJacobian supplies the analytic Jacobian of robertson
+type robertsonJacobian struct{ SystemFunc }
+
+func (robertsonJacobian) Jacobian(t float64, y []float64, jac *mat.Dense) {
+	jac.Set(0, 0, -0.04)
+	jac.Set(0, 1, 1e4*y[2])
+	jac.Set(0, 2, 1e4*y[1])
+	jac.Set(2, 1, 6e7*y[1])
+	for j := 0; j < 3; j++ {
+		jac.Set(1, j, -jac.At(0, j)-jac.At(2, j))
+	}
+}
+
+func stiffSolvers(t *testing.T, ctrl StepControl) []Integrator {
+	bdf, err := NewBDF(5, ctrl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return []Integrator{bdf, NewRosenbrockW(ctrl), NewRadau(ctrl)}
+}
+
+func TestStiff_Robertson(t *testing.T) {
+	ctrl := StepControl{Atol: []float64{1e-8}, Rtol: []float64{1e-6}}
+	dp, err := NewAdaptiveIntegrator("dormand-prince", ctrl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	explicit, err := dp.Integrate(robertson, []float64{1, 0, 0}, 0, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, solver := range stiffSolvers(t, ctrl) {
+		y := []float64{1, 0, 0}
+		stats, err := solver.Integrate(robertson, y, 0, 40)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+		for i := range y {
+			if math.Abs(y[i]-robertsonY40[i]) > 1e-5*math.Max(robertsonY40[i], 1e-3) {
+				t.Errorf("%s: expected y(40) = %v, got %v", solver.Name(), robertsonY40, y)
+				break
+			}
+		}
+		// the linear invariant y0 + y1 + y2 = 1 is conserved up to rounding
+		if s := y[0] + y[1] + y[2]; math.Abs(s-1) > 1e-12 {
+			t.Errorf("%s: expected the concentrations to sum to 1, got %v", solver.Name(), s)
+		}
+		if stats.Jacobians == 0 || stats.Factorizations == 0 {
+			t.Errorf("%s: expected Jacobian evaluations and factorizations, got %+v", solver.Name(), stats)
+		}
+		if stats.Accepted*20 > explicit.Accepted {
+			t.Errorf("%s: expected far fewer steps than the %d of %s, got %d", solver.Name(), explicit.Accepted, dp.Name(), stats.Accepted)
+		}
+	}
+}
+
+func TestStiff_UserJacobian(t *testing.T) {
+	ctrl := StepControl{Atol: []float64{1e-8}, Rtol: []float64{1e-6}}
+	analytic := robertsonJacobian{robertson}
+	for i, solver := range stiffSolvers(t, ctrl) {
+		y := []float64{1, 0, 0}
+		differenced, err := solver.Integrate(robertson, y, 0, 40)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		yJac := []float64{1, 0, 0}
+		supplied, err := stiffSolvers(t, ctrl)[i].Integrate(analytic, yJac, 0, 40)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+
+		if math.Abs(y[0]-yJac[0]) > 1e-6 || math.Abs(y[2]-yJac[2]) > 1e-6 {
+			t.Errorf("%s: expected the same solution with both Jacobians, got %v and %v", solver.Name(), y, yJac)
+		}
+		// finite differences cost one evaluation per component and Jacobian
+		if supplied.Evaluations >= differenced.Evaluations-differenced.Jacobians*3+supplied.Jacobians {
+			t.Errorf("%s: expected fewer evaluations with the analytic Jacobian, got %d and %d", solver.Name(), supplied.Evaluations, differenced.Evaluations)
+		}
+	}
+}
+
+func TestStiff_FixedStepOrder(t *testing.T) {
+	// steps of the Rosenbrock-W method reach order 3, embedded order 2, with any matrix J
+	for _, jacScale := range []float64{1, 0.5, 0} {
+		rosenbrockError := func(nSteps int, embedded bool) float64 {
+			rb := NewRosenbrockW(StepControl{})
+			y := []float64{1, 0}
+			var stats IntegrationStats
+			h := 1 / float64(nSteps)
+			for i := 0; i < nSteps; i++ {
+				rb.prepare(riccati, float64(i)*h, y, &stats)
+				rb.jw.jac.Scale(jacScale, rb.jw.jac)
+				if err := rb.step(riccati, y, float64(i)*h, h, &stats); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if embedded {
+					copy(y, rb.yLow)
+				} else {
+					copy(y, rb.yNew)
+				}
+			}
+			return math.Hypot(y[0]-0.5, y[1]-math.Pi/4)
+		}
+		if p := math.Log2(rosenbrockError(20, false) / rosenbrockError(40, false)); math.Abs(p-3) > 0.2 {
+			t.Errorf("Rosenbrock-W, J scaled by %g: expected order 3, observed %.2f", jacScale, p)
+		}
+		if p := math.Log2(rosenbrockError(20, true) / rosenbrockError(40, true)); math.Abs(p-2) > 0.2 {
+			t.Errorf("Rosenbrock-W, J scaled by %g: expected embedded order 2, observed %.2f", jacScale, p)
+		}
+	}
+
+	radauError := func(nSteps int) float64 {
+		ra := NewRadau(StepControl{Atol: []float64{1e-12}, Rtol: []float64{1e-12}})
+		y := []float64{1, 0}
+		var stats IntegrationStats
+		h := 1 / float64(nSteps)
+		for i := 0; i < nSteps; i++ {
+			ra.prepare(riccati, float64(i)*h, y, true, &stats)
+			ra.factorize(h, &stats)
+			if converged, err := ra.newton(riccati, y, float64(i)*h, h, 1e-3, &stats); !converged || err != nil {
+				t.Fatalf("expected the Newton iterations to converge, got %v", err)
+			}
+			if err := ra.estimate(riccati, y, float64(i)*h, h, false, &stats); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			copy(y, ra.yNew)
+			ra.keepStages(h)
+		}
+		return math.Hypot(y[0]-0.5, y[1]-math.Pi/4)
+	}
+	if p := math.Log2(radauError(10) / radauError(20)); math.Abs(p-5) > 0.3 {
+		t.Errorf("Radau IIA: expected order 5, observed %.2f", p)
+	}
+}
+
+func TestStiff_Control(t *testing.T) {
+	// y' = lambda (y - sin t) + cos t has the smooth solution sin t next to a transient
+	// decaying at the rate 1e6 that limits explicit steps to about 3e-6
+	const lambda = -1e6
+	prothero := SystemFunc{N: 1, F: func(t float64, y, dydt []float64) {
+		dydt[0] = lambda*(y[0]-math.Sin(t)) + math.Cos(t)
+	}}
+	for _, solver := range stiffSolvers(t, StepControl{Atol: []float64{1e-8}, Rtol: []float64{1e-8}}) {
+		y := []float64{0}
+		stats, err := solver.Integrate(prothero, y, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+		if math.Abs(y[0]-math.Sin(10)) > 1e-6 || stats.Accepted > 2000 {
+			t.Errorf("%s: expected sin(10) = %v in few steps, got %v after %+v", solver.Name(), math.Sin(10), y[0], stats)
+		}
+
+		// integrating the nonstiff problem back to the start recovers the initial state
+		y = []float64{1, 0}
+		if _, err := solver.Integrate(riccati, y, 0, 3); err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+		if _, err := solver.Integrate(riccati, y, 3, 0); err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+		if math.Abs(y[0]-1) > 1e-5 || math.Abs(y[1]) > 1e-5 {
+			t.Errorf("%s: expected to return to (1, 0), got %v", solver.Name(), y)
+		}
+
+		if _, err := solver.Integrate(robertson, []float64{1, 0}, 0, 1); err == nil {
+			t.Errorf("%s: expected an error for a state of the wrong dimension", solver.Name())
+		}
+	}
+
+	for _, solver := range stiffSolvers(t, StepControl{Atol: []float64{1e-8, 1e-8}, MaxSteps: 5}) {
+		if _, err := solver.Integrate(robertson, []float64{1, 0, 0}, 0, 40); err == nil {
+			t.Errorf("%s: expected an error for tolerances of the wrong length", solver.Name())
+		}
+		if _, err := solver.Integrate(riccati, []float64{1, 0}, 0, 100); err == nil {
+			t.Errorf("%s: expected an error when MaxSteps is exceeded", solver.Name())
+		}
+	}
+}
+
+// overflowedJacobian is dy/dt = -y with an infinite entry in its supplied Jacobian, so every
+// iteration matrix is singular whatever the step size
+type overflowedJacobian struct{ SystemFunc }
+
+func (overflowedJacobian) Jacobian(t float64, y []float64, jac *mat.Dense) {
+	jac.Set(0, 0, math.Inf(-1))
+	jac.Set(1, 1, -1)
+}
+
+func TestStiff_SingularJacobian(t *testing.T) {
+	sys := overflowedJacobian{SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+		dydt[0], dydt[1] = -y[0], -y[1]
+	}}}
+	for _, solver := range stiffSolvers(t, StepControl{Atol: []float64{1e-8}, Rtol: []float64{1e-6}}) {
+		_, err := solver.Integrate(sys, []float64{1, 1}, 0, 1)
+		var condition mat.Condition
+		if err == nil || !errors.As(err, &condition) || !strings.Contains(err.Error(), "singular iteration matrix") {
+			t.Errorf("%s: expected the failure to carry the singular iteration matrix, got %v", solver.Name(), err)
+		}
+	}
+}
+
+func TestBDF_Orders(t *testing.T) {
+	for _, maxOrder := range []int{0, 6} {
+		if _, err := NewBDF(maxOrder, StepControl{}); err == nil {
+			t.Errorf("expected an error for maximum order %d", maxOrder)
+		}
+	}
+
+	// higher orders take longer steps at a tight tolerance
+	const tol = 1e-9
+	previous := math.MaxInt
+	var y []float64
+	for maxOrder := 1; maxOrder <= 5; maxOrder++ {
+		bdf, err := NewBDF(maxOrder, StepControl{Atol: []float64{tol}, Rtol: []float64{tol}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		y = []float64{1, 0}
+		stats, err := bdf.Integrate(riccati, y, 0, 3)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", bdf.Name(), err)
+		}
+		if stats.Accepted >= previous {
+			t.Errorf("%s: expected fewer than %d steps, got %d", bdf.Name(), previous, stats.Accepted)
+		}
+		previous = stats.Accepted
+	}
+	if exact := riccatiAt(3); math.Hypot(y[0]-exact[0], y[1]-exact[1]) > 100*tol {
+		t.Errorf("expected y(3) = %v, got %v", exact, y)
+	}
+}
+
+func TestRadau_RefinedEstimate(t *testing.T) {
+	// riccati reads y0 after writing dydt[0], so an evaluation with aliased state and
+	// derivative would differ from the same equations written in the other order
+	reversed := SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+		dydt[1] = y[0]
+		dydt[0] = -2 * t * y[0] * y[0]
+	}}
+	const t0, h = 0.5, 0.2
+	estimates := make([][]float64, 0, 2)
+	for _, sys := range []ODESystem{riccati, reversed} {
+		ra := NewRadau(StepControl{Atol: []float64{1e-10}, Rtol: []float64{1e-10}})
+		y := riccatiAt(t0)
+		var stats IntegrationStats
+		ra.prepare(sys, t0, y, true, &stats)
+		ra.factorize(h, &stats)
+		if converged, err := ra.newton(sys, y, t0, h, 1e-3, &stats); !converged || err != nil {
+			t.Fatalf("expected the Newton iterations to converge, got %v", err)
+		}
+		if err := ra.estimate(sys, y, t0, h, true, &stats); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		estimates = append(estimates, append([]float64(nil), ra.yLow...))
+	}
+	for i := range estimates[0] {
+		if math.Abs(estimates[0][i]-estimates[1][i]) > 1e-14 {
+			t.Errorf("expected the refined estimate not to depend on the evaluation order, got %v and %v", estimates[0], estimates[1])
+			break
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package EquationSolver
+
+import (
+	"GoProject/gridData"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrStepRejected is returned by an adaptive StepSystem when the error estimate is too
+// large; the state is left unchanged and the step size has already been reduced
+var ErrStepRejected = errors.New("step rejected, step size reduced")
+
+// ODESystem is a coupled first-order system dy/dt = F(t, y) on a state vector of length Dim
+type ODESystem interface {
+	Dim() int
+	Derivative(t float64, y, dydt []float64)
+}
+
+// SystemSolver advances a coupled system in place by one step from t
+type SystemSolver interface {
+	StepSystem(sys ODESystem, y []float64, t float64) error
+	Name() string
+}
+
+// SystemFunc adapts a plain function to ODESystem
+type SystemFunc struct {
+	N int
+	F func(t float64, y, dydt []float64)
+}
+
+func (s SystemFunc) Dim() int                                { return s.N }
+func (s SystemFunc) Derivative(t float64, y, dydt []float64) { s.F(t, y, dydt) }
+
+// gridSystem treats a time-dependent function as N uncoupled equations dx_i/dt = f(x_i, t)
+type gridSystem struct {
+	tdFunc  gridData.TDPotentialOp
+	nPoints int
+}
+
+// GridSystem wraps the pointwise equations solved by NextStepOnGrid as an ODESystem; it is
+// a SparseSystem, so its Jacobian costs a single extra evaluation
+func GridSystem(tdFunc gridData.TDPotentialOp, nPoints int) ODESystem {
+	return gridSystem{tdFunc: tdFunc, nPoints: nPoints}
+}
+
+func (g gridSystem) Dim() int { return g.nPoints }
+func (g gridSystem) Derivative(t float64, y, dydt []float64) {
+	g.tdFunc.EvaluateOnRGridInPlace(y, dydt, t)
+}
+
+// JacobianPattern is diagonal, as the grid points don't couple
+func (g gridSystem) JacobianPattern() SparsityPattern {
+	cols := make([]int, g.nPoints)
+	pattern := make(SparsityPattern, g.nPoints)
+	for i := range pattern {
+		cols[i] = i
+		pattern[i] = cols[i : i+1]
+	}
+	return pattern
+}
+
+func checkDim(sys ODESystem, y []float64) error {
+	if sys.Dim() != len(y) {
+		return fmt.Errorf("state length %d doesn't match system dimension %d", len(y), sys.Dim())
+	}
+	return nil
+}
+
+func checkFinite(y []float64) error {
+	for _, v := range y {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid derivative: NaN or Inf encountered")
+		}
+	}
+	return nil
+}
+
+// stages is the workspace of an explicit method applied to a system
+type stages struct {
+	k    [][]float64
+	yTmp []float64
+	yLow []float64
+}
+
+func (s *stages) allocate(nStages, n int) {
+	if len(s.k) != nStages || len(s.yTmp) != n {
+		s.k = make([][]float64, nStages)
+		for i := range s.k {
+			s.k[i] = make([]float64, n)
+		}
+		s.yTmp = make([]float64, n)
+		s.yLow = make([]float64, n)
+	}
+}
+
+// evaluate computes all stage derivatives k_i for the step y(t) -> y(t + h)
+func (s *stages) evaluate(sys ODESystem, y []float64, t, h float64, tab *Tableau) {
+	s.allocate(len(tab.C), len(y))
+	sys.Derivative(t, y, s.k[0])
+	for i := 1; i < len(tab.C); i++ {
+		combine(s.yTmp, y, h, tab.A[i-1], s.k)
+		sys.Derivative(t+tab.C[i]*h, s.yTmp, s.k[i])
+	}
+}
+
+// combine sets out = y + h Sum_j w_j k_j
+func combine(out, y []float64, h float64, w []float64, k [][]float64) {
+	copy(out, y)
+	for j, wj := range w {
+		if wj == 0 {
+			continue
+		}
+		for i := range out {
+			out[i] += h * wj * k[j][i]
+		}
+	}
+}
+
+// explicitStep advances y by one step of an explicit method
+func (s *stages) explicitStep(sys ODESystem, y []float64, t, h float64, tab *Tableau) error {
+	if err := checkDim(sys, y); err != nil {
+		return err
+	}
+	s.evaluate(sys, y, t, h, tab)
+	combine(s.yTmp, y, h, tab.B, s.k)
+	if err := checkFinite(s.yTmp); err != nil {
+		return err
+	}
+	copy(y, s.yTmp)
+	return nil
+}
+
+// adaptiveStep tries one embedded step of size h: the higher-order solution is taken when the
+// scaled error estimate of ctrl is within one, otherwise y is left unchanged and ErrStepRejected
+// is returned; either way the returned value is the step size proposed by ctrl
+func (s *stages) adaptiveStep(sys ODESystem, y []float64, t, h float64, tab *Tableau, ctrl *controller) (float64, error) {
+	if err := checkDim(sys, y); err != nil {
+		return h, err
+	}
+	s.evaluate(sys, y, t, h, tab)
+	combine(s.yTmp, y, h, tab.B, s.k)
+	combine(s.yLow, y, h, tab.BLow, s.k)
+	if err := checkFinite(s.yTmp); err != nil {
+		return h, err
+	}
+	if err := checkFinite(s.yLow); err != nil {
+		return h, err
+	}
+
+	errNorm := ctrl.errorNorm(y, s.yTmp, s.yLow)
+	if errNorm <= 1 {
+		copy(y, s.yTmp)
+		return ctrl.accept(errNorm, h), nil
+	}
+	return ctrl.reject(errNorm, h), ErrStepRejected
+}
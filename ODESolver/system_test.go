@@ -0,0 +1,418 @@
+package EquationSolver
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// oscillator is d2x/dt2 = -x written as y = (x, v)
+var oscillator = SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+	dydt[0] = y[1]
+	dydt[1] = -y[0]
+}}
+
+// decay is dx/dt = -x applied pointwise on a grid
+type decay struct{}
+
+func (decay) EvaluateAt(x, t float64) float64 { return -x }
+func (d decay) EvaluateOnRGrid(x []float64, t float64) []float64 {
+	res := make([]float64, len(x))
+	d.EvaluateOnRGridInPlace(x, res, t)
+	return res
+}
+func (decay) EvaluateOnRGridInPlace(x, res []float64, t float64) {
+	for i, v := range x {
+		res[i] = -v
+	}
+}
+
+func integrate(t *testing.T, solver SystemSolver, sys ODESystem, y []float64, dt float64, nSteps int) {
+	for step := 0; step < nSteps; step++ {
+		if err := solver.StepSystem(sys, y, float64(step)*dt); err != nil {
+			t.Fatalf("%s: unexpected error: %v", solver.Name(), err)
+		}
+	}
+}
+
+func TestExplicitMethods_SystemOrder(t *testing.T) {
+	methods := []struct {
+		order int
+		init  func(dt float64) SystemSolver
+	}{
+		{1, func(dt float64) SystemSolver { return new(EulerExplicit).NewDefine(dt, nil) }},
+		{2, func(dt float64) SystemSolver { return new(HeunsExplicit).NewDefine(dt, nil) }},
+		{2, func(dt float64) SystemSolver { return new(MidPointExplicit).NewDefine(dt, nil) }},
+		{2, func(dt float64) SystemSolver { return new(Ralston2order).NewDefine(dt, nil) }},
+		{3, func(dt float64) SystemSolver { return new(Ralston3Order).NewDefine(dt, nil) }},
+		{3, func(dt float64) SystemSolver { return new(Huens3Explicit).NewDef(dt, nil) }},
+		{3, func(dt float64) SystemSolver { return new(VDHouwenExplicit).NewDef(dt, nil) }},
+		{3, func(dt float64) SystemSolver { return new(SSPRungeKutta3).NewDef(dt, nil) }},
+		{3, func(dt float64) SystemSolver { return new(RungeKutta3Explicit).NewDef(dt, nil) }},
+		{4, func(dt float64) SystemSolver { return new(RungeKutta4Explicit).NewDef(dt, nil) }},
+		{4, func(dt float64) SystemSolver { return new(RungeKutta38).NewDefine(dt, nil) }},
+		{4, func(dt float64) SystemSolver { return new(Ralston4Order).NewDefine(dt, nil) }},
+		{5, func(dt float64) SystemSolver { return new(Nystrom5Explicit).NewDefine(dt, nil) }},
+	}
+
+	// the global error at t = 1 drops by 2^p when the step is halved
+	errorAt := func(solver SystemSolver, nSteps int) float64 {
+		y := []float64{1, 0}
+		integrate(t, solver, oscillator, y, 1/float64(nSteps), nSteps)
+		return math.Hypot(y[0]-math.Cos(1), y[1]+math.Sin(1))
+	}
+	for _, m := range methods {
+		coarse := errorAt(m.init(0.1), 10)
+		fine := errorAt(m.init(0.05), 20)
+		order := math.Log2(coarse / fine)
+		if math.Abs(order-float64(m.order)) > 0.3 {
+			t.Errorf("%s: expected order %d, got %.2f", m.init(0.1).Name(), m.order, order)
+		}
+	}
+}
+
+func TestExplicitMethods_LotkaVolterra(t *testing.T) {
+	const alpha, beta, gamma, delta = 1.5, 1., 3., 1.
+	predatorPrey := SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+		dydt[0] = alpha*y[0] - beta*y[0]*y[1]
+		dydt[1] = delta*y[0]*y[1] - gamma*y[1]
+	}}
+	invariant := func(y []float64) float64 {
+		return delta*y[0] - gamma*math.Log(y[0]) + beta*y[1] - alpha*math.Log(y[1])
+	}
+
+	y := []float64{10, 5}
+	v0 := invariant(y)
+	integrate(t, new(RungeKutta4Explicit).NewDef(0.001, nil), predatorPrey, y, 0.001, 10000)
+	if math.Abs(invariant(y)-v0) > 1e-6 {
+		t.Errorf("expected the Lotka-Volterra invariant %v to be conserved, got %v", v0, invariant(y))
+	}
+}
+
+func TestExplicitMethods_GridMatchesScalar(t *testing.T) {
+	rk4 := new(RungeKutta4Explicit).NewDef(0.1, decay{})
+	xt := []float64{1, -2, 0.5}
+	expected := make([]float64, len(xt))
+	for i, x := range xt {
+		expected[i], _ = rk4.NextStep(x, 0)
+	}
+	if err := rk4.NextStepOnGrid(xt, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range xt {
+		if math.Abs(xt[i]-expected[i]) > 1e-15 {
+			t.Errorf("expected grid step %v at point %d, got %v", expected[i], i, xt[i])
+		}
+	}
+
+	if err := rk4.StepSystem(oscillator, []float64{1, 0, 0}, 0); err == nil {
+		t.Errorf("expected an error for a state of the wrong length")
+	}
+}
+
+func TestAdaptiveMethods_System(t *testing.T) {
+	verner, err := NewAdaptiveRungeKutta("verner65", 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tsitouras, err := NewAdaptiveRungeKutta("tsitouras54", 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	methods := []interface {
+		SystemSolver
+		DeltaT() float64
+	}{
+		new(HuensEuler).NewDefine(1, nil),
+		new(FehlbergRK12).NewDefine(1, nil),
+		new(BogackiShampine).NewDefine(1, nil),
+		new(RKFelberg).NewDefine(1, nil),
+		new(CashKarp).NewDefine(1, nil),
+		new(DormandPrince).NewDefine(1, nil),
+		verner,
+		tsitouras,
+	}
+
+	for _, m := range methods {
+		y := []float64{1, 0}
+		time, accepted, rejected := 0., 0, 0
+		for accepted+rejected < 200 {
+			dt := m.DeltaT()
+			before := []float64{y[0], y[1]}
+			err := m.StepSystem(oscillator, y, time)
+			if errors.Is(err, ErrStepRejected) {
+				rejected++
+				if y[0] != before[0] || y[1] != before[1] {
+					t.Fatalf("%s: expected a rejected step to leave the state unchanged", m.Name())
+				}
+				if m.DeltaT() >= dt {
+					t.Fatalf("%s: expected a rejection to shrink the step %v, got %v", m.Name(), dt, m.DeltaT())
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", m.Name(), err)
+			}
+			time += dt
+			accepted++
+		}
+		if accepted == 0 || rejected == 0 {
+			t.Errorf("%s: expected both accepted and rejected steps, got %d and %d", m.Name(), accepted, rejected)
+		}
+		if e := math.Hypot(y[0]-math.Cos(time), y[1]+math.Sin(time)); e > 1e-6 {
+			t.Errorf("%s: expected the solution at t = %v within 1e-6, off by %v", m.Name(), time, e)
+		}
+	}
+}
+
+func TestImplicitMethods_CoupledLinear(t *testing.T) {
+	// y' = A y with A = [[-2, 1], [1, -2]] has modes (1, 1) and (1, -1) with rates -1 and -3;
+	// each mode is multiplied by the method's amplification factor R(h lambda) per step
+	coupled := SystemFunc{N: 2, F: func(t float64, y, dydt []float64) {
+		dydt[0] = -2*y[0] + y[1]
+		dydt[1] = y[0] - 2*y[1]
+	}}
+	backward := func(z float64) float64 { return 1 / (1 - z) }
+	trapezoid := func(z float64) float64 { return (1 + z/2) / (1 - z/2) }
+
+	methods := []struct {
+		solver        SystemSolver
+		amplification func(float64) float64
+		tol           float64
+	}{
+		{&EulerImplicitFixPoint{DeltaT: 0.05}, backward, 1e-6},
+		{&HeunsImplicitFixPoint{DeltaT: 0.05}, trapezoid, 1e-6},
+		{&MidPointFixPoint{DeltaT: 0.05}, trapezoid, 1e-6},
+		{&EulerImplicitNewton{DeltaT: 0.5}, backward, 1e-8},
+		{&HeunsImplicitNewton{DeltaT: 0.5}, trapezoid, 1e-8},
+		{&MidPointNewton{DeltaT: 0.5}, trapezoid, 1e-8},
+	}
+
+	const nSteps = 10
+	for _, m := range methods {
+		y := []float64{1, 0}
+		var h float64
+		switch s := m.solver.(type) {
+		case *EulerImplicitFixPoint:
+			h = s.DeltaT
+		case *HeunsImplicitFixPoint:
+			h = s.DeltaT
+		case *MidPointFixPoint:
+			h = s.DeltaT
+		case *EulerImplicitNewton:
+			h = s.DeltaT
+		case *HeunsImplicitNewton:
+			h = s.DeltaT
+		case *MidPointNewton:
+			h = s.DeltaT
+		}
+		integrate(t, m.solver, coupled, y, h, nSteps)
+
+		slow := 0.5 * math.Pow(m.amplification(-h), nSteps)
+		fast := 0.5 * math.Pow(m.amplification(-3*h), nSteps)
+		if math.Abs(y[0]-(slow+fast)) > m.tol || math.Abs(y[1]-(slow-fast)) > m.tol {
+			t.Errorf("%s: expected (%v, %v), got %v", m.solver.Name(), slow+fast, slow-fast, y)
+		}
+	}
+}
+
+// reactionDiffusion is y_i' = (y_{i-1} - 2 y_i + y_{i+1}) / dx^2 - y_i^3 on n interior grid
+// points with zero boundaries; its stiff tridiagonal coupling defeats fixed-point iteration
+type reactionDiffusion struct{ n int }
+
+func (rd reactionDiffusion) Dim() int { return rd.n }
+func (rd reactionDiffusion) Derivative(t float64, y, dydt []float64) {
+	dx2 := 1 / float64((rd.n+1)*(rd.n+1))
+	for i := range y {
+		lap := -2 * y[i]
+		if i > 0 {
+			lap += y[i-1]
+		}
+		if i < rd.n-1 {
+			lap += y[i+1]
+		}
+		dydt[i] = lap/dx2 - y[i]*y[i]*y[i]
+	}
+}
+
+// tridiagonal declares the sparsity of reactionDiffusion
+type tridiagonal struct{ reactionDiffusion }
+
+func (td tridiagonal) JacobianPattern() SparsityPattern {
+	pattern := make(SparsityPattern, td.n)
+	for i := range pattern {
+		for j := max(0, i-1); j <= min(td.n-1, i+1); j++ {
+			pattern[i] = append(pattern[i], j)
+		}
+	}
+	return pattern
+}
+
+// analyticJacobian supplies the Jacobian of reactionDiffusion both dense and sparse
+type analyticJacobian struct{ tridiagonal }
+
+func (aj analyticJacobian) entry(y []float64, i, j int) float64 {
+	dx2 := 1 / float64((aj.n+1)*(aj.n+1))
+	if i == j {
+		return -2/dx2 - 3*y[i]*y[i]
+	}
+	return 1 / dx2
+}
+func (aj analyticJacobian) Jacobian(t float64, y []float64, jac *mat.Dense) {
+	for i, row := range aj.JacobianPattern() {
+		for _, j := range row {
+			jac.Set(i, j, aj.entry(y, i, j))
+		}
+	}
+}
+func (aj analyticJacobian) SparseJacobian(t float64, y []float64, values [][]float64) {
+	for i, row := range aj.JacobianPattern() {
+		for k, j := range row {
+			values[i][k] = aj.entry(y, i, j)
+		}
+	}
+}
+
+func TestImplicitNewton_LinearSolvers(t *testing.T) {
+	const (
+		n      = 40
+		dt     = 0.05
+		nSteps = 5
+	)
+	initial := func() []float64 {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = math.Sin(math.Pi * float64(i+1) / (n + 1))
+		}
+		return y
+	}
+	plain := reactionDiffusion{n}
+	systems := []ODESystem{plain, tridiagonal{plain}, analyticJacobian{tridiagonal{plain}}}
+
+	if err := (&EulerImplicitFixPoint{DeltaT: dt}).StepSystem(plain, initial(), 0); err == nil {
+		t.Errorf("expected fixed-point iteration to fail on the stiff system")
+	}
+
+	methods := []func(LinearSolver) SystemSolver{
+		func(l LinearSolver) SystemSolver { return &EulerImplicitNewton{DeltaT: dt, Linear: l} },
+		func(l LinearSolver) SystemSolver { return &HeunsImplicitNewton{DeltaT: dt, Linear: l} },
+		func(l LinearSolver) SystemSolver { return &MidPointNewton{DeltaT: dt, Linear: l} },
+	}
+	for _, method := range methods {
+		reference := initial()
+		integrate(t, method(DenseLU), plain, reference, dt, nSteps)
+		if reference[n/2] >= 1 {
+			t.Errorf("expected the solution to decay, got %v at the centre", reference[n/2])
+		}
+
+		for _, sys := range systems {
+			for _, linear := range []LinearSolver{AutoLinearSolver, DenseLU, SparseLU, NewtonGMRES} {
+				solver := method(linear)
+				y := initial()
+				if _, ok := sys.(SparseSystem); !ok && linear == SparseLU {
+					if err := solver.StepSystem(sys, y, 0); err == nil {
+						t.Errorf("%s: expected an error for a sparse solve without a pattern", solver.Name())
+					}
+					continue
+				}
+				integrate(t, solver, sys, y, dt, nSteps)
+				if e := floats.Distance(y, reference, math.Inf(1)); e > 1e-6 {
+					t.Errorf("%s, %T, solver %d: expected the dense Newton solution, off by %v", solver.Name(), sys, linear, e)
+				}
+			}
+		}
+	}
+}
+
+// growth is y' = y with a declared diagonal Jacobian
+type growth struct{ SystemFunc }
+
+func (growth) JacobianPattern() SparsityPattern { return SparsityPattern{{0}} }
+
+func TestImplicitNewton_Singular(t *testing.T) {
+	// I - h J vanishes for a backward Euler step of h = 1
+	sys := growth{SystemFunc{N: 1, F: func(t float64, y, dydt []float64) { dydt[0] = y[0] }}}
+	// the factorizations report the Newton iteration, GMRES its own breakdown
+	expected := map[LinearSolver]string{
+		DenseLU:     "singular Jacobian at iteration 0",
+		SparseLU:    "singular Jacobian at iteration 0",
+		NewtonGMRES: "singular matrix: GMRES breakdown",
+	}
+	for linear, message := range expected {
+		solver := &EulerImplicitNewton{DeltaT: 1, Linear: linear}
+		y := []float64{1}
+		if err := solver.StepSystem(sys, y, 0); err == nil || !strings.HasPrefix(err.Error(), message) {
+			t.Errorf("solver %d: expected an error starting with %q, got %v", linear, message, err)
+		}
+	}
+
+	var sw sparseJacobianWork
+	if err := sw.setPattern(SparsityPattern{{0, 2}, {1}}, 2); err == nil {
+		t.Errorf("expected an error for a pattern column out of range")
+	}
+	if err := sw.setPattern(tridiagonal{reactionDiffusion{40}}.JacobianPattern(), 40); err != nil || len(sw.groups) != 3 {
+		t.Errorf("expected a tridiagonal Jacobian to be differenced in 3 groups, got %d (%v)", len(sw.groups), err)
+	}
+}
+
+// countedPattern counts the reads of the sparsity pattern of reactionDiffusion
+type countedPattern struct {
+	tridiagonal
+	reads *int
+}
+
+func (cp countedPattern) JacobianPattern() SparsityPattern {
+	*cp.reads++
+	return cp.tridiagonal.JacobianPattern()
+}
+
+func TestImplicitNewton_PatternCache(t *testing.T) {
+	var reads int
+	sys := countedPattern{tridiagonal{reactionDiffusion{20}}, &reads}
+	solver := &EulerImplicitNewton{DeltaT: 1e-3, Linear: SparseLU}
+	y := make([]float64, 20)
+	for i := range y {
+		y[i] = math.Sin(math.Pi * float64(i+1) / 21)
+	}
+	for step := 0; step < 5; step++ {
+		if err := solver.StepSystem(sys, y, float64(step)*1e-3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("expected the pattern to be read once for 5 steps, got %d reads", reads)
+	}
+
+	// another system of the same size is colored anew
+	var otherReads int
+	if err := solver.StepSystem(countedPattern{tridiagonal{reactionDiffusion{20}}, &otherReads}, y, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if otherReads != 1 {
+		t.Errorf("expected the pattern of a new system to be read once, got %d reads", otherReads)
+	}
+}
+
+func TestImplicitNewton_GridMatchesScalar(t *testing.T) {
+	for _, solver := range []ODESolver{&EulerImplicitNewton{TdFunc: logistic{}, DeltaT: 0.5}, &HeunsImplicitNewton{TdFunc: logistic{}, DeltaT: 0.5}} {
+		grid := []float64{0.1, 0.5, 0.9, 1.5, 2}
+		points := slices.Clone(grid)
+		if err := solver.NextStepOnGrid(grid, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i, x := range points {
+			next, err := solver.NextStep(x, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(next-grid[i]) > 1e-7 {
+				t.Errorf("%T: expected the grid step from %v to match the scalar step %v, got %v", solver, x, next, grid[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package EquationSolver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Tableau is the Butcher tableau of an explicit Runge-Kutta method: stage i is evaluated at
+// t + C_i h and y + h Sum_j A_(i-1)j k_j, B gives the solution of order Order and BLow, if
+// any, the embedded solution of order EmbeddedOrder used for error estimation
+type Tableau struct {
+	Name          string
+	Order         int
+	EmbeddedOrder int
+
+	C    []float64
+	A    [][]float64
+	B    []float64
+	BLow []float64
+
+	// Dense, if set, writes the solution at t + theta h, 0 <= theta <= 1, inside an accepted
+	// step from y0 to y1 with stage derivatives k
+	Dense func(theta, h float64, y0, y1 []float64, k [][]float64, out []float64)
+}
+
+// Stages returns the number of function evaluations per step
+func (tab *Tableau) Stages() int { return len(tab.C) }
+
+// Adaptive reports whether the tableau has an embedded solution
+func (tab *Tableau) Adaptive() bool { return tab.BLow != nil }
+
+// Validate checks the shape of the tableau and the consistency conditions
+// Sum_j A_ij = C_i and Sum_i B_i = 1
+func (tab *Tableau) Validate() error {
+	const eps = 1e-12
+
+	nStages := len(tab.C)
+	if nStages == 0 || tab.C[0] != 0 {
+		return fmt.Errorf("tableau %q: the first node must be 0", tab.Name)
+	}
+	if len(tab.A) != nStages-1 {
+		return fmt.Errorf("tableau %q: expected %d rows in A, got %d", tab.Name, nStages-1, len(tab.A))
+	}
+	for i, row := range tab.A {
+		if len(row) > i+1 {
+			return fmt.Errorf("tableau %q: row %d of A has %d entries, an explicit method allows %d", tab.Name, i+1, len(row), i+1)
+		}
+		if sum := sumOf(row); math.Abs(sum-tab.C[i+1]) > eps {
+			return fmt.Errorf("tableau %q: row %d of A sums to %v, expected node %v", tab.Name, i+1, sum, tab.C[i+1])
+		}
+	}
+
+	weights := map[string][]float64{"B": tab.B}
+	if tab.BLow != nil {
+		weights["BLow"] = tab.BLow
+	}
+	for name, b := range weights {
+		if len(b) != nStages {
+			return fmt.Errorf("tableau %q: expected %d weights in %s, got %d", tab.Name, nStages, name, len(b))
+		}
+		if sum := sumOf(b); math.Abs(sum-1) > eps {
+			return fmt.Errorf("tableau %q: the weights in %s sum to %v, expected 1", tab.Name, name, sum)
+		}
+	}
+	if tab.Order < 1 || (tab.BLow != nil && tab.EmbeddedOrder < 1) {
+		return fmt.Errorf("tableau %q: the order must be positive", tab.Name)
+	}
+	return nil
+}
+
+func sumOf(v []float64) float64 {
+	sum := 0.
+	for _, x := range v {
+		sum += x
+	}
+	return sum
+}
+
+// tableaux is the registry of named methods, see RegisterTableau
+var tableaux = map[string]*Tableau{
+	"euler":            &eulerTableau,
+	"heun":             &heunTableau,
+	"midpoint":         &midPointTableau,
+	"ralston2":         &ralston2Tableau,
+	"ralston3":         &ralston3Tableau,
+	"heun3":            &heun3Tableau,
+	"wray3":            &vdHouwenTableau,
+	"ssprk3":           &ssprk3Tableau,
+	"rk3":              &rk3Tableau,
+	"rk4":              &rk4Tableau,
+	"rk38":             &rk38Tableau,
+	"ralston4":         &ralston4Tableau,
+	"nystrom5":         &nystrom5Tableau,
+	"heun-euler":       &heunEulerTableau,
+	"fehlberg12":       &fehlberg12Tableau,
+	"bogacki-shampine": &bogackiShampineTableau,
+	"fehlberg45":       &rkFehlbergTableau,
+	"cash-karp":        &cashKarpTableau,
+	"dormand-prince":   &dormandPrinceTableau,
+	"verner65":         &verner65Tableau,
+	"tsitouras54":      &tsitouras54Tableau,
+}
+
+// RegisterTableau adds a validated method under key, e.g. for NewRungeKutta(key, dt, tdFunc)
+func RegisterTableau(key string, tab *Tableau) error {
+	if _, ok := tableaux[key]; ok {
+		return fmt.Errorf("a tableau is already registered as %q", key)
+	}
+	if err := tab.Validate(); err != nil {
+		return err
+	}
+	tableaux[key] = tab
+	return nil
+}
+
+// LookupTableau returns the method registered under key
+func LookupTableau(key string) (*Tableau, error) {
+	tab, ok := tableaux[key]
+	if !ok {
+		return nil, fmt.Errorf("no tableau registered as %q", key)
+	}
+	return tab, nil
+}
+
+// TableauKeys returns the registered keys in sorted order
+func TableauKeys() []string {
+	keys := make([]string, 0, len(tableaux))
+	for key := range tableaux {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var eulerTableau = Tableau{
+	Name:  "Euler",
+	Order: 1,
+	C:     []float64{0},
+	A:     [][]float64{},
+	B:     []float64{1},
+}
+
+var heunTableau = Tableau{
+	Name:  "Heun",
+	Order: 2,
+	C:     []float64{0, 1},
+	A:     [][]float64{{1}},
+	B:     []float64{0.5, 0.5},
+}
+
+var midPointTableau = Tableau{
+	Name:  "Explicit midpoint",
+	Order: 2,
+	C:     []float64{0, 0.5},
+	A:     [][]float64{{0.5}},
+	B:     []float64{0, 1},
+}
+
+var ralston2Tableau = Tableau{
+	Name:  "Ralston order 2",
+	Order: 2,
+	C:     []float64{0, 2. / 3.},
+	A:     [][]float64{{2. / 3.}},
+	B:     []float64{0.25, 0.75},
+}
+
+var ralston3Tableau = Tableau{
+	Name:  "Ralston order 3",
+	Order: 3,
+	C:     []float64{0, 0.5, 0.75},
+	A:     [][]float64{{0.5}, {0, 0.75}},
+	B:     []float64{2. / 9., 1. / 3., 4. / 9.},
+}
+
+var heun3Tableau = Tableau{
+	Name:  "Heun order 3",
+	Order: 3,
+	C:     []float64{0, 1. / 3., 2. / 3.},
+	A:     [][]float64{{1. / 3.}, {0, 2. / 3.}},
+	B:     []float64{0.25, 0, 0.75},
+}
+
+var vdHouwenTableau = Tableau{
+	Name:  "Van der Houwen/Wray order 3",
+	Order: 3,
+	C:     []float64{0, 8. / 15., 2. / 3.},
+	A:     [][]float64{{8. / 15.}, {0.25, 5. / 12.}},
+	B:     []float64{0.25, 0, 0.75},
+}
+
+var ssprk3Tableau = Tableau{
+	Name:  "SSP Runge-Kutta order 3",
+	Order: 3,
+	C:     []float64{0, 1, 0.5},
+	A:     [][]float64{{1}, {0.25, 0.25}},
+	B:     []float64{1. / 6., 1. / 6., 2. / 3.},
+}
+
+var rk3Tableau = Tableau{
+	Name:  "Kutta order 3",
+	Order: 3,
+	C:     []float64{0, 0.5, 1},
+	A:     [][]float64{{0.5}, {-1, 2}},
+	B:     []float64{1. / 6., 2. / 3., 1. / 6.},
+}
+
+var rk4Tableau = Tableau{
+	Name:  "Classic Runge-Kutta order 4",
+	Order: 4,
+	C:     []float64{0, 0.5, 0.5, 1},
+	A:     [][]float64{{0.5}, {0, 0.5}, {0, 0, 1}},
+	B:     []float64{1. / 6., 1. / 3., 1. / 3., 1. / 6.},
+}
+
+var rk38Tableau = Tableau{
+	Name:  "Runge-Kutta 3/8 rule order 4",
+	Order: 4,
+	C:     []float64{0, 1. / 3., 2. / 3., 1},
+	A:     [][]float64{{1. / 3.}, {-1. / 3., 1}, {1, -1, 1}},
+	B:     []float64{1. / 8., 3. / 8., 3. / 8., 1. / 8.},
+}
+
+var ralston4Tableau = Tableau{
+	Name:  "Ralston order 4",
+	Order: 4,
+	C:     []float64{0, 0.4, (14. - 3*math.Sqrt(5.)) / 16., 1},
+	A: [][]float64{
+		{0.4},
+		{(-2889. + 1428*math.Sqrt(5.)) / 1024, (3785 - 1620*math.Sqrt(5.)) / 1024},
+		{(-3365 + 2094*math.Sqrt(5.)) / 6040, (-975 - 3046*math.Sqrt(5.)) / 2552, (467040 + 203968*math.Sqrt(5.)) / 240845},
+	},
+	B: []float64{(263 + 24*math.Sqrt(5.)) / 1812, (125 - 1000*math.Sqrt(5.)) / 3828,
+		(3426304 + 1661952*math.Sqrt(5.)) / 5924787, (30 - 4*math.Sqrt(5.)) / 123},
+}
+
+var nystrom5Tableau = Tableau{
+	Name:  "Nystrom order 5",
+	Order: 5,
+	C:     []float64{0, 1. / 3., 2. / 5., 1, 2. / 3., 4. / 5.},
+	A: [][]float64{
+		{1. / 3.},
+		{4. / 25., 6. / 25.},
+		{1. / 4., -3, 15. / 4.},
+		{2. / 27., 10. / 9., -50. / 81., 8. / 81.},
+		{2. / 25., 12. / 25., 2. / 15., 8. / 75., 0},
+	},
+	B: []float64{23. / 192., 0, 125. / 192., 0, -27. / 64., 125. / 192.},
+}
+
+var heunEulerTableau = Tableau{
+	Name:          "Heun-Euler 2(1)",
+	Order:         2,
+	EmbeddedOrder: 1,
+	C:             []float64{0, 1},
+	A:             [][]float64{{1}},
+	B:             []float64{0.5, 0.5},
+	BLow:          []float64{1, 0},
+}
+
+var fehlberg12Tableau = Tableau{
+	Name:          "Fehlberg 1(2)",
+	Order:         2,
+	EmbeddedOrder: 1,
+	C:             []float64{0, 0.5, 1},
+	A:             [][]float64{{0.5}, {1. / 256., 255. / 256.}},
+	B:             []float64{1. / 512., 255. / 256., 1. / 512.},
+	BLow:          []float64{1. / 256., 255. / 256., 0},
+}
+
+var bogackiShampineTableau = Tableau{
+	Name:          "Bogacki-Shampine 3(2)",
+	Order:         3,
+	EmbeddedOrder: 2,
+	C:             []float64{0, 0.5, 0.75, 1},
+	A:             [][]float64{{0.5}, {0, 0.75}, {2. / 9., 1. / 3., 4. / 9.}},
+	B:             []float64{2. / 9., 1. / 3., 4. / 9., 0},
+	BLow:          []float64{7. / 24., 0.25, 1. / 3., 1. / 8.},
+	Dense:         bogackiShampineDense,
+}
+
+// bogackiShampineDense is the cubic Hermite interpolant of y0, y1 and their derivatives
+// k_1 and k_4 = F(t + h, y1), third-order like the method
+func bogackiShampineDense(theta, h float64, y0, y1 []float64, k [][]float64, out []float64) {
+	t2 := theta * theta
+	t3 := t2 * theta
+	h00, h10 := 2*t3-3*t2+1, t3-2*t2+theta
+	h01, h11 := -2*t3+3*t2, t3-t2
+	for i := range out {
+		out[i] = h00*y0[i] + h10*h*k[0][i] + h01*y1[i] + h11*h*k[3][i]
+	}
+}
+
+var rkFehlbergTableau = Tableau{
+	Name:          "Runge-Kutta-Fehlberg 4(5)",
+	Order:         5,
+	EmbeddedOrder: 4,
+	C:             []float64{0, 0.25, 3. / 8., 12. / 13., 1, 0.5},
+	A: [][]float64{
+		{0.25},
+		{3. / 32., 9. / 32.},
+		{1932. / 2197., -7200. / 2197., 7296. / 2197.},
+		{439. / 216., -8, 3680. / 513., -845. / 4104.},
+		{-8. / 27., 2., -3544. / 2565., 1859. / 4104., -11. / 40.},
+	},
+	B:    []float64{16. / 135., 0, 6656. / 12825., 28561. / 56430., -9. / 50., 2. / 55.},
+	BLow: []float64{25. / 216., 0, 1408. / 2565., 2197. / 4104., -1. / 5., 0},
+}
+
+var cashKarpTableau = Tableau{
+	Name:          "Cash-Karp 5(4)",
+	Order:         5,
+	EmbeddedOrder: 4,
+	C:             []float64{0, 0.2, 3. / 10., 3. / 5., 1, 7. / 8.},
+	A: [][]float64{
+		{0.2},
+		{3. / 40., 9. / 40.},
+		{3. / 10., -9. / 10., 6. / 5.},
+		{-11. / 54., 5. / 2., -70. / 27., 35. / 27.},
+		{1631. / 55296., 175. / 512., 575. / 13824., 44275. / 110592., 253. / 4096.},
+	},
+	B:    []float64{37. / 378., 0, 250. / 621., 125. / 594., 0, 512. / 1771.},
+	BLow: []float64{2825. / 27648., 0, 18575. / 48384., 13525. / 55296., 277. / 14336., 0.25},
+}
+
+var dormandPrinceTableau = Tableau{
+	Name:          "Dormand-Prince 5(4)",
+	Order:         5,
+	EmbeddedOrder: 4,
+	C:             []float64{0, 0.2, 3. / 10., 4. / 5., 8. / 9., 1, 1},
+	A: [][]float64{
+		{0.2},
+		{3. / 40., 9. / 40.},
+		{44. / 45., -56. / 15., 32. / 9.},
+		{19372. / 6561., -25360. / 2187., 64448. / 6561., -212. / 729.},
+		{9017. / 3168., -355. / 33., 46732. / 5247., 49. / 176., -5103. / 18656.},
+		{35. / 384., 0, 500. / 1113., 125. / 192., -2187. / 6784., 11. / 84.},
+	},
+	B:     []float64{35. / 384., 0, 500. / 1113., 125. / 192., -2187. / 6784., 11. / 84., 0},
+	BLow:  []float64{5179. / 57600., 0, 7571. / 16695., 393. / 640., -92097. / 339200., 187. / 2100., 1. / 40.},
+	Dense: dormandPrinceDense,
+}
+
+// dormandPrinceDense is the fourth-order continuous extension of Dormand-Prince 5(4)
+// (Hairer, Norsett and Wanner, Solving ODEs I, II.6), using k_7 = F(t + h, y1)
+func dormandPrinceDense(theta, h float64, y0, y1 []float64, k [][]float64, out []float64) {
+	const (
+		d1 = -12715105075. / 11282082432.
+		d3 = 87487479700. / 32700410799.
+		d4 = -10690763975. / 1880347072.
+		d5 = 701980252875. / 199316789632.
+		d6 = -1453857185. / 822651844.
+		d7 = 69997945. / 29380423.
+	)
+	for i := range out {
+		r2 := y1[i] - y0[i]
+		r3 := h*k[0][i] - r2
+		r4 := r2 - h*k[6][i] - r3
+		r5 := h * (d1*k[0][i] + d3*k[2][i] + d4*k[3][i] + d5*k[4][i] + d6*k[5][i] + d7*k[6][i])
+		out[i] = y0[i] + theta*(r2+(1-theta)*(r3+theta*(r4+(1-theta)*r5)))
+	}
+}
+
+// verner65Tableau is Verner's 8-stage pair (DVERK)
+var verner65Tableau = Tableau{
+	Name:          "Verner 6(5)",
+	Order:         6,
+	EmbeddedOrder: 5,
+	C:             []float64{0, 1. / 6., 4. / 15., 2. / 3., 5. / 6., 1, 1. / 15., 1},
+	A: [][]float64{
+		{1. / 6.},
+		{4. / 75., 16. / 75.},
+		{5. / 6., -8. / 3., 5. / 2.},
+		{-165. / 64., 55. / 6., -425. / 64., 85. / 96.},
+		{12. / 5., -8, 4015. / 612., -11. / 36., 88. / 255.},
+		{-8263. / 15000., 124. / 75., -643. / 680., -81. / 250., 2484. / 10625., 0},
+		{3501. / 1720., -300. / 43., 297275. / 52632., -319. / 2322., 24068. / 84065., 0, 3850. / 26703.},
+	},
+	B:    []float64{3. / 40., 0, 875. / 2244., 23. / 72., 264. / 1955., 0, 125. / 11592., 43. / 616.},
+	BLow: []float64{13. / 160., 0, 2375. / 5984., 5. / 16., 12. / 85., 3. / 44., 0, 0},
+}
+
+// tsitouras54Tableau is the 5(4) pair of Ch. Tsitouras, Comput. Math. Appl. 62 (2011) 770
+var tsitouras54Tableau = Tableau{
+	Name:          "Tsitouras 5(4)",
+	Order:         5,
+	EmbeddedOrder: 4,
+	C:             []float64{0, 0.161, 0.327, 0.9, 0.9800255409045097, 1, 1},
+	A: [][]float64{
+		{0.161},
+		{-0.008480655492356989, 0.335480655492357},
+		{2.897153057105493, -6.359448489975075, 4.3622954328695815},
+		{5.325864828439257, -11.748883564062828, 7.4955393428898365, -0.09249506636175525},
+		{5.86145544294642, -12.92096931784711, 8.159367898576159, -0.071584973281401, -0.028269050394068383},
+		{0.09646076681806523, 0.01, 0.4798896504144996, 1.379008574103742, -3.290069515436081, 2.324710524099774},
+	},
+	B: []float64{0.09646076681806523, 0.01, 0.4798896504144996, 1.379008574103742,
+		-3.290069515436081, 2.324710524099774, 0},
+	BLow: []float64{0.09646076681806523 - 0.001780011052226, 0.01 - 0.000816434459657,
+		0.4798896504144996 + 0.007880878010262, 1.379008574103742 - 0.144711007173263,
+		-3.290069515436081 + 0.582357165452555, 2.324710524099774 - 0.458082105929187, 1. / 66.},
+}
@@ -0,0 +1,45 @@
+package Observables
+
+import (
+	"GoProject/gridData"
+	"fmt"
+)
+
+// Autocorrelation records C(t) = <psi(0)|psi(t)> along a propagation. Record fits the
+// observer of SplitOperator.Propagate:
+//
+//	so.Propagate(psi, func(r Quantum.PropagationReport, psi []complex128) { ac.Record(r.Time, psi) })
+type Autocorrelation struct {
+	dx     float64
+	psi0   []complex128
+	Times  []float64
+	Values []complex128
+}
+
+// NewAutocorrelation keeps a copy of the initial wavefunction
+func NewAutocorrelation(grid *gridData.RadGrid, psi0 []complex128) (*Autocorrelation, error) {
+	if len(psi0) != int(grid.NPoints()) {
+		return nil, fmt.Errorf("wavefunction length %d doesn't match grid size %d", len(psi0), grid.NPoints())
+	}
+	return &Autocorrelation{
+		dx:   grid.DeltaR(),
+		psi0: append([]complex128(nil), psi0...),
+	}, nil
+}
+
+// Record appends C(t) for the wavefunction at time t and returns it
+func (ac *Autocorrelation) Record(t float64, psi []complex128) complex128 {
+	if len(psi) != len(ac.psi0) {
+		panic(fmt.Sprintf("wavefunction length %d doesn't match grid size %d", len(psi), len(ac.psi0)))
+	}
+	value := overlap(ac.psi0, psi, ac.dx)
+	ac.Times = append(ac.Times, t)
+	ac.Values = append(ac.Values, value)
+	return value
+}
+
+// Reset drops the recorded values and keeps the initial wavefunction
+func (ac *Autocorrelation) Reset() {
+	ac.Times = ac.Times[:0]
+	ac.Values = ac.Values[:0]
+}
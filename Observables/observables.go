@@ -0,0 +1,270 @@
+package Observables
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Calculator evaluates expectation values of wavefunctions sampled on a RadGrid, with the
+// momentum p = -i d/dx applied by FourierBasis.MomentumOp. Wavefunctions need not be
+// normalized; expectation values are divided by the norm. A Calculator keeps one work
+// buffer, so use one per goroutine.
+type Calculator struct {
+	grid  *gridData.RadGrid
+	kinE  *OperatorAlgebra.FourierBasis
+	owned bool
+	mass  float64
+	x     []float64
+	x2    []float64
+	vPot  []float64
+	work  []complex128
+
+	// kSorted holds the momenta in ascending order and order[j] their FFT index
+	kSorted []float64
+	order   []int
+}
+
+// Expectations collects the one-pass expectation values of a wavefunction
+type Expectations struct {
+	Norm      float64
+	X         float64
+	X2        float64
+	P         float64
+	P2        float64
+	Kinetic   float64
+	Potential float64
+}
+
+// NewCalculator creates a calculator with its own Fourier basis; Pot may be nil when <V> is not needed
+func NewCalculator(grid *gridData.RadGrid, mass float64, Pot gridData.PotentialOp[float64]) (*Calculator, error) {
+	kinE, err := OperatorAlgebra.FFTInitWith(grid, mass, OperatorAlgebra.DefaultFFTBackend)
+	if err != nil {
+		return nil, err
+	}
+	c := NewCalculatorFromBasis(kinE, Pot)
+	c.owned = true
+	return c, nil
+}
+
+// NewCalculatorFromBasis shares an existing Fourier basis, e.g. SplitOperator.Kinetic(), so it
+// can be used inside a propagation loop; Close leaves the shared basis open
+func NewCalculatorFromBasis(kinE *OperatorAlgebra.FourierBasis, Pot gridData.PotentialOp[float64]) *Calculator {
+	grid := kinE.Grid()
+	kValues := kinE.KValues()
+	order := make([]int, len(kValues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return kValues[order[i]] < kValues[order[j]] })
+	kSorted := make([]float64, len(order))
+	for j, i := range order {
+		kSorted[j] = kValues[i]
+	}
+
+	c := &Calculator{
+		grid:    grid,
+		kinE:    kinE,
+		mass:    kinE.Mass(),
+		x:       grid.RValues(),
+		x2:      make([]float64, grid.NPoints()),
+		work:    make([]complex128, grid.NPoints()),
+		kSorted: kSorted,
+		order:   order,
+	}
+	for i, x := range c.x {
+		c.x2[i] = x * x
+	}
+	c.SetPotential(Pot)
+	return c
+}
+
+func (c *Calculator) Grid() *gridData.RadGrid                { return c.grid }
+func (c *Calculator) Kinetic() *OperatorAlgebra.FourierBasis { return c.kinE }
+
+// SetPotential replaces the potential used for <V>, e.g. between steps of a time-dependent propagation
+func (c *Calculator) SetPotential(Pot gridData.PotentialOp[float64]) {
+	c.vPot = nil
+	if Pot != nil {
+		c.vPot = c.grid.PotentialOnGrid(Pot)
+	}
+}
+
+// Close releases the Fourier basis if the calculator created it
+func (c *Calculator) Close() {
+	if c.owned {
+		c.kinE.Close()
+	}
+}
+
+func (c *Calculator) checkLen(psi []complex128) {
+	if len(psi) != len(c.x) {
+		panic(fmt.Sprintf("wavefunction length %d doesn't match grid size %d", len(psi), len(c.x)))
+	}
+}
+
+// density returns Sum_i |psi_i|^2
+func density(psi []complex128) float64 {
+	sum := 0.
+	for _, v := range psi {
+		sum += real(v)*real(v) + imag(v)*imag(v)
+	}
+	return sum
+}
+
+// weighted returns Sum_i w_i |psi_i|^2 / Sum_i |psi_i|^2
+func weighted(psi []complex128, w []float64) float64 {
+	sum := 0.
+	for i, v := range psi {
+		sum += w[i] * (real(v)*real(v) + imag(v)*imag(v))
+	}
+	return sum / density(psi)
+}
+
+// Norm returns Sum_i |psi_i|^2 dx
+func (c *Calculator) Norm(psi []complex128) float64 {
+	c.checkLen(psi)
+	return density(psi) * c.grid.DeltaR()
+}
+
+// Position returns <x>
+func (c *Calculator) Position(psi []complex128) float64 {
+	c.checkLen(psi)
+	return weighted(psi, c.x)
+}
+
+// PositionSquared returns <x^2>
+func (c *Calculator) PositionSquared(psi []complex128) float64 {
+	c.checkLen(psi)
+	return weighted(psi, c.x2)
+}
+
+// Momentum returns <p>
+func (c *Calculator) Momentum(psi []complex128) float64 {
+	c.checkLen(psi)
+	c.kinE.MomentumOp(psi, c.work)
+	sum := 0.
+	for i, v := range psi {
+		sum += real(cmplx.Conj(v) * c.work[i])
+	}
+	return sum / density(psi)
+}
+
+// MomentumSquared returns <p^2> = ||p psi||^2 / ||psi||^2
+func (c *Calculator) MomentumSquared(psi []complex128) float64 {
+	c.checkLen(psi)
+	c.kinE.MomentumOp(psi, c.work)
+	return density(c.work) / density(psi)
+}
+
+// KineticEnergy returns <T> = <p^2> / 2m
+func (c *Calculator) KineticEnergy(psi []complex128) float64 {
+	return c.MomentumSquared(psi) / (2 * c.mass)
+}
+
+// PotentialEnergy returns <V>, or zero when no potential was given
+func (c *Calculator) PotentialEnergy(psi []complex128) float64 {
+	c.checkLen(psi)
+	if c.vPot == nil {
+		return 0
+	}
+	return weighted(psi, c.vPot)
+}
+
+// Measure computes all expectation values with a single momentum transform
+func (c *Calculator) Measure(psi []complex128) Expectations {
+	c.checkLen(psi)
+	c.kinE.MomentumOp(psi, c.work)
+
+	var e Expectations
+	norm := 0.
+	for i, v := range psi {
+		rho := real(v)*real(v) + imag(v)*imag(v)
+		pv := c.work[i]
+		norm += rho
+		e.X += c.x[i] * rho
+		e.X2 += c.x[i] * c.x[i] * rho
+		e.P += real(cmplx.Conj(v) * pv)
+		e.P2 += real(pv)*real(pv) + imag(pv)*imag(pv)
+		if c.vPot != nil {
+			e.Potential += c.vPot[i] * rho
+		}
+	}
+
+	e.Norm = norm * c.grid.DeltaR()
+	e.X /= norm
+	e.X2 /= norm
+	e.P /= norm
+	e.P2 /= norm
+	e.Potential /= norm
+	e.Kinetic = e.P2 / (2 * c.mass)
+	return e
+}
+
+// MomentumSpace returns the momentum-space wavefunction
+// phi(k) = (2 pi)^-1/2 Int psi(x) exp(-i k x) dx on the momenta in ascending order
+func (c *Calculator) MomentumSpace(psi []complex128) (k []float64, phi []complex128) {
+	c.checkLen(psi)
+	c.kinE.ForwardTransform(psi, c.work)
+
+	scale := c.grid.DeltaR() / math.Sqrt(2*math.Pi)
+	x0 := c.grid.RMin()
+	phi = make([]complex128, len(c.order))
+	for j, i := range c.order {
+		phi[j] = c.work[i] * cmplx.Exp(complex(0, -c.kSorted[j]*x0)) * complex(scale, 0)
+	}
+	return append([]float64(nil), c.kSorted...), phi
+}
+
+// MomentumDensity returns |phi(k)|^2 on the momenta in ascending order, Sum_k |phi(k)|^2 dk = Norm
+func (c *Calculator) MomentumDensity(psi []complex128) (k []float64, rho []float64) {
+	c.checkLen(psi)
+	c.kinE.ForwardTransform(psi, c.work)
+
+	scale := c.grid.DeltaR() * c.grid.DeltaR() / (2 * math.Pi)
+	rho = make([]float64, len(c.order))
+	for j, i := range c.order {
+		v := c.work[i]
+		rho[j] = (real(v)*real(v) + imag(v)*imag(v)) * scale
+	}
+	return append([]float64(nil), c.kSorted...), rho
+}
+
+// Overlap returns <a|b> = Sum_i conj(a_i) b_i dx
+func (c *Calculator) Overlap(a, b []complex128) complex128 {
+	c.checkLen(a)
+	c.checkLen(b)
+	return overlap(a, b, c.grid.DeltaR())
+}
+
+func overlap(a, b []complex128, dx float64) complex128 {
+	var sum complex128
+	for i, v := range a {
+		sum += cmplx.Conj(v) * b[i]
+	}
+	return sum * complex(dx, 0)
+}
+
+// Energy returns <T> + <V>
+func (e Expectations) Energy() float64 { return e.Kinetic + e.Potential }
+
+// DeltaX returns the position uncertainty sqrt(<x^2> - <x>^2)
+func (e Expectations) DeltaX() float64 { return math.Sqrt(math.Max(e.X2-e.X*e.X, 0)) }
+
+// DeltaP returns the momentum uncertainty sqrt(<p^2> - <p>^2)
+func (e Expectations) DeltaP() float64 { return math.Sqrt(math.Max(e.P2-e.P*e.P, 0)) }
+
+// Uncertainty returns the product dx dp, at least 1/2
+func (e Expectations) Uncertainty() float64 { return e.DeltaX() * e.DeltaP() }
+
+// Complex converts a real wavefunction, e.g. EigenStates.Wavefunction, for the calculator
+func Complex(psi []float64) []complex128 {
+	out := make([]complex128, len(psi))
+	for i, v := range psi {
+		out[i] = complex(v, 0)
+	}
+	return out
+}
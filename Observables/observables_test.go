@@ -0,0 +1,210 @@
+package Observables
+
+import (
+	"GoProject/Quantum"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+)
+
+// gaussianPacket is (2 pi s^2)^-1/4 exp(-(x - x0)^2 / 4 s^2 + i p0 x)
+func gaussianPacket(x []float64, x0, p0, sigma float64) []complex128 {
+	norm := math.Pow(2*math.Pi*sigma*sigma, -0.25)
+	psi := make([]complex128, len(x))
+	for i, xi := range x {
+		d := xi - x0
+		psi[i] = complex(norm*math.Exp(-d*d/(4*sigma*sigma)), 0) * cmplx.Exp(complex(0, p0*xi))
+	}
+	return psi
+}
+
+func TestCalculator_GaussianPacket(t *testing.T) {
+	grid, err := gridData.NewFromLength(40., 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calc, err := NewCalculator(grid, 2., nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer calc.Close()
+
+	const x0, p0, sigma = 1.5, 2., 1.
+	psi := gaussianPacket(grid.RValues(), x0, p0, sigma)
+
+	e := calc.Measure(psi)
+	checks := []struct {
+		name     string
+		got, exp float64
+	}{
+		{"norm", e.Norm, 1},
+		{"<x>", e.X, x0},
+		{"<x^2>", e.X2, x0*x0 + sigma*sigma},
+		{"<p>", e.P, p0},
+		{"<p^2>", e.P2, p0*p0 + 1/(4*sigma*sigma)},
+		{"<T>", e.Kinetic, (p0*p0 + 1/(4*sigma*sigma)) / 4},
+		{"<V>", e.Potential, 0},
+		{"dx dp", e.Uncertainty(), 0.5},
+		{"Position", calc.Position(psi), x0},
+		{"PositionSquared", calc.PositionSquared(psi), x0*x0 + sigma*sigma},
+		{"Momentum", calc.Momentum(psi), p0},
+		{"MomentumSquared", calc.MomentumSquared(psi), p0*p0 + 1/(4*sigma*sigma)},
+		{"KineticEnergy", calc.KineticEnergy(psi), (p0*p0 + 1/(4*sigma*sigma)) / 4},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.exp) > 1e-10 {
+			t.Errorf("expected %s = %v, got %v", c.name, c.exp, c.got)
+		}
+	}
+
+	// phi(k) = (2 s^2 / pi)^1/4 exp(-s^2 (k - p0)^2 - i (k - p0) x0), peaked at +p0
+	k, phi := calc.MomentumSpace(psi)
+	amplitude := math.Pow(2*sigma*sigma/math.Pi, 0.25)
+	for j := range k {
+		d := k[j] - p0
+		expected := complex(amplitude*math.Exp(-sigma*sigma*d*d), 0) * cmplx.Exp(complex(0, -d*x0))
+		if cmplx.Abs(phi[j]-expected) > 1e-10 {
+			t.Fatalf("expected phi(%v) = %v, got %v", k[j], expected, phi[j])
+		}
+	}
+
+	k, rho := calc.MomentumDensity(psi)
+	norm, mean := 0., 0.
+	for j := range k {
+		if j > 0 && k[j] <= k[j-1] {
+			t.Fatalf("expected ascending momenta, got %v after %v", k[j], k[j-1])
+		}
+		norm += rho[j] * grid.DeltaK()
+		mean += k[j] * rho[j] * grid.DeltaK()
+	}
+	if math.Abs(norm-1) > 1e-10 || math.Abs(mean-p0) > 1e-10 {
+		t.Errorf("expected a unit momentum density with mean %v, got norm %v and mean %v", p0, norm, mean)
+	}
+
+	if ov := calc.Overlap(psi, psi); cmplx.Abs(ov-1) > 1e-10 {
+		t.Errorf("expected <psi|psi> = 1, got %v", ov)
+	}
+}
+
+func TestCalculator_LengthMismatch(t *testing.T) {
+	grid, err := gridData.NewFromLength(10., 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calc, err := NewCalculator(grid, 1., nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer calc.Close()
+
+	short := make([]complex128, 16)
+	ops := map[string]func(){
+		"Norm":            func() { calc.Norm(short) },
+		"PositionSquared": func() { calc.PositionSquared(short) },
+		"Momentum":        func() { calc.Momentum(short) },
+		"MomentumSquared": func() { calc.MomentumSquared(short) },
+		"Measure":         func() { calc.Measure(short) },
+		"MomentumDensity": func() { calc.MomentumDensity(short) },
+	}
+	for name, op := range ops {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "doesn't match grid size") {
+					t.Errorf("%s: expected a length mismatch panic, got %v", name, r)
+				}
+			}()
+			op()
+		}()
+	}
+}
+
+func TestCalculator_EigenStates(t *testing.T) {
+	grid, err := gridData.NewFromLength(16., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pot := gridData.Harmonic[float64]{ForceConst: 1.}
+	states, err := Quantum.NewHamil(grid, 1., pot).Diagonalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calc, err := NewCalculator(grid, 1., pot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer calc.Close()
+
+	// virial theorem <T> = <V> = E/2 and dx dp = n + 1/2 for the oscillator eigenstates
+	for n := 0; n < 3; n++ {
+		e := calc.Measure(Complex(states.Wavefunction(n)))
+		level := float64(n) + 0.5
+		if math.Abs(e.Kinetic-level/2) > 1e-6 || math.Abs(e.Potential-level/2) > 1e-6 {
+			t.Errorf("n = %d: expected <T> = <V> = %v, got %v and %v", n, level/2, e.Kinetic, e.Potential)
+		}
+		if math.Abs(e.Uncertainty()-level) > 1e-6 {
+			t.Errorf("n = %d: expected dx dp = %v, got %v", n, level, e.Uncertainty())
+		}
+		if math.Abs(e.P) > 1e-10 || math.Abs(e.Norm-1) > 1e-10 {
+			t.Errorf("n = %d: expected <p> = 0 and unit norm, got %v and %v", n, e.P, e.Norm)
+		}
+	}
+}
+
+func TestAutocorrelation_Propagation(t *testing.T) {
+	grid, err := gridData.NewFromLength(20., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tgrid, err := gridData.NewTimeGrid(0.5, 12, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pot := gridData.Harmonic[float64]{ForceConst: 1.}
+	so := Quantum.NewSplitOperator(grid, tgrid, 1., pot)
+	defer so.Close()
+
+	// coherent state displaced by x0: <x>(t) = x0 cos t, <p>(t) = -x0 sin t and
+	// |C(t)| = exp(-x0^2 (1 - cos t) / 2)
+	const x0 = 1.
+	psi := gaussianPacket(grid.RValues(), x0, 0, math.Sqrt(0.5))
+	calc := NewCalculatorFromBasis(so.Kinetic(), pot)
+	defer calc.Close()
+	ac, err := NewAutocorrelation(grid, psi)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = so.Propagate(psi, func(r Quantum.PropagationReport, psi []complex128) {
+		ac.Record(r.Time, psi)
+		e := calc.Measure(psi)
+		if math.Abs(e.X-x0*math.Cos(r.Time)) > 1e-4 || math.Abs(e.P+x0*math.Sin(r.Time)) > 1e-4 {
+			t.Errorf("t = %g: expected <x> = %v and <p> = %v, got %v and %v",
+				r.Time, x0*math.Cos(r.Time), -x0*math.Sin(r.Time), e.X, e.P)
+		}
+		if math.Abs(e.Energy()-1) > 1e-3 {
+			t.Errorf("t = %g: expected energy 1, got %v", r.Time, e.Energy())
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ac.Values) != 13 {
+		t.Fatalf("expected 13 autocorrelation values, got %d", len(ac.Values))
+	}
+	for i, c := range ac.Values {
+		expected := math.Exp(-x0 * x0 * (1 - math.Cos(ac.Times[i])) / 2)
+		if math.Abs(cmplx.Abs(c)-expected) > 1e-4 {
+			t.Errorf("expected |C(%g)| = %v, got %v", ac.Times[i], expected, cmplx.Abs(c))
+		}
+	}
+
+	// the shared basis stays usable after the calculator is closed
+	calc.Close()
+	if err := so.Step(psi, 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -7,125 +7,234 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
-type FiniteDiff struct {
-	grid         *gridData.RadGrid
-	kineticCoeff float64
-	mass         float64
-	order        int
-}
-
-func NewFiniteDiff(grid *gridData.RadGrid, mass float64, order int) (*FiniteDiff, error) {
-	if order != 3 && order != 5 && order != 6 {
-		return nil, fmt.Errorf("order must be 3, 5, or 6, got %d", order)
-	}
-
-	dxSq := grid.DeltaR() * grid.DeltaR()
-	kineticCoeff := -1 / (2 * mass * dxSq)
+// Boundary selects how the finite-difference stencil treats the grid edges
+type Boundary int
+
+const (
+	// Dirichlet drops the stencil points beyond the edges, psi = 0 outside the grid
+	Dirichlet Boundary = iota
+	// Periodic wraps the stencil around, psi(x + L) = psi(x)
+	Periodic
+)
 
-	fd := &FiniteDiff{
-		grid:         grid,
-		kineticCoeff: kineticCoeff,
-		order:        order,
+func (b Boundary) String() string {
+	switch b {
+	case Dirichlet:
+		return "dirichlet"
+	case Periodic:
+		return "periodic"
 	}
-	return fd, nil
+	return fmt.Sprintf("Boundary(%d)", int(b))
 }
 
-func (fd *FiniteDiff) computeMatrix(keMat mat.Matrix) {
-	switch fd.order {
-	case 3:
-		fd.compute3rdOrderCoefficients(keMat)
-	case 5:
-		fd.compute5thOrderCoefficients(keMat)
-	case 7:
-		fd.compute7thOrderCoefficients(keMat)
-	case 9:
-		fd.compute9thOrderCoefficients(keMat)
-	}
+// FiniteDiff is the kinetic energy -1/2m d^2/dx^2 from a central finite-difference
+// stencil of even order of accuracy. The matrix is a symmetric band (circulant when
+// periodic), stored as its first row band[k] = K_{i,i+k}; the dense matrix and the
+// eigendecomposition are built on demand and cached.
+type FiniteDiff struct {
+	grid     *gridData.RadGrid
+	mass     float64
+	order    int
+	boundary Boundary
+	ndims    int
+	band     []float64
+
+	kMat       *mat.Dense
+	kMatCached bool
+	eigVals    []float64
+	eigVecs    *mat.Dense
+	eigCached  bool
 }
 
-func (fd *FiniteDiff) compute3rdOrderCoefficients(keMat mat.Matrix) {
-	ndims := int(fd.grid.NPoints())
-	coeff := 2.0 * fd.kineticCoeff
-
-	for i := 0; i < ndims; i++ {
-		keMat.(*mat.Dense).Set(i, i, -coeff)
+// NewFiniteDiff creates the kinetic energy with a central stencil of the given even
+// order, using order+1 points
+func NewFiniteDiff(grid *gridData.RadGrid, mass float64, order int, boundary Boundary) (*FiniteDiff, error) {
+	if order < 2 || order%2 != 0 {
+		return nil, fmt.Errorf("order must be even and at least 2, got %d", order)
+	}
+	if boundary != Dirichlet && boundary != Periodic {
+		return nil, fmt.Errorf("unknown boundary condition %v", boundary)
+	}
+	ndims := int(grid.NPoints())
+	if ndims <= order {
+		return nil, fmt.Errorf("order %d stencil needs more than %d grid points, got %d", order, order, ndims)
 	}
 
-	for i := 0; i < ndims-1; i++ {
-		keMat.(*mat.Dense).Set(i+1, i, fd.kineticCoeff)
-		keMat.(*mat.Dense).Set(i, i+1, fd.kineticCoeff)
+	weights := centralSecondDerivative(order / 2)
+	kineticCoeff := -1 / (2 * mass * grid.DeltaR() * grid.DeltaR())
+	band := make([]float64, len(weights))
+	for k, w := range weights {
+		band[k] = kineticCoeff * w
 	}
+
+	return &FiniteDiff{
+		grid:     grid,
+		mass:     mass,
+		order:    order,
+		boundary: boundary,
+		ndims:    ndims,
+		band:     band,
+	}, nil
 }
 
-func (fd *FiniteDiff) compute5thOrderCoefficients(keMat mat.Matrix) {
-	ndims := int(fd.grid.NPoints())
-	k := fd.kineticCoeff
+func (fd *FiniteDiff) Order() int         { return fd.order }
+func (fd *FiniteDiff) Boundary() Boundary { return fd.boundary }
 
-	for i := 0; i < ndims; i++ {
-		keMat.(*mat.Dense).Set(i, i, 2.5*k)
-	}
+// Band returns the first row of the band, K_{i,i+k} for k = 0, ..., order/2
+func (fd *FiniteDiff) Band() []float64 { return append([]float64(nil), fd.band...) }
 
-	for i := 0; i < ndims-1; i++ {
-		keMat.(*mat.Dense).Set(i, i+1, -4.0/3.0*k)
-		keMat.(*mat.Dense).Set(i+1, i, -4.0/3.0*k)
+// centralSecondDerivative returns the weights [w0, w1, ..., wp] of the (2p+1)-point formula
+// f”(x) ~ (w0 f(x) + Sum_k wk (f(x+k dx) + f(x-k dx)))/dx^2
+func centralSecondDerivative(p int) []float64 {
+	offsets := make([]float64, 2*p+1)
+	for i := range offsets {
+		offsets[i] = float64(i - p)
 	}
-
-	for i := 0; i < ndims-2; i++ {
-		keMat.(*mat.Dense).Set(i, i+2, 1.0/12.0*k)
-		keMat.(*mat.Dense).Set(i+2, i, 1.0/12.0*k)
+	c := fornbergWeights(0, offsets, 2)
+	weights := make([]float64, p+1)
+	for k := range weights {
+		weights[k] = c[p+k][2]
 	}
+	return weights
 }
 
-func (fd *FiniteDiff) compute7thOrderCoefficients(keMat mat.Matrix) {
-	ndims := int(fd.grid.NPoints())
-	k := fd.kineticCoeff
-
-	for i := 0; i < ndims; i++ {
-		keMat.(*mat.Dense).Set(i, i, 49.0/18.0*k)
-	}
+// fornbergWeights returns the finite-difference weights c[j][m] of f(x[j]) in the m-th
+// derivative at z, for m = 0, ..., order, with Fornberg's recursion (Math. Comp. 51, 699 (1988))
+func fornbergWeights(z float64, x []float64, order int) [][]float64 {
+	n := len(x)
+	c := make([][]float64, n)
+	for i := range c {
+		c[i] = make([]float64, order+1)
+	}
+
+	c1 := 1.
+	c4 := x[0] - z
+	c[0][0] = 1
+	for i := 1; i < n; i++ {
+		mn := min(i, order)
+		c2 := 1.
+		c5 := c4
+		c4 = x[i] - z
+		for j := 0; j < i; j++ {
+			c3 := x[i] - x[j]
+			c2 *= c3
+			if j == i-1 {
+				for m := mn; m >= 1; m-- {
+					c[i][m] = c1 * (float64(m)*c[i-1][m-1] - c5*c[i-1][m]) / c2
+				}
+				c[i][0] = -c1 * c5 * c[i-1][0] / c2
+			}
+			for m := mn; m >= 1; m-- {
+				c[j][m] = (c4*c[j][m] - float64(m)*c[j][m-1]) / c3
+			}
+			c[j][0] = c4 * c[j][0] / c3
+		}
+		c1 = c2
+	}
+	return c
+}
 
-	for i := 0; i < ndims-1; i++ {
-		keMat.(*mat.Dense).Set(i, i+1, -1.5*k)
-		keMat.(*mat.Dense).Set(i+1, i, -1.5*k)
+// MulVec computes out = K in directly from the band; out must not alias in
+func (fd *FiniteDiff) MulVec(in, out []float64) {
+	n := fd.ndims
+	for i := 0; i < n; i++ {
+		sum := fd.band[0] * in[i]
+		for k := 1; k < len(fd.band); k++ {
+			if fd.boundary == Periodic {
+				sum += fd.band[k] * (in[(i-k+n)%n] + in[(i+k)%n])
+				continue
+			}
+			if i-k >= 0 {
+				sum += fd.band[k] * in[i-k]
+			}
+			if i+k < n {
+				sum += fd.band[k] * in[i+k]
+			}
+		}
+		out[i] = sum
 	}
+}
 
-	for i := 0; i < ndims-2; i++ {
-		keMat.(*mat.Dense).Set(i, i+2, 3.0/20.0*k)
-		keMat.(*mat.Dense).Set(i+2, i, 3.0/20.0*k)
-	}
+// GetMat returns the dense kinetic energy matrix, using cache if available
+func (fd *FiniteDiff) GetMat() *mat.Dense {
+	if !fd.kMatCached {
+		n := fd.ndims
+		fd.kMat = mat.NewDense(n, n, nil)
+		for i := 0; i < n; i++ {
+			fd.kMat.Set(i, i, fd.band[0])
+			for k := 1; k < len(fd.band); k++ {
+				j := i + k
+				if j >= n {
+					if fd.boundary != Periodic {
+						break
+					}
+					j -= n
+				}
+				fd.kMat.Set(i, j, fd.band[k])
+				fd.kMat.Set(j, i, fd.band[k])
+			}
+		}
+		fd.kMatCached = true
+	}
+	return fd.kMat
+}
 
-	for i := 0; i < ndims-3; i++ {
-		keMat.(*mat.Dense).Set(i, i+3, -1.0/90.0*k)
-		keMat.(*mat.Dense).Set(i+3, i, -1.0/90.0*k)
-	}
+// RealDiagonalize diagonalizes the real kinetic energy matrix
+func (fd *FiniteDiff) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	eigenvalues = make([]float64, fd.ndims)
+	eigenvectors = mat.DenseCopyOf(fd.GetMat())
+	err = RealDiagonalizeLapack(eigenvectors, eigenvalues)
+	return eigenvalues, eigenvectors, err
 }
 
-// 9-point (8th-order)
-func (fd *FiniteDiff) compute9thOrderCoefficients(keMat mat.Matrix) {
-	ndims := int(fd.grid.NPoints())
-	k := fd.kineticCoeff
+// eigenDecomposition returns the eigenvalues and eigenvectors of K, using cache if available
+func (fd *FiniteDiff) eigenDecomposition() ([]float64, *mat.Dense, error) {
+	if !fd.eigCached {
+		eigVals, eigVecs, err := fd.RealDiagonalize()
+		if err != nil {
+			return nil, nil, err
+		}
+		fd.eigVals = eigVals
+		fd.eigVecs = eigVecs
+		fd.eigCached = true
+	}
+	return fd.eigVals, fd.eigVecs, nil
+}
 
-	for i := 0; i < ndims; i++ {
-		keMat.(*mat.Dense).Set(i, i, 205.0/72.0*k)
+// ExpDtTo computes exp(dt * K) In = U exp(dt E) U^T In and stores the result in Out, which may alias In
+func (fd *FiniteDiff) ExpDtTo(dt float64, in []float64, out []float64) error {
+	if len(in) != fd.ndims || len(out) != fd.ndims {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), fd.ndims)
 	}
 
-	for i := 0; i < ndims-1; i++ {
-		keMat.(*mat.Dense).Set(i, i+1, -8.0/5.0*k)
-		keMat.(*mat.Dense).Set(i+1, i, -8.0/5.0*k)
+	eigVals, eigVecs, err := fd.eigenDecomposition()
+	if err != nil {
+		return err
 	}
+	expDtEigen(eigVals, eigVecs, dt, in, out)
+	return nil
+}
 
-	for i := 0; i < ndims-2; i++ {
-		keMat.(*mat.Dense).Set(i, i+2, 1.0/5.0*k)
-		keMat.(*mat.Dense).Set(i+2, i, 1.0/5.0*k)
-	}
+// ExpDtInPlace computes exp(dt * K) and applies it to input vector in-place
+func (fd *FiniteDiff) ExpDtInPlace(dt float64, inOut []float64) error {
+	return fd.ExpDtTo(dt, inOut, inOut)
+}
 
-	for i := 0; i < ndims-3; i++ {
-		keMat.(*mat.Dense).Set(i, i+3, -8.0/315.0*k)
-		keMat.(*mat.Dense).Set(i+3, i, -8.0/315.0*k)
+// ExpIdtTo computes exp(-i dt K) In and stores the result in Out, which may alias In
+func (fd *FiniteDiff) ExpIdtTo(dt float64, in []complex128, out []complex128) error {
+	if len(in) != fd.ndims || len(out) != fd.ndims {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), fd.ndims)
 	}
 
-	for i := 0; i < ndims-4; i++ {
-		keMat.(*mat.Dense).Set(i, i+4, 1.0/560.0*k)
-		keMat.(*mat.Dense).Set(i+4, i, 1.0/560.0*k)
+	eigVals, eigVecs, err := fd.eigenDecomposition()
+	if err != nil {
+		return err
 	}
+	expIdtEigen(eigVals, eigVecs, dt, in, out)
+	return nil
+}
+
+// ExpIdtInPlace computes exp(-i dt K) and applies it to complex input vector in-place
+func (fd *FiniteDiff) ExpIdtInPlace(dt float64, inOut []complex128) error {
+	return fd.ExpIdtTo(dt, inOut, inOut)
 }
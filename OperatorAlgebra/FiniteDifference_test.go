@@ -0,0 +1,153 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCentralSecondDerivative(t *testing.T) {
+	// the tabulated central stencils of orders 2, 4, 6 and 8
+	expected := map[int][]float64{
+		2: {-2., 1.},
+		4: {-5. / 2., 4. / 3., -1. / 12.},
+		6: {-49. / 18., 3. / 2., -3. / 20., 1. / 90.},
+		8: {-205. / 72., 8. / 5., -1. / 5., 8. / 315., -1. / 560.},
+	}
+	for order, weights := range expected {
+		got := centralSecondDerivative(order / 2)
+		for k := range weights {
+			if math.Abs(got[k]-weights[k]) > 1e-12 {
+				t.Errorf("order %d: expected w%d = %v, got %v", order, k, weights[k], got[k])
+			}
+		}
+	}
+}
+
+func TestFiniteDiff_Orders(t *testing.T) {
+	rgrid, err := gridData.NewFromLength(10., 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, order := range []int{2, 6, 12} {
+		if _, err := NewFiniteDiff(rgrid, 1., order, Dirichlet); err != nil {
+			t.Errorf("order %d: unexpected error: %v", order, err)
+		}
+	}
+	for _, order := range []int{0, 3, 5, 30} {
+		if _, err := NewFiniteDiff(rgrid, 1., order, Dirichlet); err == nil {
+			t.Errorf("expected an error for order %d", order)
+		}
+	}
+}
+
+func TestFiniteDiff_Harmonic(t *testing.T) {
+	rgrid, err := gridData.NewFromLength(16., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := rgrid.RValues()
+
+	previous := math.Inf(1)
+	for _, order := range []int{2, 4, 6, 8} {
+		fd, err := NewFiniteDiff(rgrid, 1., order, Dirichlet)
+		if err != nil {
+			t.Fatalf("order %d: unexpected error: %v", order, err)
+		}
+		var kinE KineticOp = fd
+		hamil := mat.DenseCopyOf(kinE.GetMat())
+		for i, xi := range x {
+			hamil.Set(i, i, hamil.At(i, i)+0.5*xi*xi)
+		}
+		evals := make([]float64, len(x))
+		if err := RealDiagonalizeLapack(hamil, evals); err != nil {
+			t.Fatalf("order %d: unexpected error: %v", order, err)
+		}
+
+		worst := 0.
+		for n := 0; n < 4; n++ {
+			worst = math.Max(worst, math.Abs(evals[n]-(float64(n)+0.5)))
+		}
+		if worst >= previous || (order == 8 && worst > 1e-5) {
+			t.Errorf("order %d: expected a smaller error than %v, got %v", order, previous, worst)
+		}
+		previous = worst
+	}
+
+	for _, order := range []int{0, 3, 128} {
+		if _, err := NewFiniteDiff(rgrid, 1., order, Dirichlet); err == nil {
+			t.Errorf("expected an error for order %d", order)
+		}
+	}
+}
+
+func TestFiniteDiff_Periodic(t *testing.T) {
+	rgrid, err := gridData.NewFromLength(10., 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fd, err := NewFiniteDiff(rgrid, 2., 6, Periodic)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a plane wave fitting the box is an eigenvector of the circulant matrix
+	k := 2 * math.Pi * 3 / 10.
+	in, out := make([]float64, 40), make([]float64, 40)
+	for i, xi := range rgrid.RValues() {
+		in[i] = math.Cos(k * xi)
+	}
+	fd.MulVec(in, out)
+
+	dx := rgrid.DeltaR()
+	eigenvalue := 0.
+	for m, w := range fd.Band() {
+		if m == 0 {
+			eigenvalue += w
+			continue
+		}
+		eigenvalue += 2 * w * math.Cos(float64(m)*k*dx)
+	}
+	if math.Abs(eigenvalue-k*k/4) > 1e-3 {
+		t.Errorf("expected eigenvalue close to k^2/2m = %v, got %v", k*k/4, eigenvalue)
+	}
+	for i := range in {
+		if math.Abs(out[i]-eigenvalue*in[i]) > 1e-10 {
+			t.Fatalf("expected %v at point %d, got %v", eigenvalue*in[i], i, out[i])
+		}
+	}
+
+	// MulVec, the dense matrix and the propagator describe the same operator
+	dense := mat.NewVecDense(40, nil)
+	dense.MulVec(fd.GetMat(), mat.NewVecDense(40, in))
+	for i := range out {
+		if math.Abs(dense.AtVec(i)-out[i]) > 1e-10 {
+			t.Errorf("expected dense product %v at point %d, got %v", out[i], i, dense.AtVec(i))
+		}
+	}
+
+	psi := make([]complex128, 40)
+	for i, v := range in {
+		psi[i] = complex(v, 0)
+	}
+	if err := fd.ExpIdtInPlace(0.7, psi); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	phase := cmplx.Exp(complex(0, -0.7*eigenvalue))
+	for i, v := range in {
+		if cmplx.Abs(psi[i]-phase*complex(v, 0)) > 1e-10 {
+			t.Fatalf("expected %v at point %d, got %v", phase*complex(v, 0), i, psi[i])
+		}
+	}
+
+	decayed := make([]float64, 40)
+	if err := fd.ExpDtTo(-0.7, in, decayed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(decayed[3]-math.Exp(-0.7*eigenvalue)*in[3]) > 1e-10 {
+		t.Errorf("expected exp(-dt E) decay %v, got %v", math.Exp(-0.7*eigenvalue)*in[3], decayed[3])
+	}
+}
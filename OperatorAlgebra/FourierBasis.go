@@ -2,41 +2,68 @@ package OperatorAlgebra
 
 import (
 	"GoProject/gridData"
-	"fmt"
-	"math"
-	"math/rand/v2"
 	"runtime"
+	"sync"
 
-	"github.com/jvlmdr/go-fftw/fftw"
+	"gonum.org/v1/gonum/mat"
 )
 
-// FourierBasis represents the kinetic energy operator in Fourier/DVR basis
+// FourierBasis represents the kinetic energy operator in Fourier/DVR basis. The operators
+// may be called from several goroutines at once; each call draws its own FFT plan and
+// workspace from a pool and does one forward and one backward transform without allocating.
 type FourierBasis struct {
 	grid     *gridData.RadGrid
 	mass     float64
 	nPoints  int
-	fftPlan  fftw.Plan
-	ifftPlan fftw.Plan
-	Buff     *fftw.Array
+	backend  FFTBackend
+	plans    *planPool
 	kValues  []float64
 	keValues []float64
+
+	matMu      sync.Mutex
+	kMat       *mat.Dense
+	kMatCached bool
 }
 
-// FFTInit creates a new Fourier struct for fast fourier transform
+// FFTInit creates a new Fourier struct for fast fourier transform with the DefaultFFTBackend
 func FFTInit(grid *gridData.RadGrid, mass float64) *FourierBasis {
-	fb := &FourierBasis{}
-	fb.initialize(grid, mass)
-	runtime.SetFinalizer(fb, (*FourierBasis).destroy)
+	fb, err := FFTInitWith(grid, mass, DefaultFFTBackend)
+	if err != nil {
+		panic(err)
+	}
 	return fb
 }
 
-func (f *FourierBasis) Redefine(grid *gridData.RadGrid, mass float64) {
-	(*fftw.Plan).Destroy(&f.fftPlan)
-	(*fftw.Plan).Destroy(&f.ifftPlan)
-	f.initialize(grid, mass)
+// FFTInitWith creates the Fourier basis with the transforms done by the given backend
+func FFTInitWith(grid *gridData.RadGrid, mass float64, backend FFTBackend) (*FourierBasis, error) {
+	fb := &FourierBasis{backend: backend}
+	if err := fb.initialize(grid, mass); err != nil {
+		return nil, err
+	}
+	runtime.SetFinalizer(fb, (*FourierBasis).destroy)
+	return fb, nil
 }
 
-func (f *FourierBasis) initialize(grid *gridData.RadGrid, mass float64) {
+// Redefine moves the basis to a new grid and mass; it must not run concurrently with the operators
+func (f *FourierBasis) Redefine(grid *gridData.RadGrid, mass float64) error {
+	f.plans.close()
+	return f.initialize(grid, mass)
+}
+
+func (f *FourierBasis) Grid() *gridData.RadGrid { return f.grid }
+func (f *FourierBasis) Mass() float64           { return f.mass }
+func (f *FourierBasis) Backend() FFTBackend     { return f.backend }
+
+// KValues returns the momenta of the transform coefficients, in FFT order
+func (f *FourierBasis) KValues() []float64 { return append([]float64(nil), f.kValues...) }
+
+// ForwardTransform computes the unnormalized discrete Fourier transform
+// Out_j = Sum_n In_n exp(-i k_j (x_n - x_0)), with k_j in the order of KValues
+func (f *FourierBasis) ForwardTransform(In []complex128, Out []complex128) {
+	f.plans.transform(In, Out)
+}
+
+func (f *FourierBasis) initialize(grid *gridData.RadGrid, mass float64) error {
 	gridPoints := int(grid.NPoints())
 	kVal := grid.KValues()
 
@@ -50,110 +77,105 @@ func (f *FourierBasis) initialize(grid *gridData.RadGrid, mass float64) {
 		keValues[i] = k * k * invTwoMass
 	}
 
-	buff := fftw.NewArray(gridPoints)
-
-	for i := 0; i < gridPoints; i++ {
-		Re := rand.NormFloat64()
-		Im := rand.NormFloat64()
-		invMag := 1.0 / math.Sqrt(Re*Re+Im*Im)
-		buff.Set(i, complex(Re*invMag, Im*invMag))
+	plans, err := newPlanPool(f.backend, []int{gridPoints})
+	if err != nil {
+		return err
 	}
 
-	fftPlan := *fftw.NewPlan(buff, buff, fftw.Forward, fftw.Estimate)
-	ifftPlan := *fftw.NewPlan(buff, buff, fftw.Backward, fftw.Estimate)
-
 	f.grid = grid
 	f.mass = mass
 	f.nPoints = gridPoints
-	f.fftPlan = fftPlan
-	f.ifftPlan = ifftPlan
-	f.Buff = buff
+	f.plans = plans
 	f.kValues = kValues
 	f.keValues = keValues
+	f.kMat = nil
+	f.kMatCached = false
+	return nil
 }
 
-func (f *FourierBasis) forwardBuff(in []complex128) {
-	copy(f.Buff.Elems, in)
-	f.fftPlan.Execute()
-}
-
-func (f *FourierBasis) forward(in []complex128, out []complex128) {
-	copy(f.Buff.Elems, in)
-	f.fftPlan.Execute()
-	copy(out, f.Buff.Elems)
+func (f *FourierBasis) MomentumOpInPlace(InOut []complex128) {
+	f.plans.diagonalOp(InOut, InOut, f.kValues)
 }
 
-func (f *FourierBasis) forwardInPlace(InOut []complex128) {
-	copy(f.Buff.Elems, InOut)
-	f.fftPlan.Execute()
-	copy(InOut, f.Buff.Elems)
+func (f *FourierBasis) MomentumOp(In []complex128, Out []complex128) {
+	f.plans.diagonalOp(In, Out, f.kValues)
 }
 
-func (f *FourierBasis) backwardBuff(in []complex128) {
-	copy(f.Buff.Elems, in)
-	f.ifftPlan.Execute()
+func (f *FourierBasis) LaplacianOpInPlace(InOut []complex128) {
+	f.plans.diagonalOp(InOut, InOut, f.keValues)
 }
 
-func (f *FourierBasis) backward(in []complex128, out []complex128) {
-	copy(f.Buff.Elems, in)
-	f.ifftPlan.Execute()
-	copy(out, f.Buff.Elems)
+func (f *FourierBasis) LaplacianOp(In []complex128, Out []complex128) {
+	f.plans.diagonalOp(In, Out, f.keValues)
+}
+
+// MulVec computes out = K in for a real vector with one forward and one backward FFT
+func (f *FourierBasis) MulVec(in, out []float64) {
+	f.plans.diagonalOpReal(in, out, f.keValues)
+}
+
+// GetMat returns the dense kinetic energy matrix of the Fourier grid, using cache if available
+func (f *FourierBasis) GetMat() *mat.Dense {
+	f.matMu.Lock()
+	defer f.matMu.Unlock()
+	if !f.kMatCached {
+		f.kMat = mat.NewDense(f.nPoints, f.nPoints, nil)
+		col := make([]complex128, f.nPoints)
+		for j := 0; j < f.nPoints; j++ {
+			clear(col)
+			col[j] = 1
+			f.LaplacianOpInPlace(col)
+			for i := range col {
+				f.kMat.Set(i, j, real(col[i]))
+			}
+		}
+		f.kMatCached = true
+	}
+	return f.kMat
 }
 
-func (f *FourierBasis) backwardInPlace(InOut []complex128) {
-	copy(f.Buff.Elems, InOut)
-	f.ifftPlan.Execute()
-	copy(InOut, f.Buff.Elems)
+// RealDiagonalize diagonalizes the dense Fourier-grid kinetic energy matrix
+func (f *FourierBasis) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	eigenvalues = make([]float64, f.nPoints)
+	eigenvectors = mat.DenseCopyOf(f.GetMat())
+	err = RealDiagonalizeLapack(eigenvectors, eigenvalues)
+	return eigenvalues, eigenvectors, err
 }
 
-func (f *FourierBasis) operatorOp(InOut []complex128, Op []float64) {
-	if len(f.Buff.Elems) != len(Op) {
-		panic(fmt.Sprintf("length mismatch: Buff.Elems=%d, kValues=%d",
-			len(f.Buff.Elems), len(Op)))
+// ExpDtTo computes exp(dt * K) In and stores the result in Out
+func (f *FourierBasis) ExpDtTo(Dt float64, In []float64, Out []float64) error {
+	if err := checkBasisLen(f.nPoints, len(In), len(Out)); err != nil {
+		return err
 	}
-
-	copy(f.Buff.Elems, InOut)
-	f.fftPlan.Execute()
-
-	for i := range f.Buff.Elems {
-		f.Buff.Elems[i] *= complex(Op[i], 0)
-	}
-
-	f.ifftPlan.Execute()
-	copy(InOut, f.Buff.Elems)
+	f.plans.expOpReal(In, Out, f.keValues, Dt)
+	return nil
 }
 
-func (f *FourierBasis) MomentumOpInPlace(InOut []complex128) {
-	f.operatorOp(InOut, f.kValues)
-}
-
-func (f *FourierBasis) MomentumOp(In []complex128, Out []complex128) {
-	copy(Out, In)
-	f.MomentumOpInPlace(Out)
-	copy(Out, f.Buff.Elems)
+// ExpDtInPlace computes exp(dt * K) and applies it to the input vector in-place
+func (f *FourierBasis) ExpDtInPlace(Dt float64, InOut []float64) error {
+	return f.ExpDtTo(Dt, InOut, InOut)
 }
 
-func (f *FourierBasis) LaplacianOpInPlace(InOut []complex128) {
-	f.operatorOp(InOut, f.keValues)
+// ExpIdtTo computes exp(-i dt K) In and stores the result in Out
+func (f *FourierBasis) ExpIdtTo(Dt float64, In []complex128, Out []complex128) error {
+	if err := checkBasisLen(f.nPoints, len(In), len(Out)); err != nil {
+		return err
+	}
+	f.plans.expOp(In, Out, f.keValues, complex(0, -Dt))
+	return nil
 }
 
-func (f *FourierBasis) LaplacianOp(In []complex128, Out []complex128) {
-	copy(Out, In)
-	f.LaplacianOpInPlace(Out)
-	copy(Out, f.Buff.Elems)
+// ExpIdtInPlace computes exp(-i dt K) and applies it to the input vector in-place
+func (f *FourierBasis) ExpIdtInPlace(Dt float64, InOut []complex128) error {
+	return f.ExpIdtTo(Dt, InOut, InOut)
 }
 
 func (f *FourierBasis) destroy() {
-	if f != nil {
-		(*fftw.Plan).Destroy(&f.fftPlan)
-		(*fftw.Plan).Destroy(&f.ifftPlan)
-	}
-	fmt.Println("Destroyed")
+	f.plans.close()
 }
 
-// Clean cleans up FFTW
-func (f *FourierBasis) Clean() {
+// Close releases the FFT plans; the operators panic if used afterwards
+func (f *FourierBasis) Close() {
 	runtime.SetFinalizer(f, nil)
 	f.destroy()
-	fmt.Println("Cleaned")
 }
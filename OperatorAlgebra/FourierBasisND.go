@@ -0,0 +1,165 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FourierBasisND is the Fourier-grid kinetic energy on a 2D/3D product grid, applied
+// with multi-dimensional FFTs; T = Sum_i k_i^2 / 2 m_i in k-space. Like FourierBasis it
+// is safe for concurrent use and its operators do not allocate.
+type FourierBasisND struct {
+	grid     *gridData.ProductGrid
+	masses   []float64
+	nPoints  int
+	backend  FFTBackend
+	plans    *planPool
+	kValues  [][]float64
+	keValues []float64
+
+	matMu      sync.Mutex
+	kMat       *mat.Dense
+	kMatCached bool
+}
+
+// FFTInitND creates the multi-dimensional Fourier basis with one mass per axis and the DefaultFFTBackend
+func FFTInitND(grid *gridData.ProductGrid, masses ...float64) (*FourierBasisND, error) {
+	return FFTInitNDWith(grid, DefaultFFTBackend, masses...)
+}
+
+// FFTInitNDWith creates the multi-dimensional Fourier basis with the transforms done by the given backend
+func FFTInitNDWith(grid *gridData.ProductGrid, backend FFTBackend, masses ...float64) (*FourierBasisND, error) {
+	if len(masses) != grid.NDims() {
+		return nil, fmt.Errorf("got %d masses for a %d-dimensional grid", len(masses), grid.NDims())
+	}
+
+	// kValues[axis] holds k_axis at every flattened point, so each operator is one diagonal
+	nPoints := grid.NPoints()
+	kValues := make([][]float64, grid.NDims())
+	axisK := make([][]float64, grid.NDims())
+	for axis := range kValues {
+		kValues[axis] = make([]float64, nPoints)
+		axisK[axis] = grid.KValues(axis)
+	}
+
+	keValues := make([]float64, nPoints)
+	idx := make([]int, grid.NDims())
+	for flat := range keValues {
+		grid.MultiIndex(flat, idx)
+		for axis, j := range idx {
+			k := axisK[axis][j]
+			kValues[axis][flat] = k
+			keValues[flat] += k * k / (2 * masses[axis])
+		}
+	}
+
+	plans, err := newPlanPool(backend, grid.Shape())
+	if err != nil {
+		return nil, err
+	}
+	f := &FourierBasisND{
+		grid:     grid,
+		masses:   append([]float64(nil), masses...),
+		nPoints:  nPoints,
+		backend:  backend,
+		plans:    plans,
+		kValues:  kValues,
+		keValues: keValues,
+	}
+	runtime.SetFinalizer(f, (*FourierBasisND).destroy)
+	return f, nil
+}
+
+func (f *FourierBasisND) Grid() *gridData.ProductGrid { return f.grid }
+func (f *FourierBasisND) Backend() FFTBackend         { return f.backend }
+
+// MomentumOpInPlace applies the momentum k along one axis
+func (f *FourierBasisND) MomentumOpInPlace(axis int, InOut []complex128) {
+	f.plans.diagonalOp(InOut, InOut, f.kValues[axis])
+}
+
+func (f *FourierBasisND) MomentumOp(axis int, In []complex128, Out []complex128) {
+	f.plans.diagonalOp(In, Out, f.kValues[axis])
+}
+
+// LaplacianOpInPlace applies the full kinetic energy Sum_i k_i^2 / 2 m_i
+func (f *FourierBasisND) LaplacianOpInPlace(InOut []complex128) {
+	f.plans.diagonalOp(InOut, InOut, f.keValues)
+}
+
+func (f *FourierBasisND) LaplacianOp(In []complex128, Out []complex128) {
+	f.plans.diagonalOp(In, Out, f.keValues)
+}
+
+// MulVec computes out = T in for a real vector
+func (f *FourierBasisND) MulVec(in, out []float64) {
+	f.plans.diagonalOpReal(in, out, f.keValues)
+}
+
+// GetMat returns the dense kinetic energy matrix, using cache if available; only sensible for small grids
+func (f *FourierBasisND) GetMat() *mat.Dense {
+	f.matMu.Lock()
+	defer f.matMu.Unlock()
+	if !f.kMatCached {
+		f.kMat = mat.NewDense(f.nPoints, f.nPoints, nil)
+		col := make([]complex128, f.nPoints)
+		for j := 0; j < f.nPoints; j++ {
+			clear(col)
+			col[j] = 1
+			f.LaplacianOpInPlace(col)
+			for i := range col {
+				f.kMat.Set(i, j, real(col[i]))
+			}
+		}
+		f.kMatCached = true
+	}
+	return f.kMat
+}
+
+// RealDiagonalize diagonalizes the dense kinetic energy matrix
+func (f *FourierBasisND) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	eigenvalues = make([]float64, f.nPoints)
+	eigenvectors = mat.DenseCopyOf(f.GetMat())
+	err = RealDiagonalizeLapack(eigenvectors, eigenvalues)
+	return eigenvalues, eigenvectors, err
+}
+
+// ExpDtTo computes exp(dt T) In and stores the result in Out
+func (f *FourierBasisND) ExpDtTo(Dt float64, In []float64, Out []float64) error {
+	if err := checkBasisLen(f.nPoints, len(In), len(Out)); err != nil {
+		return err
+	}
+	f.plans.expOpReal(In, Out, f.keValues, Dt)
+	return nil
+}
+
+func (f *FourierBasisND) ExpDtInPlace(Dt float64, InOut []float64) error {
+	return f.ExpDtTo(Dt, InOut, InOut)
+}
+
+// ExpIdtTo computes exp(-i dt T) In and stores the result in Out
+func (f *FourierBasisND) ExpIdtTo(Dt float64, In []complex128, Out []complex128) error {
+	if err := checkBasisLen(f.nPoints, len(In), len(Out)); err != nil {
+		return err
+	}
+	f.plans.expOp(In, Out, f.keValues, complex(0, -Dt))
+	return nil
+}
+
+func (f *FourierBasisND) ExpIdtInPlace(Dt float64, InOut []complex128) error {
+	return f.ExpIdtTo(Dt, InOut, InOut)
+}
+
+func (f *FourierBasisND) destroy() {
+	f.plans.close()
+}
+
+// Close releases the FFT plans; the operators panic if used afterwards
+func (f *FourierBasisND) Close() {
+	runtime.SetFinalizer(f, nil)
+	f.destroy()
+}
@@ -0,0 +1,101 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFourierBasisND_Laplacian(t *testing.T) {
+	xGrid, err := gridData.NewFromLength(6., 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yGrid, err := gridData.NewFromLength(8., 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zGrid, err := gridData.NewFromLength(5., 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := gridData.NewProductGrid(xGrid, yGrid, zGrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinE, err := FFTInitND(grid, 1., 2., 0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer kinE.Close()
+
+	// the multi-dimensional transform must agree with the sum of 1D transforms on the fibres
+	ref, err := NewKroneckerKinetic(grid, FFTInit(xGrid, 1.), FFTInit(yGrid, 2.), FFTInit(zGrid, 0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := grid.NPoints()
+	in := make([]float64, n)
+	for i := range in {
+		in[i] = math.Sin(0.7*float64(i)) + 0.1*float64(i%5)
+	}
+	out, expected := make([]float64, n), make([]float64, n)
+	kinE.MulVec(in, out)
+	ref.MulVec(in, expected)
+	for i := range out {
+		if math.Abs(out[i]-expected[i]) > 1e-10 {
+			t.Errorf("expected T psi component %d = %v, got %v", i, expected[i], out[i])
+		}
+	}
+
+	if _, err := FFTInitND(grid, 1.); err == nil {
+		t.Errorf("expected an error for a missing mass")
+	}
+}
+
+func TestFourierBasisND_MomentumOp(t *testing.T) {
+	xGrid, err := gridData.NewFromLength(20., 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yGrid, err := gridData.NewFromLength(20., 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := gridData.NewProductGrid(xGrid, yGrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinE, err := FFTInitND(grid, 1., 1.)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer kinE.Close()
+
+	// a plane wave exp(i (px x + py y)) fitting the box is an eigenfunction of both momenta
+	px, py := 2*math.Pi*3/20., 2*math.Pi*2/20.
+	psi := make([]complex128, grid.NPoints())
+	x := make([]float64, 2)
+	for i := range psi {
+		grid.Point(i, x)
+		psi[i] = cmplx.Exp(complex(0, px*x[0]+py*x[1]))
+	}
+
+	out := make([]complex128, len(psi))
+	for axis, p := range []float64{px, py} {
+		kinE.MomentumOp(axis, psi, out)
+		for i := range psi {
+			if cmplx.Abs(out[i]-complex(p, 0)*psi[i]) > 1e-10 {
+				t.Fatalf("axis %d: expected %v psi at point %d, got %v", axis, p, i, out[i]/psi[i])
+			}
+		}
+	}
+
+	kinE.LaplacianOp(psi, out)
+	for i := range psi {
+		if cmplx.Abs(out[i]-complex(0.5*(px*px+py*py), 0)*psi[i]) > 1e-10 {
+			t.Fatalf("expected kinetic energy %v at point %d, got %v", 0.5*(px*px+py*py), i, out[i]/psi[i])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"sync"
+	"testing"
+)
+
+func TestFourierBasis_NoAllocations(t *testing.T) {
+	// the pool and operators are backend independent; GoFFT is checked because its
+	// transforms are pure Go, where AllocsPerRun also sees inside the plan
+	grid, err := gridData.NewFromLength(20., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := FFTInitWith(grid, 1., GoFFT)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	psi, out := make([]complex128, 64), make([]complex128, 64)
+	re, reOut := make([]float64, 64), make([]float64, 64)
+	for i := range psi {
+		psi[i] = complex(math.Exp(-0.1*float64(i-32)*float64(i-32)), 0)
+		re[i] = real(psi[i])
+	}
+	ops := map[string]func(){
+		"MomentumOp":    func() { f.MomentumOp(psi, out) },
+		"LaplacianOp":   func() { f.LaplacianOp(psi, out) },
+		"MulVec":        func() { f.MulVec(re, reOut) },
+		"ExpDtTo":       func() { _ = f.ExpDtTo(0.1, re, reOut) },
+		"ExpIdtInPlace": func() { _ = f.ExpIdtInPlace(0.1, out) },
+	}
+	for name, op := range ops {
+		if allocs := testing.AllocsPerRun(20, op); allocs != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, allocs)
+		}
+	}
+	f.Close()
+	f.Close()
+}
+
+func TestFourierBasis_Concurrent(t *testing.T) {
+	grid, err := gridData.NewFromLength(20., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := FFTInit(grid, 1.)
+	defer f.Close()
+
+	psi := make([]complex128, 64)
+	for i, x := range grid.RValues() {
+		psi[i] = cmplx.Exp(complex(-0.5*x*x, 2*x))
+	}
+	expected := make([]complex128, 64)
+	f.LaplacianOp(psi, expected)
+
+	var wg sync.WaitGroup
+	errs := make(chan int, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out := make([]complex128, 64)
+			for rep := 0; rep < 50; rep++ {
+				f.LaplacianOp(psi, out)
+				for i := range out {
+					if cmplx.Abs(out[i]-expected[i]) > 1e-12 {
+						errs <- i
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for i := range errs {
+		t.Errorf("concurrent LaplacianOp differs at point %d", i)
+	}
+}
+
+func TestFourierBasis_MomentumSign(t *testing.T) {
+	grid, err := gridData.NewFromLength(20., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := FFTInit(grid, 1.)
+	defer f.Close()
+
+	// the plane wave exp(i p x) has momentum +p, for p below and above zero
+	for _, n := range []int{3, -5} {
+		p := 2 * math.Pi * float64(n) / 20.
+		psi := make([]complex128, grid.NPoints())
+		for i, x := range grid.RValues() {
+			psi[i] = cmplx.Exp(complex(0, p*x))
+		}
+		out := make([]complex128, len(psi))
+		f.MomentumOp(psi, out)
+		for i := range psi {
+			if cmplx.Abs(out[i]-complex(p, 0)*psi[i]) > 1e-10 {
+				t.Fatalf("expected %v psi at point %d, got %v", p, i, out[i]/psi[i])
+			}
+		}
+	}
+}
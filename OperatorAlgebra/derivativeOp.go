@@ -3,20 +3,21 @@ package OperatorAlgebra
 import "gonum.org/v1/gonum/mat"
 
 type MatrixOp interface {
+	GetMat() *mat.Dense
 	RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error)
 }
 
 type CanTimeSolverOp interface {
-	ExpDtTo(At float64, In []float64, Out []float64)
-	ExpDtInPlace(At float64, InOut []float64)
+	ExpDtTo(At float64, In []float64, Out []float64) error
+	ExpDtInPlace(At float64, InOut []float64) error
 }
 
 type TimeSolverOp interface {
-	ExpDtTo(Dt float64, In []float64, Out []float64)
-	ExpDtInPlace(Dt float64, InOut []float64)
+	ExpDtTo(Dt float64, In []float64, Out []float64) error
+	ExpDtInPlace(Dt float64, InOut []float64) error
 
-	ExpIdtTo(Dt float64, In []complex128, Out []complex128)
-	ExpIdtInPlace(Dt float64, InOut []complex128)
+	ExpIdtTo(Dt float64, In []complex128, Out []complex128) error
+	ExpIdtInPlace(Dt float64, InOut []complex128) error
 }
 
 type MomentumOp interface {
@@ -38,3 +39,11 @@ type CanKineticOp interface {
 	MatrixOp
 	CanTimeSolverOp
 }
+
+// DVRBasis is a discrete variable representation: the kinetic energy on quadrature
+// points, with weights such that Sum_k w_k f(x_k) approximates the integral of f
+type DVRBasis interface {
+	KineticOp
+	Points() []float64
+	Weights() []float64
+}
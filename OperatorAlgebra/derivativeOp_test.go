@@ -2,6 +2,8 @@ package OperatorAlgebra
 
 import (
 	"GoProject/gridData"
+	"math"
+	"math/cmplx"
 	"testing"
 )
 
@@ -11,5 +13,92 @@ func TestKeDVR_Evaluate(t *testing.T) {
 		panic(err)
 	}
 	kinE := NewKeDVR(rgrid, 1.)
-	kinE.Mat()
+	kinE.GetMat()
+}
+
+func TestIterativeEigenSolver_LowestEigen(t *testing.T) {
+	rgrid, err := gridData.NewFromLength(20., 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := rgrid.RValues()
+	vPot := make([]float64, len(x))
+	for i, xi := range x {
+		vPot[i] = 0.5 * xi * xi
+	}
+
+	kinE := NewKeDVR(rgrid, 1.)
+	matVec := func(in, out []float64) {
+		kinE.MulVec(in, out)
+		for i := range out {
+			out[i] += vPot[i] * in[i]
+		}
+	}
+	diagonal := make([]float64, len(x))
+	for i := range diagonal {
+		diagonal[i] = kinE.element(i, i) + vPot[i]
+	}
+
+	solvers := map[string]*IterativeEigenSolver{
+		"Lanczos":  NewLanczos(len(x), matVec),
+		"Davidson": NewDavidson(len(x), matVec, diagonal),
+	}
+	for name, solver := range solvers {
+		evals, evecs, err := solver.LowestEigen(6)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		for n, e := range evals {
+			if math.Abs(e-(float64(n)+0.5)) > 1e-6 {
+				t.Errorf("%s: expected E%d = %v, got %v", name, n, float64(n)+0.5, e)
+			}
+		}
+		if rows, cols := evecs.Dims(); rows != len(x) || cols != 6 {
+			t.Errorf("%s: expected eigenvectors %dx6, got %dx%d", name, len(x), rows, cols)
+		}
+	}
+}
+
+func TestKeDVR_ExpIdtTo(t *testing.T) {
+	rgrid, err := gridData.NewFromLength(30., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var kinE KineticOp = NewKeDVR(rgrid, 1.)
+	fourier := FFTInit(rgrid, 1.)
+
+	psi := make([]complex128, rgrid.NPoints())
+	for i, x := range rgrid.RValues() {
+		psi[i] = complex(math.Exp(-x*x/2), 0) * cmplx.Exp(complex(0, x))
+	}
+
+	out := make([]complex128, len(psi))
+	if err := kinE.ExpIdtTo(0.5, psi, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reference := make([]complex128, len(psi))
+	if err := fourier.ExpIdtTo(0.5, psi, reference); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	normIn, normOut := 0., 0.
+	for i := range psi {
+		normIn += cmplx.Abs(psi[i]) * cmplx.Abs(psi[i])
+		normOut += cmplx.Abs(out[i]) * cmplx.Abs(out[i])
+		if cmplx.Abs(out[i]-reference[i]) > 1e-6 {
+			t.Fatalf("expected %v at point %d, got %v", reference[i], i, out[i])
+		}
+	}
+	if math.Abs(normIn-normOut) > 1e-10 {
+		t.Errorf("expected norm %v to be conserved, got %v", normIn, normOut)
+	}
+
+	if err := kinE.ExpIdtInPlace(-0.5, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range psi {
+		if cmplx.Abs(out[i]-psi[i]) > 1e-10 {
+			t.Fatalf("expected back-propagation to recover %v at point %d, got %v", psi[i], i, out[i])
+		}
+	}
 }
@@ -6,120 +6,186 @@ import (
 	"math"
 	"math/cmplx"
 
-	"gonum.org/v1/gonum/blas"
-	"gonum.org/v1/gonum/blas/cblas128"
 	"gonum.org/v1/gonum/mat"
 )
 
-// KeDvrBasis represents the kinetic energy operator in DVR basis with caching
-type KeDvrBasis struct {
-	grid        *gridData.RadGrid
-	mass        float64
-	ndims       int
-	dx2         float64
-	massDx2     float64
-	invMassDx2  float64
-	diagTerm    float64
-	diagTermZ2I float64
-	kMat        *mat.Dense
-	kMatCached  bool
-}
+// Domain selects the sinc-type DVR of KeDvrBasis on the uniform grid
+type Domain int
+
+const (
+	// FullLine is the Colbert-Miller sinc DVR on (-infinity, infinity)
+	FullLine Domain = iota
+	// HalfLine is the sinc DVR on [0, infinity) with psi(0) = 0; the grid points must be
+	// positive multiples of dr, as made by gridData.NewRadialGrid
+	HalfLine
+	// Ring is the periodic Fourier-grid DVR with psi(x + L) = psi(x), L = N dr
+	Ring
+)
 
-func (k *KeDvrBasis) ExpDtTo(Dt float64, In []float64, Out []float64) {
-	//TODO implement me
-	panic("implement me")
+func (d Domain) String() string {
+	switch d {
+	case FullLine:
+		return "full"
+	case HalfLine:
+		return "half"
+	case Ring:
+		return "ring"
+	}
+	return fmt.Sprintf("Domain(%d)", int(d))
 }
 
-func (k *KeDvrBasis) ExpIdtTo(Dt float64, In []complex128, Out []complex128) {
-	//TODO implement me
-	panic("implement me")
+// KeDvrBasis represents the kinetic energy operator in DVR basis with caching
+type KeDvrBasis struct {
+	grid       *gridData.RadGrid
+	mass       float64
+	domain     Domain
+	ndims      int
+	n0         int
+	dx2        float64
+	massDx2    float64
+	invMassDx2 float64
+	diagTerm   float64
+	kMat       *mat.Dense
+	kMatCached bool
+	expMat     *mat.Dense
+	expDt      float64
+	expCached  bool
+	eigVals    []float64
+	eigVecs    *mat.Dense
+	eigCached  bool
 }
 
-// NewKeDVR creates and initializes a new kinetic energy DVR basis
+// NewKeDVR creates and initializes a new kinetic energy sinc DVR on (-infinity, infinity)
 func NewKeDVR(grid *gridData.RadGrid, mass float64) *KeDvrBasis {
+	k, _ := NewKeDVRDomain(grid, mass, FullLine)
+	return k
+}
+
+// NewKeDVRDomain creates the kinetic energy DVR for an explicitly chosen domain
+func NewKeDVRDomain(grid *gridData.RadGrid, mass float64, domain Domain) (*KeDvrBasis, error) {
 	ndim := int(grid.NPoints())
 	dx2 := grid.DeltaR() * grid.DeltaR()
 	massDx2 := mass * dx2
-	invMassDx2 := 1.0 / massDx2
-	diagTerm := math.Pi * math.Pi / (6.0 * massDx2)
-
-	return &KeDvrBasis{
-		grid:        grid,
-		mass:        mass,
-		ndims:       ndim,
-		dx2:         dx2,
-		massDx2:     massDx2,
-		invMassDx2:  invMassDx2,
-		diagTerm:    diagTerm,
-		diagTermZ2I: diagTerm - 0.25, // Pre-compute for zero-to-infinity
-		kMat:        mat.NewDense(ndim, ndim, nil),
-		kMatCached:  false,
-	}
-}
 
-// isZeroToInfinity determines if the grid domain is [0, infinity)
-func (k *KeDvrBasis) isZeroToInfinity() bool {
-	return math.Abs(k.grid.RMax()+k.grid.RMin()) >= 1
-}
-
-// buildRealMinInftyToInfty constructs the kinetic energy matrix for (-∞, ∞) domain
-func (k *KeDvrBasis) buildRealMinInftyToInfty(mat *mat.Dense) {
-	for i := 0; i < k.ndims; i++ {
-		mat.Set(i, i, k.diagTerm)
+	k := &KeDvrBasis{
+		grid:       grid,
+		mass:       mass,
+		domain:     domain,
+		ndims:      ndim,
+		dx2:        dx2,
+		massDx2:    massDx2,
+		invMassDx2: 1.0 / massDx2,
+		diagTerm:   math.Pi * math.Pi / (6.0 * massDx2),
+		kMat:       mat.NewDense(ndim, ndim, nil),
 	}
 
-	for i := 1; i < k.ndims; i++ {
-		for j := 0; j < i; j++ {
-			diff := i - j
-			sign := float64(1 - 2*(diff&1))
-			diffSq := float64(diff * diff)
-			val := sign * k.invMassDx2 / diffSq
-
-			mat.Set(i, j, val)
-			mat.Set(j, i, val)
+	switch domain {
+	case FullLine:
+	case HalfLine:
+		// r_i = (n0 + i) dr, the mirror images -r_j of the odd extension lie on the same lattice
+		ratio := grid.RMin() / grid.DeltaR()
+		k.n0 = int(math.Round(ratio))
+		if k.n0 < 1 || math.Abs(ratio-float64(k.n0)) > 1e-8*math.Max(1, ratio) {
+			return nil, fmt.Errorf("half-line DVR needs rMin to be a positive multiple of dr = %g, got %g",
+				grid.DeltaR(), grid.RMin())
 		}
-	}
-}
-
-// buildRealZeroToInfinity constructs the kinetic energy matrix for [0, ∞) domain
-func (k *KeDvrBasis) buildRealZeroToInfinity(mat *mat.Dense) {
-	for i := 0; i < k.ndims; i++ {
-		diagVal := k.diagTerm
-		if i > 0 {
-			diagVal += k.diagTermZ2I / float64(i*i)
+	case Ring:
+		if ndim < 2 {
+			return nil, fmt.Errorf("periodic DVR needs at least 2 points")
+		}
+		// with L = N dr, (1/2m)(2 pi/L)^2 (N^2 + 2)/12 for even and (N^2 - 1)/12 for odd N
+		prefactor := 2 * math.Pi * math.Pi / (massDx2 * float64(ndim*ndim))
+		if ndim%2 == 0 {
+			k.diagTerm = prefactor * float64(ndim*ndim+2) / 12
+		} else {
+			k.diagTerm = prefactor * float64(ndim*ndim-1) / 12
 		}
-		mat.Set(i, i, diagVal)
+	default:
+		return nil, fmt.Errorf("unknown DVR domain %v", domain)
 	}
+	return k, nil
+}
 
-	for i := 1; i < k.ndims; i++ {
-		for j := 0; j < i; j++ {
-			diff := i - j
-			add := i + j
-			sign := float64(1 - 2*(diff&1))
-			diffSq := float64(diff * diff)
-			addSq := float64(add * add)
+func (k *KeDvrBasis) Domain() Domain { return k.domain }
 
-			val := sign * k.invMassDx2 * (1.0/diffSq - 1.0/addSq)
+// Points returns the DVR points, the grid points
+func (k *KeDvrBasis) Points() []float64 { return k.grid.RValues() }
 
-			mat.Set(i, j, val)
-			mat.Set(j, i, val)
-		}
+// Weights returns the quadrature weights, dr at every point
+func (k *KeDvrBasis) Weights() []float64 {
+	weights := make([]float64, k.ndims)
+	for i := range weights {
+		weights[i] = k.grid.DeltaR()
 	}
+	return weights
 }
 
 // GetMat returns the kinetic energy matrix, using cache if available
 func (k *KeDvrBasis) GetMat() *mat.Dense {
 	if !k.kMatCached {
-		if k.isZeroToInfinity() {
-			k.buildRealZeroToInfinity(k.kMat)
-		} else {
-			k.buildRealMinInftyToInfty(k.kMat)
+		for i := 0; i < k.ndims; i++ {
+			k.kMat.Set(i, i, k.element(i, i))
+			for j := 0; j < i; j++ {
+				val := k.element(i, j)
+				k.kMat.Set(i, j, val)
+				k.kMat.Set(j, i, val)
+			}
 		}
 		k.kMatCached = true
 	}
 	return k.kMat
 }
 
+// element returns the kinetic energy matrix element K_ij without building the matrix
+func (k *KeDvrBasis) element(i, j int) float64 {
+	diff := i - j
+	sign := float64(1 - 2*(diff&1))
+
+	switch k.domain {
+	case HalfLine:
+		// (1/2m dr^2)(-1)^(i-j) (2/(n_i-n_j)^2 - 2/(n_i+n_j)^2), diagonal pi^2/3 - 1/(2 n_i^2)
+		add := float64(2*k.n0 + i + j)
+		if i == j {
+			return k.diagTerm - k.invMassDx2/(add*add)
+		}
+		return sign * k.invMassDx2 * (1.0/float64(diff*diff) - 1.0/(add*add))
+	case Ring:
+		if i == j {
+			return k.diagTerm
+		}
+		// (1/2m)(2 pi/L)^2 (-1)^(i-j) / (2 sin^2(pi (i-j)/N)), times cos(pi (i-j)/N) for odd N
+		sin, cos := math.Sincos(math.Pi * float64(diff) / float64(k.ndims))
+		val := sign * math.Pi * math.Pi * k.invMassDx2 / float64(k.ndims*k.ndims) / (sin * sin)
+		if k.ndims%2 == 1 {
+			val *= cos
+		}
+		return val
+	}
+
+	if i == j {
+		return k.diagTerm
+	}
+	return sign * k.invMassDx2 / float64(diff*diff)
+}
+
+// MulVec computes out = K in, using the cached matrix if available and the
+// analytic matrix elements otherwise, so the dense matrix is never formed
+func (k *KeDvrBasis) MulVec(in, out []float64) {
+	if k.kMatCached {
+		outVec := mat.NewVecDense(k.ndims, out)
+		outVec.MulVec(k.kMat, mat.NewVecDense(k.ndims, in))
+		return
+	}
+
+	for i := 0; i < k.ndims; i++ {
+		sum := 0.
+		for j := 0; j < k.ndims; j++ {
+			sum += k.element(i, j) * in[j]
+		}
+		out[i] = sum
+	}
+}
+
 // scaleMatrixComplexParam scales a real matrix by exp(-2iθ) and stores in complex matrix
 func scaleMatrixComplexParam(realMat *mat.Dense, complexMat *mat.CDense, theta float64) {
 	rows, cols := realMat.Dims()
@@ -144,24 +210,34 @@ func (k *KeDvrBasis) GetComplexMat(theta float64) *mat.CDense {
 // RealDiagonalize diagonalizes the real kinetic energy matrix
 func (k *KeDvrBasis) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
 	eigenvalues = make([]float64, k.ndims)
-	eigenvectors = mat.NewDense(k.ndims, k.ndims, nil)
-	eigenvectors = k.GetMat()
+	eigenvectors = mat.DenseCopyOf(k.GetMat())
 	err = RealDiagonalizeLapack(eigenvectors, eigenvalues)
 	return eigenvalues, eigenvectors, err
 }
 
+// expMatrix returns exp(dt * K), reusing the last result when dt is unchanged
+func (k *KeDvrBasis) expMatrix(dt float64) *mat.Dense {
+	if !k.expCached || k.expDt != dt {
+		scaledMat := mat.NewDense(k.ndims, k.ndims, nil)
+		scaledMat.Scale(dt, k.GetMat())
+
+		if k.expMat == nil {
+			k.expMat = mat.NewDense(k.ndims, k.ndims, nil)
+		}
+		k.expMat.Exp(scaledMat)
+		k.expDt = dt
+		k.expCached = true
+	}
+	return k.expMat
+}
+
 // ExpDt computes exp(dt * K) and applies it to input vector
 func (k *KeDvrBasis) ExpDt(dt float64, in []float64) ([]float64, error) {
 	if len(in) != k.ndims {
 		return nil, fmt.Errorf("input vector length %d doesn't match basis dimension %d", len(in), k.ndims)
 	}
 
-	kMat := k.GetMat()
-	scaledMat := mat.NewDense(k.ndims, k.ndims, nil)
-	scaledMat.Scale(dt, kMat)
-
-	expMat := mat.NewDense(k.ndims, k.ndims, nil)
-	expMat.Exp(scaledMat)
+	expMat := k.expMatrix(dt)
 
 	result := make([]float64, k.ndims)
 	resultVec := mat.NewVecDense(k.ndims, result)
@@ -171,88 +247,125 @@ func (k *KeDvrBasis) ExpDt(dt float64, in []float64) ([]float64, error) {
 	return result, nil
 }
 
-// ExpDtInPlace computes exp(dt * K) and applies it to input vector in-place
-func (k *KeDvrBasis) ExpDtInPlace(dt float64, inOut []float64) error {
-	if len(inOut) != k.ndims {
-		return fmt.Errorf("vector length %d doesn't match basis dimension %d", len(inOut), k.ndims)
+// ExpDtTo computes exp(dt * K) In and stores the result in Out, which may alias In
+func (k *KeDvrBasis) ExpDtTo(dt float64, in []float64, out []float64) error {
+	if len(in) != k.ndims || len(out) != k.ndims {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), k.ndims)
 	}
 
-	kMat := k.GetMat()
-	scaledMat := mat.NewDense(k.ndims, k.ndims, nil)
-	scaledMat.Scale(dt, kMat)
-
-	expMat := mat.NewDense(k.ndims, k.ndims, nil)
-	expMat.Exp(scaledMat)
+	expMat := k.expMatrix(dt)
 
 	temp := make([]float64, k.ndims)
 	tempVec := mat.NewVecDense(k.ndims, temp)
-	inOutVec := mat.NewVecDense(k.ndims, inOut)
-	tempVec.MulVec(expMat, inOutVec)
+	tempVec.MulVec(expMat, mat.NewVecDense(k.ndims, in))
 
-	copy(inOut, temp)
+	copy(out, temp)
 	return nil
 }
 
-// ExpIdt computes exp(i*dt*K) and applies it to complex input vector
-// This implements the complex-scaled exponential: exp(i*dt*K)
-func (k *KeDvrBasis) ExpIdt(dt float64, in []complex128) ([]complex128, error) {
-	if len(in) != k.ndims {
-		return nil, fmt.Errorf("input vector length %d doesn't match basis dimension %d", len(in), k.ndims)
+// ExpDtInPlace computes exp(dt * K) and applies it to input vector in-place
+func (k *KeDvrBasis) ExpDtInPlace(dt float64, inOut []float64) error {
+	return k.ExpDtTo(dt, inOut, inOut)
+}
+
+// eigenDecomposition returns the eigenvalues and eigenvectors of K, using cache if available
+func (k *KeDvrBasis) eigenDecomposition() ([]float64, *mat.Dense, error) {
+	if !k.eigCached {
+		eigVals, eigVecs, err := k.RealDiagonalize()
+		if err != nil {
+			return nil, nil, err
+		}
+		k.eigVals = eigVals
+		k.eigVecs = eigVecs
+		k.eigCached = true
 	}
+	return k.eigVals, k.eigVecs, nil
+}
 
-	kMat := k.GetMat()
-	scaledMat := mat.NewDense(k.ndims, k.ndims, nil)
-	scaledMat.Scale(dt, kMat)
+// ExpIdtTo computes exp(-i dt K) In and stores the result in Out, which may alias In.
+// K is real symmetric, so exp(-i dt K) = U exp(-i dt E) U^T with the cached
+// eigendecomposition K = U E U^T; the real and imaginary parts are propagated separately.
+func (k *KeDvrBasis) ExpIdtTo(dt float64, in []complex128, out []complex128) error {
+	if len(in) != k.ndims || len(out) != k.ndims {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), k.ndims)
+	}
 
-	complexScaled := mat.NewCDense(k.ndims, k.ndims, nil)
-	for i := 0; i < k.ndims; i++ {
-		for j := 0; j < k.ndims; j++ {
-			complexScaled.Set(i, j, 1i*complex(scaledMat.At(i, j), 0))
-		}
+	eigVals, eigVecs, err := k.eigenDecomposition()
+	if err != nil {
+		return err
+	}
+	expIdtEigen(eigVals, eigVecs, dt, in, out)
+	return nil
+}
+
+// expIdtEigen applies exp(-i dt K) = U exp(-i dt E) U^T for a real symmetric K = U E U^T,
+// propagating the real and imaginary parts separately; out may alias in
+func expIdtEigen(eigVals []float64, eigVecs *mat.Dense, dt float64, in []complex128, out []complex128) {
+	ndims := len(eigVals)
+	re := mat.NewVecDense(ndims, nil)
+	im := mat.NewVecDense(ndims, nil)
+	for i, v := range in {
+		re.SetVec(i, real(v))
+		im.SetVec(i, imag(v))
 	}
 
-	expMat := mat.NewCDense(k.ndims, k.ndims, nil)
-	//	expMat.Exp(complexScaled)
+	coefRe := mat.NewVecDense(ndims, nil)
+	coefIm := mat.NewVecDense(ndims, nil)
+	coefRe.MulVec(eigVecs.T(), re)
+	coefIm.MulVec(eigVecs.T(), im)
 
-	result := make([]complex128, k.ndims)
-	cblas128.Gemv(
-		blas.NoTrans,
-		complex(1, 0),
-		expMat.RawCMatrix(),
-		cblas128.Vector{N: len(in), Data: in, Inc: 1},
-		complex(0, 0),
-		cblas128.Vector{N: len(result), Data: result, Inc: 1},
-	)
+	for n, e := range eigVals {
+		sin, cos := math.Sincos(-dt * e)
+		cRe, cIm := coefRe.AtVec(n), coefIm.AtVec(n)
+		coefRe.SetVec(n, cRe*cos-cIm*sin)
+		coefIm.SetVec(n, cRe*sin+cIm*cos)
+	}
 
-	return result, nil
+	re.MulVec(eigVecs, coefRe)
+	im.MulVec(eigVecs, coefIm)
+	for i := range out {
+		out[i] = complex(re.AtVec(i), im.AtVec(i))
+	}
 }
 
-func (k *KeDvrBasis) ExpIdtInPlace(dt float64, inOut []complex128) error {
-	if len(inOut) != k.ndims {
-		return fmt.Errorf("vector length %d doesn't match basis dimension %d", len(inOut), k.ndims)
+// expDtEigen applies exp(dt K) = U exp(dt E) U^T for a real symmetric K = U E U^T; out may alias in
+func expDtEigen(eigVals []float64, eigVecs *mat.Dense, dt float64, in []float64, out []float64) {
+	ndims := len(eigVals)
+	coef := mat.NewVecDense(ndims, nil)
+	coef.MulVec(eigVecs.T(), mat.NewVecDense(ndims, in))
+	for n, e := range eigVals {
+		coef.SetVec(n, coef.AtVec(n)*math.Exp(dt*e))
 	}
+	mat.NewVecDense(ndims, out).MulVec(eigVecs, coef)
+}
 
-	result, err := k.ExpIdt(dt, inOut)
-	if err != nil {
-		return err
+// ExpIdt computes exp(-i dt K) and applies it to complex input vector
+func (k *KeDvrBasis) ExpIdt(dt float64, in []complex128) ([]complex128, error) {
+	result := make([]complex128, len(in))
+	if err := k.ExpIdtTo(dt, in, result); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	copy(inOut, result)
-	return nil
+// ExpIdtInPlace computes exp(-i dt K) and applies it to complex input vector in-place
+func (k *KeDvrBasis) ExpIdtInPlace(dt float64, inOut []complex128) error {
+	return k.ExpIdtTo(dt, inOut, inOut)
 }
 
 func (k *KeDvrBasis) Clone() *KeDvrBasis {
 	newK := &KeDvrBasis{
-		grid:        k.grid,
-		mass:        k.mass,
-		ndims:       k.ndims,
-		dx2:         k.dx2,
-		massDx2:     k.massDx2,
-		invMassDx2:  k.invMassDx2,
-		diagTerm:    k.diagTerm,
-		diagTermZ2I: k.diagTermZ2I,
-		kMat:        mat.NewDense(k.ndims, k.ndims, nil),
-		kMatCached:  false,
+		grid:       k.grid,
+		mass:       k.mass,
+		domain:     k.domain,
+		ndims:      k.ndims,
+		n0:         k.n0,
+		dx2:        k.dx2,
+		massDx2:    k.massDx2,
+		invMassDx2: k.invMassDx2,
+		diagTerm:   k.diagTerm,
+		kMat:       mat.NewDense(k.ndims, k.ndims, nil),
+		kMatCached: false,
 	}
 	if k.kMatCached {
 		newK.kMat.Copy(k.kMat)
@@ -263,4 +376,6 @@ func (k *KeDvrBasis) Clone() *KeDvrBasis {
 
 func (k *KeDvrBasis) Clear() {
 	k.kMatCached = false
+	k.expCached = false
+	k.eigCached = false
 }
@@ -0,0 +1,109 @@
+package OperatorAlgebra
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// FFTBackend selects the library that performs the Fourier transforms of the Fourier-grid operators
+type FFTBackend int
+
+const (
+	// FFTW uses the cgo binding to the FFTW C library
+	FFTW FFTBackend = iota
+	// GoFFT uses the pure-Go transforms of gonum's dsp/fourier, so no C toolchain is needed
+	GoFFT
+)
+
+func (b FFTBackend) String() string {
+	switch b {
+	case FFTW:
+		return "fftw"
+	case GoFFT:
+		return "go"
+	}
+	return fmt.Sprintf("FFTBackend(%d)", int(b))
+}
+
+// fftPlan transforms its own buffer in place, unnormalized in both directions
+// like FFTW, so a forward and backward transform multiply by the number of points
+type fftPlan interface {
+	Buffer() []complex128
+	Forward()
+	Backward()
+	Destroy()
+}
+
+// newFFTPlan creates a plan for a row-major array of the given shape
+func newFFTPlan(backend FFTBackend, shape []int) (fftPlan, error) {
+	for i, n := range shape {
+		if n < 1 {
+			return nil, fmt.Errorf("axis %d has %d points", i, n)
+		}
+	}
+	switch backend {
+	case FFTW:
+		return newFFTWPlan(shape)
+	case GoFFT:
+		return newGoFFTPlan(shape), nil
+	}
+	return nil, fmt.Errorf("unknown FFT backend %v", backend)
+}
+
+// goFFTPlan applies gonum's 1D complex FFT along every axis in turn
+type goFFTPlan struct {
+	shape   []int
+	strides []int
+	buff    []complex128
+	ffts    []*fourier.CmplxFFT
+	fibres  [][]complex128
+}
+
+func newGoFFTPlan(shape []int) *goFFTPlan {
+	p := &goFFTPlan{
+		shape:   append([]int(nil), shape...),
+		strides: make([]int, len(shape)),
+		ffts:    make([]*fourier.CmplxFFT, len(shape)),
+		fibres:  make([][]complex128, len(shape)),
+	}
+	size := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		p.strides[i] = size
+		size *= shape[i]
+		p.ffts[i] = fourier.NewCmplxFFT(shape[i])
+		p.fibres[i] = make([]complex128, shape[i])
+	}
+	p.buff = make([]complex128, size)
+	return p
+}
+
+func (p *goFFTPlan) Buffer() []complex128 { return p.buff }
+func (p *goFFTPlan) Forward()             { p.transform((*fourier.CmplxFFT).Coefficients) }
+func (p *goFFTPlan) Backward()            { p.transform((*fourier.CmplxFFT).Sequence) }
+func (p *goFFTPlan) Destroy()             {}
+
+// transform runs op on every fibre of every axis; the fibres of the last axis
+// are contiguous and transformed in place
+func (p *goFFTPlan) transform(op func(t *fourier.CmplxFFT, dst, src []complex128) []complex128) {
+	for axis, n := range p.shape {
+		stride := p.strides[axis]
+		fft, fibre := p.ffts[axis], p.fibres[axis]
+		for outer := 0; outer < len(p.buff); outer += n * stride {
+			for inner := 0; inner < stride; inner++ {
+				offset := outer + inner
+				if stride == 1 {
+					op(fft, p.buff[offset:offset+n], p.buff[offset:offset+n])
+					continue
+				}
+				for j := range fibre {
+					fibre[j] = p.buff[offset+j*stride]
+				}
+				op(fft, fibre, fibre)
+				for j, v := range fibre {
+					p.buff[offset+j*stride] = v
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestGoFFTPlan(t *testing.T) {
+	// compare against the defining sum X_k = Sum_j x_j exp(-2 pi i j k / N) on a 3 x 4 x 5 array
+	shape := []int{3, 4, 5}
+	plan, err := newFFTPlan(GoFFT, shape)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer plan.Destroy()
+
+	n := 60
+	data := make([]complex128, n)
+	for i := range data {
+		data[i] = complex(math.Sin(float64(i)), math.Cos(0.3*float64(i*i)))
+	}
+	copy(plan.Buffer(), data)
+	plan.Forward()
+
+	for k := 0; k < n; k++ {
+		kIdx := []int{k / 20, (k / 5) % 4, k % 5}
+		var ref complex128
+		for j, x := range data {
+			jIdx := []int{j / 20, (j / 5) % 4, j % 5}
+			phase := 0.
+			for axis, m := range shape {
+				phase += float64(jIdx[axis]*kIdx[axis]) / float64(m)
+			}
+			ref += x * cmplx.Exp(complex(0, -2*math.Pi*phase))
+		}
+		if cmplx.Abs(plan.Buffer()[k]-ref) > 1e-10 {
+			t.Errorf("expected coefficient %d = %v, got %v", k, ref, plan.Buffer()[k])
+		}
+	}
+
+	plan.Backward()
+	for i, x := range data {
+		if cmplx.Abs(plan.Buffer()[i]/complex(float64(n), 0)-x) > 1e-12 {
+			t.Errorf("expected round trip %v at %d, got %v", x, i, plan.Buffer()[i]/complex(float64(n), 0))
+		}
+	}
+}
+
+func TestFFTBackends_Agree(t *testing.T) {
+	if DefaultFFTBackend != FFTW {
+		t.Skip("FFTW backend not available without cgo")
+	}
+	grid, err := gridData.NewFromLength(20., 48)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fftw, err := FFTInitWith(grid, 2., FFTW)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	goFFT, err := FFTInitWith(grid, 2., GoFFT)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	psi := make([]complex128, 48)
+	for i, x := range grid.RValues() {
+		psi[i] = cmplx.Exp(complex(-0.5*(x-1)*(x-1), 1.5*x))
+	}
+	outW, outG := make([]complex128, 48), make([]complex128, 48)
+	ops := map[string][2]func(In, Out []complex128){
+		"MomentumOp":  {fftw.MomentumOp, goFFT.MomentumOp},
+		"LaplacianOp": {fftw.LaplacianOp, goFFT.LaplacianOp},
+	}
+	for name, op := range ops {
+		op[0](psi, outW)
+		op[1](psi, outG)
+		for i := range outW {
+			if cmplx.Abs(outW[i]-outG[i]) > 1e-10 {
+				t.Errorf("%s: fftw gives %v at %d, go gives %v", name, outW[i], i, outG[i])
+			}
+		}
+	}
+}
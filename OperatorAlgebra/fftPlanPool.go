@@ -0,0 +1,180 @@
+package OperatorAlgebra
+
+import (
+	"fmt"
+	"math/cmplx"
+	"sync"
+)
+
+// planPool hands FFT plans to concurrent callers. A plan is created the first time more
+// goroutines transform at once than there are idle plans, and is kept for reuse until
+// close, so steady-state operators do not allocate.
+type planPool struct {
+	backend FFTBackend
+	shape   []int
+	nPoints int
+
+	mu     sync.Mutex
+	idle   []fftPlan
+	all    []fftPlan
+	closed bool
+}
+
+// newPlanPool creates the pool with one plan, so that an unusable backend fails here
+func newPlanPool(backend FFTBackend, shape []int) (*planPool, error) {
+	plan, err := newFFTPlan(backend, shape)
+	if err != nil {
+		return nil, err
+	}
+	nPoints := len(plan.Buffer())
+	return &planPool{
+		backend: backend,
+		shape:   append([]int(nil), shape...),
+		nPoints: nPoints,
+		idle:    []fftPlan{plan},
+		all:     []fftPlan{plan},
+	}, nil
+}
+
+func (p *planPool) acquire() fftPlan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		panic("FFT operator used after Close")
+	}
+	if n := len(p.idle); n > 0 {
+		plan := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		return plan
+	}
+	plan, err := newFFTPlan(p.backend, p.shape)
+	if err != nil {
+		panic(fmt.Sprintf("creating %v plan: %v", p.backend, err))
+	}
+	p.all = append(p.all, plan)
+	return plan
+}
+
+func (p *planPool) release(plan fftPlan) {
+	p.mu.Lock()
+	p.idle = append(p.idle, plan)
+	p.mu.Unlock()
+}
+
+// close destroys every plan; it is safe to call more than once
+func (p *planPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	for _, plan := range p.all {
+		plan.Destroy()
+	}
+	p.idle, p.all, p.closed = nil, nil, true
+}
+
+// transform computes the unnormalized forward transform Out = F In
+func (p *planPool) transform(In, Out []complex128) {
+	p.checkLen(len(In), len(Out))
+	plan := p.acquire()
+	buff := plan.Buffer()
+	copy(buff, In)
+	plan.Forward()
+	copy(Out, buff)
+	p.release(plan)
+}
+
+// diagonalOp computes Out = F^-1 diag(op) F In, with the 1/N of the unnormalized
+// backward transform folded into the k-space multiplication
+func (p *planPool) diagonalOp(In, Out []complex128, op []float64) {
+	p.checkLen(len(In), len(Out))
+	plan := p.acquire()
+	buff := plan.Buffer()
+	copy(buff, In)
+	plan.Forward()
+
+	invN := 1.0 / float64(p.nPoints)
+	for i := range buff {
+		buff[i] *= complex(op[i]*invN, 0)
+	}
+
+	plan.Backward()
+	copy(Out, buff)
+	p.release(plan)
+}
+
+// diagonalOpReal is diagonalOp for a real vector, keeping the real part of the result
+func (p *planPool) diagonalOpReal(in, out []float64, op []float64) {
+	p.checkLen(len(in), len(out))
+	plan := p.acquire()
+	buff := plan.Buffer()
+	for i, v := range in {
+		buff[i] = complex(v, 0)
+	}
+	plan.Forward()
+
+	invN := 1.0 / float64(p.nPoints)
+	for i := range buff {
+		buff[i] *= complex(op[i]*invN, 0)
+	}
+
+	plan.Backward()
+	for i, v := range buff {
+		out[i] = real(v)
+	}
+	p.release(plan)
+}
+
+// expOp computes Out = F^-1 exp(factor diag(op)) F In
+func (p *planPool) expOp(In, Out []complex128, op []float64, factor complex128) {
+	p.checkLen(len(In), len(Out))
+	plan := p.acquire()
+	buff := plan.Buffer()
+	copy(buff, In)
+	plan.Forward()
+
+	invN := complex(1.0/float64(p.nPoints), 0)
+	for i := range buff {
+		buff[i] *= cmplx.Exp(factor*complex(op[i], 0)) * invN
+	}
+
+	plan.Backward()
+	copy(Out, buff)
+	p.release(plan)
+}
+
+// expOpReal is expOp with a real factor for a real vector
+func (p *planPool) expOpReal(in, out []float64, op []float64, factor float64) {
+	p.checkLen(len(in), len(out))
+	plan := p.acquire()
+	buff := plan.Buffer()
+	for i, v := range in {
+		buff[i] = complex(v, 0)
+	}
+	plan.Forward()
+
+	invN := complex(1.0/float64(p.nPoints), 0)
+	for i := range buff {
+		buff[i] *= cmplx.Exp(complex(factor*op[i], 0)) * invN
+	}
+
+	plan.Backward()
+	for i, v := range buff {
+		out[i] = real(v)
+	}
+	p.release(plan)
+}
+
+func (p *planPool) checkLen(in, out int) {
+	if in != p.nPoints || out != p.nPoints {
+		panic(fmt.Sprintf("length mismatch: in=%d, out=%d, grid=%d", in, out, p.nPoints))
+	}
+}
+
+func checkBasisLen(nPoints, in, out int) error {
+	if in != nPoints || out != nPoints {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", in, out, nPoints)
+	}
+	return nil
+}
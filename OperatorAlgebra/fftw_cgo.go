@@ -0,0 +1,43 @@
+//go:build cgo
+
+package OperatorAlgebra
+
+import (
+	"sync"
+
+	"github.com/jvlmdr/go-fftw/fftw"
+)
+
+// DefaultFFTBackend is used by FFTInit and FFTInitND; FFTW when cgo is available
+const DefaultFFTBackend = FFTW
+
+// plannerMu serializes the FFTW planner, which unlike plan execution is not thread safe
+var plannerMu sync.Mutex
+
+type fftwPlan struct {
+	buff     *fftw.ArrayN
+	fftPlan  *fftw.Plan
+	ifftPlan *fftw.Plan
+}
+
+func newFFTWPlan(shape []int) (fftPlan, error) {
+	plannerMu.Lock()
+	defer plannerMu.Unlock()
+	buff := fftw.NewArrayN(shape)
+	return &fftwPlan{
+		buff:     buff,
+		fftPlan:  fftw.NewPlanN(buff, buff, fftw.Forward, fftw.Estimate),
+		ifftPlan: fftw.NewPlanN(buff, buff, fftw.Backward, fftw.Estimate),
+	}, nil
+}
+
+func (p *fftwPlan) Buffer() []complex128 { return p.buff.Elems }
+func (p *fftwPlan) Forward()             { p.fftPlan.Execute() }
+func (p *fftwPlan) Backward()            { p.ifftPlan.Execute() }
+
+func (p *fftwPlan) Destroy() {
+	plannerMu.Lock()
+	defer plannerMu.Unlock()
+	p.fftPlan.Destroy()
+	p.ifftPlan.Destroy()
+}
@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package OperatorAlgebra
+
+import "fmt"
+
+// DefaultFFTBackend is used by FFTInit and FFTInitND; the pure-Go transforms without cgo
+const DefaultFFTBackend = GoFFT
+
+func newFFTWPlan(shape []int) (fftPlan, error) {
+	return nil, fmt.Errorf("the FFTW backend needs cgo; use GoFFT in builds with CGO_ENABLED=0")
+}
@@ -0,0 +1,300 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// GaussDVR is a DVR on the points of a Gauss quadrature: the position operator is
+// diagonalized in a basis of orthonormal polynomials times sqrt(W), x = U diag(x_k) U^T,
+// and the kinetic energy of that basis is carried to the points, K = U^T K_FBR U
+type GaussDVR struct {
+	name    string
+	points  []float64
+	weights []float64
+	kMat    *mat.Dense
+
+	eigVals   []float64
+	eigVecs   *mat.Dense
+	eigCached bool
+}
+
+// orthoFamily holds the recurrence b_{n+1} p_{n+1} = (y - a_n) p_n - b_n p_{n-1} of the
+// polynomials orthonormal under the weight W(y), whose integral is mu0
+type orthoFamily struct {
+	a, b func(n int) float64
+	mu0  float64
+}
+
+var (
+	legendreFamily = orthoFamily{
+		a:   func(int) float64 { return 0 },
+		b:   func(n int) float64 { return float64(n) / math.Sqrt(float64(4*n*n-1)) },
+		mu0: 2,
+	}
+	hermiteFamily = orthoFamily{
+		a:   func(int) float64 { return 0 },
+		b:   func(n int) float64 { return math.Sqrt(float64(n) / 2) },
+		mu0: math.Sqrt(math.Pi),
+	}
+)
+
+// laguerreFamily is the generalized Laguerre family with W = y^alpha exp(-y)
+func laguerreFamily(alpha float64) orthoFamily {
+	return orthoFamily{
+		a:   func(n int) float64 { return 2*float64(n) + alpha + 1 },
+		b:   func(n int) float64 { return math.Sqrt(float64(n) * (float64(n) + alpha)) },
+		mu0: math.Gamma(alpha + 1),
+	}
+}
+
+// gaussRule returns the n points and weights of the Gauss rule for W from the Jacobi
+// matrix, with U_nk = sqrt(w_k) p_n(y_k) as columns, signed so that U_0k > 0
+func (f orthoFamily) gaussRule(n int) (y, w []float64, u *mat.Dense, err error) {
+	jacobi := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		jacobi.Set(i, i, f.a(i))
+		if i > 0 {
+			jacobi.Set(i, i-1, f.b(i))
+			jacobi.Set(i-1, i, f.b(i))
+		}
+	}
+	y = make([]float64, n)
+	if err := RealDiagonalizeLapack(jacobi, y); err != nil {
+		return nil, nil, nil, err
+	}
+
+	w = make([]float64, n)
+	for k := 0; k < n; k++ {
+		if jacobi.At(0, k) < 0 {
+			for i := 0; i < n; i++ {
+				jacobi.Set(i, k, -jacobi.At(i, k))
+			}
+		}
+		w[k] = f.mu0 * jacobi.At(0, k) * jacobi.At(0, k)
+	}
+	return y, w, jacobi, nil
+}
+
+// evaluate fills p[n] = p_n(y) and dp[n] = p_n'(y) for n < len(p)
+func (f orthoFamily) evaluate(y float64, p, dp []float64) {
+	p[0], dp[0] = 1/math.Sqrt(f.mu0), 0
+	for n := 0; n+1 < len(p); n++ {
+		pPrev, dpPrev := 0., 0.
+		if n > 0 {
+			pPrev, dpPrev = f.b(n)*p[n-1], f.b(n)*dp[n-1]
+		}
+		bNext := f.b(n + 1)
+		p[n+1] = ((y-f.a(n))*p[n] - pPrev) / bNext
+		dp[n+1] = (p[n] + (y-f.a(n))*dp[n] - dpPrev) / bNext
+	}
+}
+
+// derivativeForm returns K_FBR,ij = 1/2 Int phi_i'(y) phi_j'(y) dy for phi_n = sqrt(W) p_n.
+// The integral is done with the Gauss rule of quad, exact once n+1 points are used;
+// logDeriv is d ln sqrt(W)/dy and ratio = W/W_quad.
+func (f orthoFamily) derivativeForm(n int, quad orthoFamily, logDeriv, ratio func(y float64) float64) (*mat.Dense, error) {
+	yq, wq, _, err := quad.gaussRule(n + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	kFBR := mat.NewDense(n, n, nil)
+	p, dp, d := make([]float64, n), make([]float64, n), make([]float64, n)
+	for q, y := range yq {
+		f.evaluate(y, p, dp)
+		for i := range d {
+			d[i] = dp[i] + logDeriv(y)*p[i]
+		}
+		scale := 0.5 * wq[q] * ratio(y)
+		for i := 0; i < n; i++ {
+			for j := 0; j <= i; j++ {
+				kFBR.Set(i, j, kFBR.At(i, j)+scale*d[i]*d[j])
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			kFBR.Set(j, i, kFBR.At(i, j))
+		}
+	}
+	return kFBR, nil
+}
+
+// newGaussDVR maps the rule y_k of f to x = center + scale y and transforms kFBR, the kinetic
+// matrix in y for unit mass, to the points; W gives the plain-integral weights w_k / W(y_k)
+func newGaussDVR(name string, n int, f orthoFamily, kFBR func() (*mat.Dense, error), W func(y float64) float64,
+	center, scale, mass float64) (*GaussDVR, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%s DVR needs at least one point, got %d", name, n)
+	}
+	if scale <= 0 || mass <= 0 {
+		return nil, fmt.Errorf("%s DVR needs a positive scale and mass, got %g and %g", name, scale, mass)
+	}
+
+	y, w, u, err := f.gaussRule(n)
+	if err != nil {
+		return nil, err
+	}
+	kinetic, err := kFBR()
+	if err != nil {
+		return nil, err
+	}
+
+	dvr := &GaussDVR{
+		name:    name,
+		points:  make([]float64, n),
+		weights: make([]float64, n),
+		kMat:    mat.NewDense(n, n, nil),
+	}
+	for k := range y {
+		dvr.points[k] = center + scale*y[k]
+		dvr.weights[k] = scale * w[k] / W(y[k])
+	}
+
+	var temp mat.Dense
+	temp.Mul(u.T(), kinetic)
+	dvr.kMat.Mul(&temp, u)
+	dvr.kMat.Scale(1/(mass*scale*scale), dvr.kMat)
+	return dvr, nil
+}
+
+// NewLegendreDVR is the Gauss-Legendre DVR on the finite interval [a, b]; the kinetic energy is
+// the quadratic form 1/2m Int |psi'|^2, so psi is free (not forced to vanish) at the ends
+func NewLegendreDVR(n int, a, b, mass float64) (*GaussDVR, error) {
+	if b <= a {
+		return nil, fmt.Errorf("Legendre DVR needs b > a, got [%g, %g]", a, b)
+	}
+	kFBR := func() (*mat.Dense, error) {
+		return legendreFamily.derivativeForm(n, legendreFamily,
+			func(float64) float64 { return 0 }, func(float64) float64 { return 1 })
+	}
+	return newGaussDVR("Legendre", n, legendreFamily, kFBR, func(float64) float64 { return 1 },
+		0.5*(a+b), 0.5*(b-a), mass)
+}
+
+// NewAngularDVR is the Gauss-Legendre DVR in x = cos(theta) for a rotor with moment of inertia
+// inertia, K = -1/2I d/dx (1 - x^2) d/dx, whose Legendre eigenvalues are l(l+1)/2I
+func NewAngularDVR(n int, inertia float64) (*GaussDVR, error) {
+	kFBR := func() (*mat.Dense, error) {
+		kinetic := mat.NewDense(n, n, nil)
+		for l := 0; l < n; l++ {
+			kinetic.Set(l, l, 0.5*float64(l*(l+1)))
+		}
+		return kinetic, nil
+	}
+	return newGaussDVR("angular", n, legendreFamily, kFBR, func(float64) float64 { return 1 }, 0, 1, inertia)
+}
+
+// NewLaguerreDVR is the radial DVR on [0, infinity) with r = scale y, built on the functions
+// y exp(-y/2) L_n^(2)(y), which vanish at the origin like a radial wavefunction
+func NewLaguerreDVR(n int, scale, mass float64) (*GaussDVR, error) {
+	family := laguerreFamily(2)
+	kFBR := func() (*mat.Dense, error) {
+		return family.derivativeForm(n, laguerreFamily(0),
+			func(y float64) float64 { return 1/y - 0.5 }, func(y float64) float64 { return y * y })
+	}
+	return newGaussDVR("Laguerre", n, family, kFBR, func(y float64) float64 { return y * y * math.Exp(-y) },
+		0, scale, mass)
+}
+
+// NewHermiteDVR is the DVR of the harmonic-oscillator functions centred on center, x = center + scale y;
+// scale = 1/sqrt(m omega) makes its basis the eigenstates of the oscillator of frequency omega
+func NewHermiteDVR(n int, center, scale, mass float64) (*GaussDVR, error) {
+	kFBR := func() (*mat.Dense, error) {
+		return hermiteFamily.derivativeForm(n, hermiteFamily,
+			func(y float64) float64 { return -y }, func(float64) float64 { return 1 })
+	}
+	return newGaussDVR("Hermite", n, hermiteFamily, kFBR, func(y float64) float64 { return math.Exp(-y * y) },
+		center, scale, mass)
+}
+
+func (g *GaussDVR) String() string {
+	return fmt.Sprintf("%s DVR{points: %d, range: [%g, %g]}", g.name, len(g.points), g.points[0], g.points[len(g.points)-1])
+}
+
+// Points returns the quadrature points in ascending order
+func (g *GaussDVR) Points() []float64 { return append([]float64(nil), g.points...) }
+
+// Weights returns w_k such that Sum_k w_k f(x_k) approximates Int f(x) dx, so that
+// psi(x_k) = c_k / sqrt(w_k) for the DVR coefficients c_k
+func (g *GaussDVR) Weights() []float64 { return append([]float64(nil), g.weights...) }
+
+// PotentialOnPoints evaluates the potential at the DVR points, its diagonal representation
+func (g *GaussDVR) PotentialOnPoints(pot gridData.PotentialOp[float64]) []float64 {
+	values := make([]float64, len(g.points))
+	for k, x := range g.points {
+		values[k] = pot.EvaluateAt(x)
+	}
+	return values
+}
+
+// GetMat returns the kinetic energy matrix on the points
+func (g *GaussDVR) GetMat() *mat.Dense { return g.kMat }
+
+// MulVec computes out = K in
+func (g *GaussDVR) MulVec(in, out []float64) {
+	outVec := mat.NewVecDense(len(out), out)
+	outVec.MulVec(g.kMat, mat.NewVecDense(len(in), in))
+}
+
+// RealDiagonalize diagonalizes the kinetic energy matrix
+func (g *GaussDVR) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	eigenvalues = make([]float64, len(g.points))
+	eigenvectors = mat.DenseCopyOf(g.kMat)
+	err = RealDiagonalizeLapack(eigenvectors, eigenvalues)
+	return eigenvalues, eigenvectors, err
+}
+
+// eigenDecomposition returns the eigenvalues and eigenvectors of K, using cache if available
+func (g *GaussDVR) eigenDecomposition() ([]float64, *mat.Dense, error) {
+	if !g.eigCached {
+		eigVals, eigVecs, err := g.RealDiagonalize()
+		if err != nil {
+			return nil, nil, err
+		}
+		g.eigVals = eigVals
+		g.eigVecs = eigVecs
+		g.eigCached = true
+	}
+	return g.eigVals, g.eigVecs, nil
+}
+
+// ExpDtTo computes exp(dt * K) In and stores the result in Out, which may alias In
+func (g *GaussDVR) ExpDtTo(dt float64, in []float64, out []float64) error {
+	if len(in) != len(g.points) || len(out) != len(g.points) {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), len(g.points))
+	}
+	eigVals, eigVecs, err := g.eigenDecomposition()
+	if err != nil {
+		return err
+	}
+	expDtEigen(eigVals, eigVecs, dt, in, out)
+	return nil
+}
+
+// ExpDtInPlace computes exp(dt * K) and applies it to input vector in-place
+func (g *GaussDVR) ExpDtInPlace(dt float64, inOut []float64) error {
+	return g.ExpDtTo(dt, inOut, inOut)
+}
+
+// ExpIdtTo computes exp(-i dt K) In and stores the result in Out, which may alias In
+func (g *GaussDVR) ExpIdtTo(dt float64, in []complex128, out []complex128) error {
+	if len(in) != len(g.points) || len(out) != len(g.points) {
+		return fmt.Errorf("vector lengths %d, %d don't match basis dimension %d", len(in), len(out), len(g.points))
+	}
+	eigVals, eigVecs, err := g.eigenDecomposition()
+	if err != nil {
+		return err
+	}
+	expIdtEigen(eigVals, eigVecs, dt, in, out)
+	return nil
+}
+
+// ExpIdtInPlace computes exp(-i dt K) and applies it to complex input vector in-place
+func (g *GaussDVR) ExpIdtInPlace(dt float64, inOut []complex128) error {
+	return g.ExpIdtTo(dt, inOut, inOut)
+}
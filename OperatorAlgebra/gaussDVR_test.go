@@ -0,0 +1,130 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// lowestLevels diagonalizes K + V on the DVR points
+func lowestLevels(t *testing.T, dvr DVRBasis, pot func(x float64) float64) []float64 {
+	t.Helper()
+	hamil := mat.DenseCopyOf(dvr.GetMat())
+	for k, x := range dvr.Points() {
+		hamil.Set(k, k, hamil.At(k, k)+pot(x))
+	}
+	n, _ := hamil.Dims()
+	evals := make([]float64, n)
+	if err := RealDiagonalizeLapack(hamil, evals); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return evals
+}
+
+func TestGaussDVR_Levels(t *testing.T) {
+	harmonic := func(x float64) float64 { return 0.5 * x * x }
+	hermite, err := NewHermiteDVR(30, 0, 1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	laguerre, err := NewLaguerreDVR(60, 0.25, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	legendre, err := NewLegendreDVR(30, 0, math.Pi, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	angular, err := NewAngularDVR(12, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		dvr      DVRBasis
+		pot      func(x float64) float64
+		expected []float64
+	}{
+		// the oscillator, its odd states on the half line, a free particle with free ends on [0, pi]
+		"Hermite":  {hermite, harmonic, []float64{0.5, 1.5, 2.5, 3.5}},
+		"Laguerre": {laguerre, harmonic, []float64{1.5, 3.5, 5.5, 7.5}},
+		"Legendre": {legendre, func(float64) float64 { return 0 }, []float64{0, 0.5, 2, 4.5}},
+		"angular":  {angular, func(float64) float64 { return 0 }, []float64{0, 0.5, 1.5, 3}},
+	}
+	for name, c := range cases {
+		evals := lowestLevels(t, c.dvr, c.pot)
+		for n, e := range c.expected {
+			if math.Abs(evals[n]-e) > 1e-8 {
+				t.Errorf("%s: expected E%d = %v, got %v", name, n, e, evals[n])
+			}
+		}
+	}
+
+	// the weights integrate over the points: Int_0^pi sin(x) dx = 2 and Int exp(-x^2) dx = sqrt(pi)
+	integrals := map[string]struct {
+		dvr      DVRBasis
+		f        func(x float64) float64
+		expected float64
+	}{
+		"Legendre": {legendre, math.Sin, 2},
+		"Hermite":  {hermite, func(x float64) float64 { return math.Exp(-x * x) }, math.Sqrt(math.Pi)},
+		"Laguerre": {laguerre, func(x float64) float64 { return x * x * math.Exp(-x) }, 2},
+	}
+	for name, c := range integrals {
+		sum := 0.
+		weights := c.dvr.Weights()
+		for k, x := range c.dvr.Points() {
+			sum += weights[k] * c.f(x)
+		}
+		if math.Abs(sum-c.expected) > 1e-8 {
+			t.Errorf("%s: expected quadrature %v, got %v", name, c.expected, sum)
+		}
+	}
+
+	if _, err := NewLegendreDVR(10, 1, 0, 1); err == nil {
+		t.Errorf("expected an error for an empty interval")
+	}
+}
+
+func TestKeDVR_Domains(t *testing.T) {
+	radial, err := gridData.NewRadialGrid(8., 80)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	half, err := NewKeDVRDomain(radial, 1., HalfLine)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	evals := lowestLevels(t, half, func(x float64) float64 { return 0.5 * x * x })
+	for n, e := range []float64{1.5, 3.5, 5.5} {
+		if math.Abs(evals[n]-e) > 1e-8 {
+			t.Errorf("half line: expected E%d = %v, got %v", n, e, evals[n])
+		}
+	}
+	line, err := gridData.NewRGrid(-1, 1, 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewKeDVRDomain(line, 1., HalfLine); err == nil {
+		t.Errorf("expected an error for a half-line DVR on a grid starting at -1")
+	}
+
+	// the periodic DVR is the Fourier-grid Hamiltonian, for even and odd numbers of points
+	for _, n := range []uint32{16, 17} {
+		grid, err := gridData.NewFromLength(10., n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ring, err := NewKeDVRDomain(grid, 2., Ring)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fourier := FFTInit(grid, 2.)
+		if !mat.EqualApprox(ring.GetMat(), fourier.GetMat(), 1e-10) {
+			t.Errorf("%d points: periodic DVR differs from the Fourier-grid matrix", n)
+		}
+		fourier.Close()
+	}
+}
@@ -0,0 +1,248 @@
+package OperatorAlgebra
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// MatVecFunc applies a real symmetric operator to a vector: out = A in
+type MatVecFunc func(in, out []float64)
+
+// MatVecOp is implemented by operators that can be applied without forming their dense matrix
+type MatVecOp interface {
+	MulVec(in, out []float64)
+}
+
+// IterativeEigenSolver finds the lowest eigenpairs of a large real symmetric operator
+// known only through its matrix-vector product. Without a diagonal it runs a
+// thick-restart Lanczos iteration, with a diagonal it runs Davidson with the
+// diagonal preconditioner.
+type IterativeEigenSolver struct {
+	dim      int
+	matVec   MatVecFunc
+	diagonal []float64
+
+	MaxBasis  int
+	MaxIter   int
+	Tolerance float64
+
+	basis   [][]float64
+	aBasis  [][]float64
+	projMat *mat.Dense
+	nMatVec int
+}
+
+// NewLanczos creates a restarted Lanczos solver for an operator of dimension dim
+func NewLanczos(dim int, matVec MatVecFunc) *IterativeEigenSolver {
+	return &IterativeEigenSolver{
+		dim:       dim,
+		matVec:    matVec,
+		MaxIter:   1000,
+		Tolerance: 1e-8,
+	}
+}
+
+// NewDavidson creates a Davidson solver preconditioned with the diagonal of the operator
+func NewDavidson(dim int, matVec MatVecFunc, diagonal []float64) *IterativeEigenSolver {
+	return &IterativeEigenSolver{
+		dim:       dim,
+		matVec:    matVec,
+		diagonal:  diagonal,
+		MaxIter:   1000,
+		Tolerance: 1e-8,
+	}
+}
+
+// NMatVec counts the matrix-vector products done by the last call to LowestEigen
+func (s *IterativeEigenSolver) NMatVec() int { return s.nMatVec }
+
+// LowestEigen returns the nEig lowest eigenvalues in ascending order and the
+// corresponding orthonormal eigenvectors stored as columns
+func (s *IterativeEigenSolver) LowestEigen(nEig int) (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	if nEig <= 0 || nEig > s.dim {
+		return nil, nil, fmt.Errorf("number of eigenpairs must be in [1, %d], got %d", s.dim, nEig)
+	}
+	if s.diagonal != nil && len(s.diagonal) != s.dim {
+		return nil, nil, fmt.Errorf("diagonal length %d doesn't match operator dimension %d", len(s.diagonal), s.dim)
+	}
+
+	maxBasis := s.MaxBasis
+	if maxBasis <= 0 {
+		maxBasis = max(3*nEig, nEig+20)
+	}
+	maxBasis = min(maxBasis, s.dim)
+	if maxBasis < min(nEig+2, s.dim) {
+		return nil, nil, fmt.Errorf("maximum basis size %d too small for %d eigenpairs", maxBasis, nEig)
+	}
+	nKeep := max(nEig, min(2*nEig, maxBasis-nEig))
+	nKeep = min(nKeep, maxBasis-1)
+
+	s.basis = make([][]float64, 0, maxBasis)
+	s.aBasis = make([][]float64, 0, maxBasis)
+	s.projMat = mat.NewDense(maxBasis, maxBasis, nil)
+	s.nMatVec = 0
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	start := make([]float64, s.dim)
+	for i := range start {
+		start[i] = rng.NormFloat64()
+	}
+	s.addVector(start)
+
+	ritzVecs := make([][]float64, nEig)
+	residuals := make([][]float64, nEig)
+	for j := range ritzVecs {
+		ritzVecs[j] = make([]float64, s.dim)
+		residuals[j] = make([]float64, s.dim)
+	}
+
+	for iter := 0; iter < s.MaxIter; iter++ {
+		nBasis := len(s.basis)
+		theta, coeffs, err := s.rayleighRitz(nBasis)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		nFound := min(nEig, nBasis)
+		unconverged := -1
+		for j := 0; j < nFound; j++ {
+			s.ritzPair(coeffs, j, theta[j], ritzVecs[j], residuals[j])
+			if unconverged < 0 && blas64.Nrm2(blasVec(residuals[j])) > s.Tolerance {
+				unconverged = j
+			}
+		}
+
+		if nFound == nEig && unconverged < 0 {
+			eigenvectors = mat.NewDense(s.dim, nEig, nil)
+			for j := 0; j < nEig; j++ {
+				eigenvectors.SetCol(j, ritzVecs[j])
+			}
+			return theta[:nEig], eigenvectors, nil
+		}
+		if unconverged < 0 {
+			unconverged = nFound - 1
+		}
+
+		correction := s.correction(residuals[unconverged], theta[unconverged])
+
+		if nBasis == maxBasis {
+			s.restart(coeffs, theta, nKeep)
+		}
+
+		if !s.addVector(correction) {
+			for i := range start {
+				start[i] = rng.NormFloat64()
+			}
+			if !s.addVector(start) {
+				return nil, nil, fmt.Errorf("could not extend the search space beyond %d vectors", len(s.basis))
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("eigensolver did not converge after %d iterations", s.MaxIter)
+}
+
+func blasVec(data []float64) blas64.Vector {
+	return blas64.Vector{N: len(data), Data: data, Inc: 1}
+}
+
+// addVector orthonormalizes v against the search space (twice, for stability)
+// and appends it together with its image under the operator
+func (s *IterativeEigenSolver) addVector(v []float64) bool {
+	v = append([]float64(nil), v...)
+	norm0 := blas64.Nrm2(blasVec(v))
+	if norm0 == 0 {
+		return false
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		for _, b := range s.basis {
+			blas64.Axpy(-blas64.Dot(blasVec(b), blasVec(v)), blasVec(b), blasVec(v))
+		}
+	}
+
+	norm := blas64.Nrm2(blasVec(v))
+	if norm < 1e-10*norm0 {
+		return false
+	}
+	blas64.Scal(1/norm, blasVec(v))
+
+	av := make([]float64, s.dim)
+	s.matVec(v, av)
+	s.nMatVec++
+
+	n := len(s.basis)
+	s.basis = append(s.basis, v)
+	s.aBasis = append(s.aBasis, av)
+	for i := 0; i <= n; i++ {
+		hij := blas64.Dot(blasVec(s.basis[i]), blasVec(av))
+		s.projMat.Set(i, n, hij)
+		s.projMat.Set(n, i, hij)
+	}
+	return true
+}
+
+// rayleighRitz diagonalizes the operator projected onto the current search space
+func (s *IterativeEigenSolver) rayleighRitz(nBasis int) ([]float64, *mat.Dense, error) {
+	theta := make([]float64, nBasis)
+	coeffs := mat.DenseCopyOf(s.projMat.Slice(0, nBasis, 0, nBasis))
+	if err := RealDiagonalizeLapack(coeffs, theta); err != nil {
+		return nil, nil, err
+	}
+	return theta, coeffs, nil
+}
+
+// ritzPair computes the j-th Ritz vector x = V y and its residual r = A x - theta x
+func (s *IterativeEigenSolver) ritzPair(coeffs *mat.Dense, j int, theta float64, x, r []float64) {
+	clear(x)
+	clear(r)
+	for i := range s.basis {
+		c := coeffs.At(i, j)
+		blas64.Axpy(c, blasVec(s.basis[i]), blasVec(x))
+		blas64.Axpy(c, blasVec(s.aBasis[i]), blasVec(r))
+	}
+	blas64.Axpy(-theta, blasVec(x), blasVec(r))
+}
+
+// correction returns the next expansion direction. For Lanczos the residual is
+// parallel to the next Krylov vector; Davidson applies (D - theta)^-1.
+func (s *IterativeEigenSolver) correction(residual []float64, theta float64) []float64 {
+	t := append([]float64(nil), residual...)
+	if s.diagonal == nil {
+		return t
+	}
+	for i := range t {
+		denom := s.diagonal[i] - theta
+		if math.Abs(denom) < 1e-8 {
+			denom = math.Copysign(1e-8, denom)
+		}
+		t[i] /= denom
+	}
+	return t
+}
+
+// restart collapses the search space onto the nKeep lowest Ritz vectors
+func (s *IterativeEigenSolver) restart(coeffs *mat.Dense, theta []float64, nKeep int) {
+	newBasis := make([][]float64, nKeep)
+	newABasis := make([][]float64, nKeep)
+	for j := 0; j < nKeep; j++ {
+		newBasis[j] = make([]float64, s.dim)
+		newABasis[j] = make([]float64, s.dim)
+		for i := range s.basis {
+			c := coeffs.At(i, j)
+			blas64.Axpy(c, blasVec(s.basis[i]), blasVec(newBasis[j]))
+			blas64.Axpy(c, blasVec(s.aBasis[i]), blasVec(newABasis[j]))
+		}
+	}
+
+	s.basis = append(s.basis[:0], newBasis...)
+	s.aBasis = append(s.aBasis[:0], newABasis...)
+	s.projMat.Zero()
+	for j := 0; j < nKeep; j++ {
+		s.projMat.Set(j, j, theta[j])
+	}
+}
@@ -0,0 +1,239 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// KroneckerKinetic is the kinetic energy on a product grid, T = Sum_i 1 x ... x T_i x ... x 1.
+// Every term acts on the one-dimensional fibres of its axis, so the N^d x N^d matrix is
+// only formed when GetMat or RealDiagonalize is called. The fibre buffers are shared scratch
+// space, so unlike FourierBasis one KroneckerKinetic must not be used from several goroutines
+// at once; give each goroutine its own operator.
+type KroneckerKinetic struct {
+	grid *gridData.ProductGrid
+	axes []KineticOp
+
+	axisMats []*mat.Dense
+	fibreIn  [][]float64
+	fibreOut [][]float64
+	fibreZ   [][]complex128
+	fibreZO  [][]complex128
+}
+
+// NewKroneckerKinetic combines one kinetic operator per axis of the product grid
+func NewKroneckerKinetic(grid *gridData.ProductGrid, axes ...KineticOp) (*KroneckerKinetic, error) {
+	if len(axes) != grid.NDims() {
+		return nil, fmt.Errorf("got %d kinetic operators for a %d-dimensional grid", len(axes), grid.NDims())
+	}
+
+	shape := grid.Shape()
+	k := &KroneckerKinetic{
+		grid:     grid,
+		axes:     axes,
+		axisMats: make([]*mat.Dense, len(axes)),
+		fibreIn:  make([][]float64, len(axes)),
+		fibreOut: make([][]float64, len(axes)),
+		fibreZ:   make([][]complex128, len(axes)),
+		fibreZO:  make([][]complex128, len(axes)),
+	}
+	for i, axis := range axes {
+		rows, cols := axis.GetMat().Dims()
+		if rows != shape[i] || cols != shape[i] {
+			return nil, fmt.Errorf("kinetic matrix %dx%d doesn't match %d points on axis %d", rows, cols, shape[i], i)
+		}
+		k.fibreIn[i] = make([]float64, shape[i])
+		k.fibreOut[i] = make([]float64, shape[i])
+		k.fibreZ[i] = make([]complex128, shape[i])
+		k.fibreZO[i] = make([]complex128, shape[i])
+	}
+	return k, nil
+}
+
+// NewKroneckerKeDVR uses the sinc-DVR kinetic energy on every axis, with one mass per axis
+func NewKroneckerKeDVR(grid *gridData.ProductGrid, masses ...float64) (*KroneckerKinetic, error) {
+	if len(masses) != grid.NDims() {
+		return nil, fmt.Errorf("got %d masses for a %d-dimensional grid", len(masses), grid.NDims())
+	}
+	axes := make([]KineticOp, len(masses))
+	for i, mass := range masses {
+		axes[i] = NewKeDVR(grid.Axis(i), mass)
+	}
+	return NewKroneckerKinetic(grid, axes...)
+}
+
+func (k *KroneckerKinetic) Grid() *gridData.ProductGrid { return k.grid }
+func (k *KroneckerKinetic) Axis(i int) KineticOp        { return k.axes[i] }
+
+// forEachFibre calls f with the offset of every line of points along axis
+func (k *KroneckerKinetic) forEachFibre(axis int, f func(offset, stride int)) {
+	shape := k.grid.Shape()
+	stride := k.grid.Strides()[axis]
+	outer := k.grid.NPoints() / (shape[axis] * stride)
+	for o := 0; o < outer; o++ {
+		for inner := 0; inner < stride; inner++ {
+			f(o*shape[axis]*stride+inner, stride)
+		}
+	}
+}
+
+// axisMulVec applies T_i to a single fibre
+func (k *KroneckerKinetic) axisMulVec(axis int, in, out []float64) {
+	if op, ok := k.axes[axis].(MatVecOp); ok {
+		op.MulVec(in, out)
+		return
+	}
+	if k.axisMats[axis] == nil {
+		k.axisMats[axis] = k.axes[axis].GetMat()
+	}
+	outVec := mat.NewVecDense(len(out), out)
+	outVec.MulVec(k.axisMats[axis], mat.NewVecDense(len(in), in))
+}
+
+// MulVec applies the kinetic energy, out = T in, one axis at a time
+func (k *KroneckerKinetic) MulVec(in, out []float64) {
+	clear(out)
+	for axis := range k.axes {
+		fibreIn, fibreOut := k.fibreIn[axis], k.fibreOut[axis]
+		k.forEachFibre(axis, func(offset, stride int) {
+			for j := range fibreIn {
+				fibreIn[j] = in[offset+j*stride]
+			}
+			k.axisMulVec(axis, fibreIn, fibreOut)
+			for j, v := range fibreOut {
+				out[offset+j*stride] += v
+			}
+		})
+	}
+}
+
+// GetMat forms the full kinetic matrix; only sensible for small grids
+func (k *KroneckerKinetic) GetMat() *mat.Dense {
+	n := k.grid.NPoints()
+	kMat := mat.NewDense(n, n, nil)
+	unit := make([]float64, n)
+	column := make([]float64, n)
+	for j := 0; j < n; j++ {
+		unit[j] = 1
+		k.MulVec(unit, column)
+		kMat.SetCol(j, column)
+		unit[j] = 0
+	}
+	return kMat
+}
+
+// RealDiagonalize builds the eigenpairs from those of the axes: the eigenvalues are
+// sums of axis eigenvalues and the eigenvectors Kronecker products of axis eigenvectors
+func (k *KroneckerKinetic) RealDiagonalize() (eigenvalues []float64, eigenvectors *mat.Dense, err error) {
+	axisVals := make([][]float64, len(k.axes))
+	axisVecs := make([]*mat.Dense, len(k.axes))
+	for i, axis := range k.axes {
+		axisVals[i], axisVecs[i], err = axis.RealDiagonalize()
+		if err != nil {
+			return nil, nil, fmt.Errorf("axis %d: %w", i, err)
+		}
+	}
+
+	n := k.grid.NPoints()
+	eigenvalues = make([]float64, n)
+	idx := make([]int, len(k.axes))
+	for flat := range eigenvalues {
+		k.grid.MultiIndex(flat, idx)
+		for i, j := range idx {
+			eigenvalues[flat] += axisVals[i][j]
+		}
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return eigenvalues[order[a]] < eigenvalues[order[b]] })
+
+	sorted := make([]float64, n)
+	eigenvectors = mat.NewDense(n, n, nil)
+	pointIdx := make([]int, len(k.axes))
+	for col, state := range order {
+		sorted[col] = eigenvalues[state]
+		k.grid.MultiIndex(state, idx)
+		for row := 0; row < n; row++ {
+			k.grid.MultiIndex(row, pointIdx)
+			v := 1.
+			for i := range k.axes {
+				v *= axisVecs[i].At(pointIdx[i], idx[i])
+			}
+			eigenvectors.Set(row, col, v)
+		}
+	}
+	return sorted, eigenvectors, nil
+}
+
+// ExpDtTo computes exp(dt T) in = Prod_i exp(dt T_i) in, the axis terms commuting
+func (k *KroneckerKinetic) ExpDtTo(dt float64, in []float64, out []float64) error {
+	if len(in) != k.grid.NPoints() || len(out) != len(in) {
+		return fmt.Errorf("vector lengths %d and %d don't match grid size %d", len(in), len(out), k.grid.NPoints())
+	}
+	copy(out, in)
+	for axis, op := range k.axes {
+		fibreIn, fibreOut := k.fibreIn[axis], k.fibreOut[axis]
+		var err error
+		k.forEachFibre(axis, func(offset, stride int) {
+			if err != nil {
+				return
+			}
+			for j := range fibreIn {
+				fibreIn[j] = out[offset+j*stride]
+			}
+			if err = op.ExpDtTo(dt, fibreIn, fibreOut); err != nil {
+				return
+			}
+			for j, v := range fibreOut {
+				out[offset+j*stride] = v
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("axis %d: %w", axis, err)
+		}
+	}
+	return nil
+}
+
+func (k *KroneckerKinetic) ExpDtInPlace(dt float64, inOut []float64) error {
+	return k.ExpDtTo(dt, inOut, inOut)
+}
+
+// ExpIdtTo computes exp(-i dt T) in = Prod_i exp(-i dt T_i) in
+func (k *KroneckerKinetic) ExpIdtTo(dt float64, in []complex128, out []complex128) error {
+	if len(in) != k.grid.NPoints() || len(out) != len(in) {
+		return fmt.Errorf("vector lengths %d and %d don't match grid size %d", len(in), len(out), k.grid.NPoints())
+	}
+	copy(out, in)
+	for axis, op := range k.axes {
+		fibreIn, fibreOut := k.fibreZ[axis], k.fibreZO[axis]
+		var err error
+		k.forEachFibre(axis, func(offset, stride int) {
+			if err != nil {
+				return
+			}
+			for j := range fibreIn {
+				fibreIn[j] = out[offset+j*stride]
+			}
+			if err = op.ExpIdtTo(dt, fibreIn, fibreOut); err != nil {
+				return
+			}
+			for j, v := range fibreOut {
+				out[offset+j*stride] = v
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("axis %d: %w", axis, err)
+		}
+	}
+	return nil
+}
+
+func (k *KroneckerKinetic) ExpIdtInPlace(dt float64, inOut []complex128) error {
+	return k.ExpIdtTo(dt, inOut, inOut)
+}
@@ -0,0 +1,130 @@
+package OperatorAlgebra
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestKroneckerKinetic_MulVec(t *testing.T) {
+	xGrid, err := gridData.NewFromLength(6., 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yGrid, err := gridData.NewFromLength(8., 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := gridData.NewProductGrid(xGrid, yGrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinE, err := NewKroneckerKeDVR(grid, 1., 2.)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// T = Tx (x) 1 + 1 (x) Ty with the last axis running fastest
+	tx, ty := kinE.Axis(0).GetMat(), kinE.Axis(1).GetMat()
+	expected := mat.NewDense(20, 20, nil)
+	idI, idJ := make([]int, 2), make([]int, 2)
+	for i := 0; i < 20; i++ {
+		grid.MultiIndex(i, idI)
+		for j := 0; j < 20; j++ {
+			grid.MultiIndex(j, idJ)
+			v := 0.
+			if idI[1] == idJ[1] {
+				v += tx.At(idI[0], idJ[0])
+			}
+			if idI[0] == idJ[0] {
+				v += ty.At(idI[1], idJ[1])
+			}
+			expected.Set(i, j, v)
+		}
+	}
+	if !mat.EqualApprox(kinE.GetMat(), expected, 1e-12) {
+		t.Errorf("Kronecker-sum matrix doesn't match Tx (x) 1 + 1 (x) Ty")
+	}
+
+	eigenvalues, eigenvectors, err := kinE.RealDiagonalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for n := range eigenvalues {
+		vec := mat.NewVecDense(20, nil)
+		vec.MulVec(expected, eigenvectors.ColView(n))
+		vec.AddScaledVec(vec, -eigenvalues[n], eigenvectors.ColView(n))
+		if vec.Norm(2) > 1e-10 || (n > 0 && eigenvalues[n] < eigenvalues[n-1]) {
+			t.Errorf("eigenpair %d is wrong or out of order: residual %v", n, vec.Norm(2))
+		}
+	}
+
+	psi := make([]complex128, 20)
+	for i := range psi {
+		psi[i] = complex(math.Sin(float64(i)), math.Cos(float64(2*i)))
+	}
+	out := make([]complex128, 20)
+	if err := kinE.ExpIdtTo(0.3, psi, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// exp(-i dt T) from the eigendecomposition of the full matrix
+	for i := range psi {
+		var ref complex128
+		for n := range eigenvalues {
+			var overlap complex128
+			for j := range psi {
+				overlap += complex(eigenvectors.At(j, n), 0) * psi[j]
+			}
+			ref += cmplx.Exp(complex(0, -0.3*eigenvalues[n])) * overlap * complex(eigenvectors.At(i, n), 0)
+		}
+		if cmplx.Abs(out[i]-ref) > 1e-10 {
+			t.Errorf("expected exp(-i dt T) component %d = %v, got %v", i, ref, out[i])
+		}
+	}
+}
+
+func TestKroneckerKinetic_Davidson(t *testing.T) {
+	axis, err := gridData.NewFromLength(14., 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := gridData.NewProductGrid(axis, axis)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinE, err := NewKroneckerKeDVR(grid, 1., 1.)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pot := gridData.Separable[float64]{Terms: []gridData.PotentialOp[float64]{
+		gridData.Harmonic[float64]{ForceConst: 1.}, gridData.Harmonic[float64]{ForceConst: 1.},
+	}}
+	vPot := grid.PotentialOnGrid(pot)
+
+	kinMat := kinE.Axis(0).GetMat()
+	diagonal := make([]float64, grid.NPoints())
+	for i, v := range vPot {
+		diagonal[i] = 2*kinMat.At(0, 0) + v
+	}
+
+	// Davidson, unlike single-vector Lanczos, resolves the degenerate levels
+	solver := NewDavidson(grid.NPoints(), func(in, out []float64) {
+		kinE.MulVec(in, out)
+		for i, v := range vPot {
+			out[i] += v * in[i]
+		}
+	}, diagonal)
+	solver.Tolerance = 1e-7
+	energies, _, err := solver.LowestEigen(6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for n, expected := range []float64{1, 2, 2, 3, 3, 3} {
+		if math.Abs(energies[n]-expected) > 1e-6 {
+			t.Errorf("expected E%d = %v, got %v", n, expected, energies[n])
+		}
+	}
+}
@@ -15,7 +15,8 @@ type zomplex interface {
 		rwork []float64) (first int)
 }
 
-var zomplex64 zomplex
+// zomplex64 is the complex general eigensolver used by ZGeev
+var zomplex64 zomplex = zgeevGo{}
 
 func ZGeev(jobvl lapack.LeftEVJob, jobvr lapack.RightEVJob, a mat.CMatrix, w []complex128, vl,
 	vr []complex128) (first int) {
@@ -39,18 +40,16 @@ func dsyev(n int, a blas64.Symmetric, evals []float64, eVecs blas64.General) err
 	work := make([]float64, 1)
 	lwork := -1
 
-	ok := lapack64.Syev(lapack.EVCompute, a, w, work, lwork)
-	if !ok {
-		return fmt.Errorf("LAPACK Syev query failed")
-	}
+	// the workspace query only fills work[0] and always reports false
+	lapack64.Syev(lapack.EVCompute, a, w, work, lwork)
 
 	optimalLwork := int(work[0])
 	work = make([]float64, optimalLwork)
 	lwork = optimalLwork
 
-	ok = lapack64.Syev(lapack.EVCompute, a, evals, work, lwork)
+	ok := lapack64.Syev(lapack.EVCompute, a, evals, work, lwork)
 	if !ok {
-		err := fmt.Errorf("LAPACK Syev query failed")
+		err := fmt.Errorf("LAPACK Syev failed to converge")
 		return err
 	}
 
@@ -1,18 +1,33 @@
 package OperatorAlgebra
 
 import (
+	"fmt"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/lapack"
 	"gonum.org/v1/gonum/mat"
 )
 
-func ComplexGenDiagonalization(a mat.CMatrix, values []complex128, vectorsL mat.CMatrix, vectorsR mat.CMatrix) {
-	ZGeev(lapack.LeftEVCompute, lapack.RightEVCompute, a, values, vectorsL.(*mat.CDense).RawCMatrix().Data,
+// ComplexGenDiagonalization diagonalizes a general complex matrix, which is overwritten.
+// The left and right eigenvectors are returned as unit-norm columns of vectorsL and vectorsR.
+func ComplexGenDiagonalization(a mat.CMatrix, values []complex128, vectorsL mat.CMatrix, vectorsR mat.CMatrix) error {
+	first := ZGeev(lapack.LeftEVCompute, lapack.RightEVCompute, a, values, vectorsL.(*mat.CDense).RawCMatrix().Data,
 		vectorsR.(*mat.CDense).RawCMatrix().Data)
+	if first > 0 {
+		return fmt.Errorf("complex QR iteration failed to converge, only eigenvalues %d to %d are valid",
+			first, len(values)-1)
+	}
+	return nil
 }
 
+// RealDiagonalizeLapack diagonalizes the real symmetric matrix held in eVecs.
+// On return eVecs holds the orthonormal eigenvectors as columns and evals the
+// eigenvalues in ascending order.
 func RealDiagonalizeLapack(eVecs mat.Matrix, evals []float64) error {
-	err := dsyev(eVecs.(*mat.Dense).RawMatrix().Rows, eVecs.(*mat.SymDense).RawSymmetric(),
-		evals, eVecs.(*mat.Dense).RawMatrix())
+	raw := eVecs.(*mat.Dense).RawMatrix()
+	sym := blas64.Symmetric{N: raw.Rows, Stride: raw.Stride, Data: raw.Data, Uplo: blas.Upper}
+	err := dsyev(raw.Rows, sym, evals, raw)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,282 @@
+package OperatorAlgebra
+
+import (
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/lapack"
+)
+
+// zgeevGo is a pure-Go complex general eigensolver following the zgeev contract:
+// Householder reduction to Hessenberg form, single-shift complex QR iteration to
+// the Schur form A = Q T Q^H, and eigenvectors by back-substitution on T.
+// Matrices are row-major and eigenvectors are returned as unit-norm columns.
+type zgeevGo struct{}
+
+const zgeevMaxSweeps = 30
+
+func (zgeevGo) Zgeev(jobvl lapack.LeftEVJob, jobvr lapack.RightEVJob, n int, a []complex128, lda int,
+	w []complex128, vl []complex128, ldvl int, vr []complex128, ldvr int, work []complex128, lwork int,
+	rwork []float64) (first int) {
+	if n == 0 {
+		return 0
+	}
+
+	h := make([]complex128, n*n)
+	for i := 0; i < n; i++ {
+		copy(h[i*n:(i+1)*n], a[i*lda:i*lda+n])
+	}
+	q := make([]complex128, n*n)
+	for i := 0; i < n; i++ {
+		q[i*n+i] = 1
+	}
+
+	hessenbergReduce(n, h, q)
+	if info := schurQR(n, h, q); info > 0 {
+		for i := info; i < n; i++ {
+			w[i] = h[i*n+i]
+		}
+		return info
+	}
+
+	for i := 0; i < n; i++ {
+		w[i] = h[i*n+i]
+	}
+
+	if jobvr == lapack.RightEVCompute {
+		schurRightVectors(n, h, q, vr, ldvr)
+	}
+	if jobvl == lapack.LeftEVCompute {
+		schurLeftVectors(n, h, q, vl, ldvl)
+	}
+	for i := 0; i < n; i++ {
+		copy(a[i*lda:i*lda+n], h[i*n:(i+1)*n])
+	}
+	return 0
+}
+
+// hessenbergReduce overwrites h with H = P^H A P in upper Hessenberg form and q with q P
+func hessenbergReduce(n int, h, q []complex128) {
+	v := make([]complex128, n)
+	for k := 0; k < n-2; k++ {
+		xNorm := 0.
+		for i := k + 1; i < n; i++ {
+			xNorm = math.Hypot(xNorm, cmplx.Abs(h[i*n+k]))
+		}
+		if xNorm == 0 {
+			continue
+		}
+
+		x0 := h[(k+1)*n+k]
+		phase := complex(1, 0)
+		if x0 != 0 {
+			phase = x0 / complex(cmplx.Abs(x0), 0)
+		}
+		alpha := -phase * complex(xNorm, 0)
+
+		vNorm := 0.
+		for i := k + 1; i < n; i++ {
+			v[i] = h[i*n+k]
+			if i == k+1 {
+				v[i] -= alpha
+			}
+			vNorm = math.Hypot(vNorm, cmplx.Abs(v[i]))
+		}
+		for i := k + 1; i < n; i++ {
+			v[i] /= complex(vNorm, 0)
+		}
+
+		// h <- (I - 2 v v^H) h
+		for j := k; j < n; j++ {
+			var s complex128
+			for i := k + 1; i < n; i++ {
+				s += cmplx.Conj(v[i]) * h[i*n+j]
+			}
+			for i := k + 1; i < n; i++ {
+				h[i*n+j] -= 2 * v[i] * s
+			}
+		}
+		// h <- h (I - 2 v v^H) and q <- q (I - 2 v v^H)
+		for _, m := range [][]complex128{h, q} {
+			for i := 0; i < n; i++ {
+				var s complex128
+				for j := k + 1; j < n; j++ {
+					s += m[i*n+j] * v[j]
+				}
+				for j := k + 1; j < n; j++ {
+					m[i*n+j] -= 2 * s * cmplx.Conj(v[j])
+				}
+			}
+		}
+		for i := k + 2; i < n; i++ {
+			h[i*n+k] = 0
+		}
+	}
+}
+
+// givens returns c, s with [c s; -conj(s) c] [f; g] = [r; 0]
+func givens(f, g complex128) (float64, complex128) {
+	if g == 0 {
+		return 1, 0
+	}
+	if f == 0 {
+		return 0, 1
+	}
+	absF := cmplx.Abs(f)
+	norm := math.Hypot(absF, cmplx.Abs(g))
+	return absF / norm, (f / complex(absF, 0)) * cmplx.Conj(g) / complex(norm, 0)
+}
+
+// schurQR reduces the Hessenberg matrix h to upper triangular Schur form with
+// Wilkinson-shifted QR sweeps, accumulating the unitary transformations into q.
+// It returns 0 on success or hi+1 when the eigenvalue at index hi failed to converge.
+func schurQR(n int, h, q []complex128) int {
+	cs := make([]float64, n)
+	sn := make([]complex128, n)
+	eps := math.Nextafter(1, 2) - 1
+
+	hi := n - 1
+	iter := 0
+	for hi > 0 {
+		lo := hi
+		for ; lo > 0; lo-- {
+			scale := cmplx.Abs(h[(lo-1)*n+lo-1]) + cmplx.Abs(h[lo*n+lo])
+			if cmplx.Abs(h[lo*n+lo-1]) <= eps*scale {
+				h[lo*n+lo-1] = 0
+				break
+			}
+		}
+		if lo == hi {
+			hi--
+			iter = 0
+			continue
+		}
+
+		iter++
+		if iter > zgeevMaxSweeps*n {
+			return hi + 1
+		}
+
+		var shift complex128
+		if iter%10 == 0 {
+			shift = h[hi*n+hi] + complex(cmplx.Abs(h[hi*n+hi-1]), 0)
+		} else {
+			a, b := h[(hi-1)*n+hi-1], h[(hi-1)*n+hi]
+			c, d := h[hi*n+hi-1], h[hi*n+hi]
+			half := (a - d) / 2
+			disc := cmplx.Sqrt(half*half + b*c)
+			mu1, mu2 := (a+d)/2+disc, (a+d)/2-disc
+			shift = mu1
+			if cmplx.Abs(mu2-d) < cmplx.Abs(mu1-d) {
+				shift = mu2
+			}
+		}
+
+		for k := lo; k <= hi; k++ {
+			h[k*n+k] -= shift
+		}
+
+		for k := lo; k < hi; k++ {
+			c, s := givens(h[k*n+k], h[(k+1)*n+k])
+			cs[k], sn[k] = c, s
+			for j := k; j < n; j++ {
+				x, y := h[k*n+j], h[(k+1)*n+j]
+				h[k*n+j] = complex(c, 0)*x + s*y
+				h[(k+1)*n+j] = -cmplx.Conj(s)*x + complex(c, 0)*y
+			}
+		}
+
+		for k := lo; k < hi; k++ {
+			c, s := complex(cs[k], 0), sn[k]
+			for i := 0; i <= min(k+1, hi); i++ {
+				x, y := h[i*n+k], h[i*n+k+1]
+				h[i*n+k] = x*c + y*cmplx.Conj(s)
+				h[i*n+k+1] = -x*s + y*c
+			}
+			for i := 0; i < n; i++ {
+				x, y := q[i*n+k], q[i*n+k+1]
+				q[i*n+k] = x*c + y*cmplx.Conj(s)
+				q[i*n+k+1] = -x*s + y*c
+			}
+		}
+
+		for k := lo; k <= hi; k++ {
+			h[k*n+k] += shift
+		}
+	}
+	return 0
+}
+
+// schurNorm returns a scale for guarding near-zero denominators in the back-substitution
+func schurNorm(n int, t []complex128) float64 {
+	norm := 0.
+	for _, v := range t {
+		norm = max(norm, cmplx.Abs(v))
+	}
+	return math.Max(norm, 1) * (math.Nextafter(1, 2) - 1)
+}
+
+// schurRightVectors computes the right eigenvectors Q x of A from T x = lambda x
+func schurRightVectors(n int, t, q, vr []complex128, ldvr int) {
+	small := schurNorm(n, t)
+	x := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		lambda := t[k*n+k]
+		clear(x)
+		x[k] = 1
+		for j := k - 1; j >= 0; j-- {
+			var s complex128
+			for m := j + 1; m <= k; m++ {
+				s += t[j*n+m] * x[m]
+			}
+			denom := t[j*n+j] - lambda
+			if cmplx.Abs(denom) < small {
+				denom = complex(small, 0)
+			}
+			x[j] = -s / denom
+		}
+		storeVector(n, q, x, 0, k, vr, ldvr, k)
+	}
+}
+
+// schurLeftVectors computes the left eigenvectors Q y of A from y^H T = lambda y^H
+func schurLeftVectors(n int, t, q, vl []complex128, ldvl int) {
+	small := schurNorm(n, t)
+	w := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		lambda := t[k*n+k]
+		clear(w)
+		w[k] = 1
+		for j := k + 1; j < n; j++ {
+			var s complex128
+			for i := k; i < j; i++ {
+				s += w[i] * t[i*n+j]
+			}
+			denom := t[j*n+j] - lambda
+			if cmplx.Abs(denom) < small {
+				denom = complex(small, 0)
+			}
+			w[j] = -s / denom
+		}
+		for j := range w {
+			w[j] = cmplx.Conj(w[j])
+		}
+		storeVector(n, q, w, k, n-1, vl, ldvl, k)
+	}
+}
+
+// storeVector writes the unit-norm vector q x, with x nonzero only in [from, to], into column col of v
+func storeVector(n int, q, x []complex128, from, to int, v []complex128, ldv, col int) {
+	norm := 0.
+	for i := 0; i < n; i++ {
+		var s complex128
+		for m := from; m <= to; m++ {
+			s += q[i*n+m] * x[m]
+		}
+		v[i*ldv+col] = s
+		norm = math.Hypot(norm, cmplx.Abs(s))
+	}
+	for i := 0; i < n; i++ {
+		v[i*ldv+col] /= complex(norm, 0)
+	}
+}
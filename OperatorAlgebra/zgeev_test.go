@@ -0,0 +1,45 @@
+package OperatorAlgebra
+
+import (
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestComplexGenDiagonalization(t *testing.T) {
+	const n = 30
+	rng := rand.New(rand.NewPCG(3, 4))
+	a := mat.NewCDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a.Set(i, j, complex(rng.NormFloat64(), rng.NormFloat64()))
+		}
+	}
+	orig := mat.NewCDense(n, n, nil)
+	orig.Copy(a)
+
+	values := make([]complex128, n)
+	vectorsL := mat.NewCDense(n, n, nil)
+	vectorsR := mat.NewCDense(n, n, nil)
+	if err := ComplexGenDiagonalization(a, values, vectorsL, vectorsR); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for k := 0; k < n; k++ {
+		maxRight, maxLeft := 0., 0.
+		for i := 0; i < n; i++ {
+			var av, ua complex128
+			for j := 0; j < n; j++ {
+				av += orig.At(i, j) * vectorsR.At(j, k)
+				ua += cmplx.Conj(vectorsL.At(j, k)) * orig.At(j, i)
+			}
+			maxRight = max(maxRight, cmplx.Abs(av-values[k]*vectorsR.At(i, k)))
+			maxLeft = max(maxLeft, cmplx.Abs(ua-values[k]*cmplx.Conj(vectorsL.At(i, k))))
+		}
+		if maxRight > 1e-9 || maxLeft > 1e-9 {
+			t.Errorf("eigenpair %d: residuals %v (right) and %v (left)", k, maxRight, maxLeft)
+		}
+	}
+}
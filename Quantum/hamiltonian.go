@@ -3,42 +3,210 @@ package Quantum
 import (
 	"GoProject/OperatorAlgebra"
 	"GoProject/gridData"
+	"fmt"
+	"math"
 
 	"gonum.org/v1/gonum/mat"
 )
 
+// HamiltonianOp represents H = T + V on a real-space grid, with T given by any kinetic representation
 type HamiltonianOp struct {
-	grid *gridData.RadGrid
-	kinE OperatorAlgebra.KineticOp
-	potE gridData.PotentialOp[float64]
-	hmat mat.Matrix
+	grid       *gridData.RadGrid
+	kinE       OperatorAlgebra.KineticOp
+	potE       gridData.PotentialOp[float64]
+	vPot       []float64
+	hmat       *mat.Dense
+	hmatCached bool
 }
 
+// EigenStates holds the eigenvalues of a Hamiltonian in ascending order and the
+// corresponding orthonormal eigenvectors stored as columns
+type EigenStates struct {
+	grid     *gridData.RadGrid
+	Energies []float64
+	Vectors  *mat.Dense
+}
+
+// NewHamil creates a Hamiltonian with the sinc-DVR kinetic energy operator
 func NewHamil(grid *gridData.RadGrid, mass float64, Pot gridData.PotentialOp[float64]) *HamiltonianOp {
 	kinE := OperatorAlgebra.NewKeDVR(grid, mass)
-	Hmat := mat.Matrix(mat.NewDense(int(grid.NPoints()), int(grid.NPoints()), nil))
 	return &HamiltonianOp{
 		grid: grid,
 		kinE: kinE,
 		potE: Pot,
-		hmat: Hmat,
 	}
 }
 
-func (op *HamiltonianOp) Mat() {
-	vPot := op.grid.PotentialOnGrid(op.potE)
-	err := op.grid.PrintVectorToFileRe(vPot, "potent.dat", "%21.14e")
-	if err != nil {
-		return
+// NewHamilWithKinetic creates a Hamiltonian from an arbitrary kinetic energy representation
+func NewHamilWithKinetic(grid *gridData.RadGrid, kinE OperatorAlgebra.KineticOp,
+	Pot gridData.PotentialOp[float64]) (*HamiltonianOp, error) {
+	rows, cols := kinE.GetMat().Dims()
+	if rows != int(grid.NPoints()) || cols != int(grid.NPoints()) {
+		return nil, fmt.Errorf("kinetic matrix %dx%d doesn't match grid size %d", rows, cols, grid.NPoints())
+	}
+	return &HamiltonianOp{
+		grid: grid,
+		kinE: kinE,
+		potE: Pot,
+	}, nil
+}
+
+func (op *HamiltonianOp) Grid() *gridData.RadGrid                  { return op.grid }
+func (op *HamiltonianOp) Kinetic() OperatorAlgebra.KineticOp       { return op.kinE }
+func (op *HamiltonianOp) Potential() gridData.PotentialOp[float64] { return op.potE }
+
+// potentialOnGrid returns the potential on the grid points, using cache if available
+func (op *HamiltonianOp) potentialOnGrid() []float64 {
+	if op.vPot == nil {
+		op.vPot = op.grid.PotentialOnGrid(op.potE)
+	}
+	return op.vPot
+}
+
+// Mat assembles the Hamiltonian matrix T + V, using cache if available
+func (op *HamiltonianOp) Mat() *mat.Dense {
+	if !op.hmatCached {
+		vPot := op.potentialOnGrid()
+		op.hmat = mat.DenseCopyOf(op.kinE.GetMat())
+
+		for i := 0; i < int(op.grid.NPoints()); i++ {
+			op.hmat.Set(i, i, op.hmat.At(i, i)+vPot[i])
+		}
+		op.hmatCached = true
 	}
-	// need a change
-	op.hmat = op.kinE.KMat
+	return op.hmat
+}
+
+// ComplexMat assembles the non-Hermitian matrix T + V + Cap for a complex absorbing potential
+func (op *HamiltonianOp) ComplexMat(Cap gridData.PotentialOp[complex128]) *mat.CDense {
+	hmat := op.Mat()
+	absorber := op.grid.PotentialOnGridZ(Cap)
+	ndims := int(op.grid.NPoints())
 
-	for i := 0; i < int(op.grid.NPoints()); i++ {
-		op.hmat.(*mat.Dense).Set(i, i, op.hmat.At(i, i)+vPot[i])
+	cmat := mat.NewCDense(ndims, ndims, nil)
+	for i := 0; i < ndims; i++ {
+		for j := 0; j < ndims; j++ {
+			cmat.Set(i, j, complex(hmat.At(i, j), 0))
+		}
+		cmat.Set(i, i, cmat.At(i, i)+absorber[i])
 	}
+	return cmat
 }
 
+// EvaluateOp returns the assembled Hamiltonian matrix
 func (op *HamiltonianOp) EvaluateOp() *mat.Dense {
-	return nil
+	return op.Mat()
+}
+
+// Clear drops the cached Hamiltonian matrix and potential
+func (op *HamiltonianOp) Clear() {
+	op.hmatCached = false
+	op.vPot = nil
+}
+
+// MulVec computes out = H in. The dense Hamiltonian is never formed when the
+// kinetic operator can be applied matrix-free.
+func (op *HamiltonianOp) MulVec(in, out []float64) {
+	if kin, ok := op.kinE.(OperatorAlgebra.MatVecOp); ok {
+		kin.MulVec(in, out)
+	} else {
+		outVec := mat.NewVecDense(len(out), out)
+		outVec.MulVec(op.kinE.GetMat(), mat.NewVecDense(len(in), in))
+	}
+
+	vPot := op.potentialOnGrid()
+	for i := range out {
+		out[i] += vPot[i] * in[i]
+	}
+}
+
+// Diagonalize returns all bound-state energies and eigenvectors of the Hamiltonian
+func (op *HamiltonianOp) Diagonalize() (*EigenStates, error) {
+	ndims := int(op.grid.NPoints())
+	energies := make([]float64, ndims)
+	vectors := mat.DenseCopyOf(op.Mat())
+
+	if err := OperatorAlgebra.RealDiagonalizeLapack(vectors, energies); err != nil {
+		return nil, fmt.Errorf("diagonalization failed: %w", err)
+	}
+	fixPhase(vectors)
+
+	return &EigenStates{
+		grid:     op.grid,
+		Energies: energies,
+		Vectors:  vectors,
+	}, nil
+}
+
+// LowestStates returns the nStates lowest eigenpairs from a restarted Lanczos
+// iteration driven by MulVec, for grids where full diagonalization is too expensive.
+// A Krylov space grown from a single start vector holds only one vector of every
+// degenerate level, so exactly degenerate energies (e.g. on periodic grids) may be
+// returned once; use Diagonalize when the spectrum can be degenerate.
+func (op *HamiltonianOp) LowestStates(nStates int) (*EigenStates, error) {
+	return op.IterativeStates(OperatorAlgebra.NewLanczos(int(op.grid.NPoints()), op.MulVec), nStates)
+}
+
+// IterativeStates returns the nStates lowest eigenpairs from a configured Lanczos or
+// Davidson solver whose matrix-vector product is MulVec
+func (op *HamiltonianOp) IterativeStates(solver *OperatorAlgebra.IterativeEigenSolver,
+	nStates int) (*EigenStates, error) {
+	energies, vectors, err := solver.LowestEigen(nStates)
+	if err != nil {
+		return nil, fmt.Errorf("iterative diagonalization failed: %w", err)
+	}
+	fixPhase(vectors)
+
+	return &EigenStates{
+		grid:     op.grid,
+		Energies: energies,
+		Vectors:  vectors,
+	}, nil
+}
+
+// Diagonal returns the diagonal of T + V, the Davidson preconditioner
+func (op *HamiltonianOp) Diagonal() []float64 {
+	vPot := op.potentialOnGrid()
+	kinMat := op.kinE.GetMat()
+	diagonal := make([]float64, len(vPot))
+	for i, v := range vPot {
+		diagonal[i] = kinMat.At(i, i) + v
+	}
+	return diagonal
+}
+
+// fixPhase makes the largest component of every eigenvector positive so results are reproducible
+func fixPhase(vectors *mat.Dense) {
+	rows, cols := vectors.Dims()
+	for j := 0; j < cols; j++ {
+		maxVal := 0.
+		for i := 0; i < rows; i++ {
+			if math.Abs(vectors.At(i, j)) > math.Abs(maxVal) {
+				maxVal = vectors.At(i, j)
+			}
+		}
+		if maxVal < 0 {
+			for i := 0; i < rows; i++ {
+				vectors.Set(i, j, -vectors.At(i, j))
+			}
+		}
+	}
+}
+
+// NStates returns the number of computed eigenstates
+func (es *EigenStates) NStates() int { return len(es.Energies) }
+
+// State returns a copy of the n-th eigenvector normalized to unit length
+func (es *EigenStates) State(n int) []float64 {
+	return mat.Col(nil, n, es.Vectors)
+}
+
+// Wavefunction returns the n-th eigenstate normalized on the grid, Sum_i |psi_i|^2 dr = 1
+func (es *EigenStates) Wavefunction(n int) []float64 {
+	psi := es.State(n)
+	scale := 1. / math.Sqrt(es.grid.DeltaR())
+	for i := range psi {
+		psi[i] *= scale
+	}
+	return psi
 }
@@ -1,7 +1,9 @@
 package Quantum
 
 import (
+	"GoProject/OperatorAlgebra"
 	"GoProject/gridData"
+	"math"
 	"testing"
 )
 
@@ -11,3 +13,67 @@ func TestHamiltonianOp(t *testing.T) {
 	Harmonic := NewHamil(grid, 1., PotE)
 	Harmonic.Mat()
 }
+
+func TestHamiltonianOp_Diagonalize(t *testing.T) {
+	grid, err := gridData.NewFromLength(16., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	PotE := gridData.Harmonic[float64]{ForceConst: 1.}
+
+	kinetics := map[string]OperatorAlgebra.KineticOp{
+		"KeDVR":   OperatorAlgebra.NewKeDVR(grid, 1.),
+		"Fourier": OperatorAlgebra.FFTInit(grid, 1.),
+	}
+
+	for name, kinE := range kinetics {
+		hamil, err := NewHamilWithKinetic(grid, kinE, PotE)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		states, err := hamil.Diagonalize()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		for n := 0; n < 5; n++ {
+			expected := float64(n) + 0.5
+			if math.Abs(states.Energies[n]-expected) > 1e-6 {
+				t.Errorf("%s: expected E%d = %v, got %v", name, n, expected, states.Energies[n])
+			}
+		}
+
+		psi := states.Wavefunction(0)
+		norm := 0.
+		for _, v := range psi {
+			norm += v * v * grid.DeltaR()
+		}
+		if math.Abs(norm-1) > 1e-10 {
+			t.Errorf("%s: expected unit norm, got %v", name, norm)
+		}
+	}
+}
+
+func TestHamiltonianOp_LowestStates(t *testing.T) {
+	grid, err := gridData.NewFromLength(16., 96)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	PotE := gridData.Morse[float64]{De: 10., Alpha: 0.5, Cen: -2.}
+	hamil := NewHamil(grid, 1., PotE)
+
+	full, err := hamil.Diagonalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lowest, err := hamil.LowestStates(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for n := 0; n < 4; n++ {
+		if math.Abs(full.Energies[n]-lowest.Energies[n]) > 1e-8 {
+			t.Errorf("expected E%d = %v, got %v", n, full.Energies[n], lowest.Energies[n])
+		}
+	}
+}
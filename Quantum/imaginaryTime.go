@@ -0,0 +1,177 @@
+package Quantum
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ImaginaryTimeSolver relaxes trial wavefunctions to the lowest eigenstates by
+// propagating in imaginary time, psi <- exp(-V dtau/2) exp(-T dtau) exp(-V dtau/2) psi,
+// keeping every state orthogonal to the states found before it
+type ImaginaryTimeSolver struct {
+	grid *gridData.RadGrid
+	kinE OperatorAlgebra.CanKineticOp
+	vPot []float64
+	dTau float64
+
+	MaxSteps   int
+	CheckEvery int
+	Tolerance  float64
+
+	halfExpV []float64
+	work     []float64
+}
+
+// NewImaginaryTime creates an imaginary-time solver with the sinc-DVR kinetic energy operator
+func NewImaginaryTime(grid *gridData.RadGrid, mass float64, Pot gridData.PotentialOp[float64],
+	dTau float64) (*ImaginaryTimeSolver, error) {
+	return NewImaginaryTimeWithKinetic(grid, OperatorAlgebra.NewKeDVR(grid, mass), Pot, dTau)
+}
+
+// NewImaginaryTimeWithKinetic creates an imaginary-time solver for any kinetic representation
+// providing a real exponential exp(dt K)
+func NewImaginaryTimeWithKinetic(grid *gridData.RadGrid, kinE OperatorAlgebra.CanKineticOp,
+	Pot gridData.PotentialOp[float64], dTau float64) (*ImaginaryTimeSolver, error) {
+	if dTau <= 0 {
+		return nil, fmt.Errorf("imaginary time step must be positive, got %g", dTau)
+	}
+
+	vPot := grid.PotentialOnGrid(Pot)
+	halfExpV := make([]float64, len(vPot))
+	for i, v := range vPot {
+		halfExpV[i] = math.Exp(-0.5 * dTau * v)
+	}
+
+	return &ImaginaryTimeSolver{
+		grid:       grid,
+		kinE:       kinE,
+		vPot:       vPot,
+		dTau:       dTau,
+		MaxSteps:   100000,
+		CheckEvery: 10,
+		Tolerance:  1e-10,
+		halfExpV:   halfExpV,
+		work:       make([]float64, len(vPot)),
+	}, nil
+}
+
+// GroundState relaxes to the lowest eigenstate and returns its energy and unit-norm eigenvector
+func (it *ImaginaryTimeSolver) GroundState() (float64, []float64, error) {
+	states, err := it.LowestStates(1)
+	if err != nil {
+		return 0, nil, err
+	}
+	return states.Energies[0], states.State(0), nil
+}
+
+// LowestStates relaxes the nStates lowest eigenstates one after another, each
+// Gram-Schmidt orthogonalized against the previously converged states
+func (it *ImaginaryTimeSolver) LowestStates(nStates int) (*EigenStates, error) {
+	ndims := int(it.grid.NPoints())
+	if nStates <= 0 || nStates > ndims {
+		return nil, fmt.Errorf("number of states must be in [1, %d], got %d", ndims, nStates)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	energies := make([]float64, nStates)
+	found := make([][]float64, 0, nStates)
+
+	for n := 0; n < nStates; n++ {
+		psi := make([]float64, ndims)
+		for i := range psi {
+			psi[i] = rng.NormFloat64()
+		}
+		orthonormalize(psi, found)
+
+		energy, err := it.relax(psi, found)
+		if err != nil {
+			return nil, fmt.Errorf("state %d: %w", n, err)
+		}
+		energies[n] = energy
+		found = append(found, psi)
+	}
+
+	vectors := mat.NewDense(ndims, nStates, nil)
+	for n, psi := range found {
+		vectors.SetCol(n, psi)
+	}
+	fixPhase(vectors)
+
+	return &EigenStates{
+		grid:     it.grid,
+		Energies: energies,
+		Vectors:  vectors,
+	}, nil
+}
+
+// relax propagates psi in imaginary time until its energy stops changing
+func (it *ImaginaryTimeSolver) relax(psi []float64, lower [][]float64) (float64, error) {
+	energy := it.energy(psi)
+	for step := 1; step <= it.MaxSteps; step++ {
+		if err := it.step(psi); err != nil {
+			return 0, err
+		}
+		orthonormalize(psi, lower)
+
+		if step%it.CheckEvery == 0 {
+			newEnergy := it.energy(psi)
+			if math.IsNaN(newEnergy) {
+				return 0, fmt.Errorf("energy became invalid after %d steps", step)
+			}
+			if math.Abs(newEnergy-energy) < it.Tolerance {
+				return newEnergy, nil
+			}
+			energy = newEnergy
+		}
+	}
+	return 0, fmt.Errorf("imaginary-time relaxation did not converge after %d steps", it.MaxSteps)
+}
+
+// step applies one symmetric split step of length dTau
+func (it *ImaginaryTimeSolver) step(psi []float64) error {
+	for i := range psi {
+		psi[i] *= it.halfExpV[i]
+	}
+	if err := it.kinE.ExpDtInPlace(-it.dTau, psi); err != nil {
+		return err
+	}
+	for i := range psi {
+		psi[i] *= it.halfExpV[i]
+	}
+	return nil
+}
+
+// energy returns <psi|T + V|psi> for a unit-norm psi
+func (it *ImaginaryTimeSolver) energy(psi []float64) float64 {
+	if kin, ok := it.kinE.(OperatorAlgebra.MatVecOp); ok {
+		kin.MulVec(psi, it.work)
+	} else {
+		workVec := mat.NewVecDense(len(it.work), it.work)
+		workVec.MulVec(it.kinE.GetMat(), mat.NewVecDense(len(psi), psi))
+	}
+
+	energy := 0.
+	for i, p := range psi {
+		energy += p*it.work[i] + it.vPot[i]*p*p
+	}
+	return energy
+}
+
+// orthonormalize removes the components of psi along the given orthonormal states
+// (twice, for stability) and normalizes it to unit length
+func orthonormalize(psi []float64, states [][]float64) {
+	v := blas64.Vector{N: len(psi), Data: psi, Inc: 1}
+	for pass := 0; pass < 2; pass++ {
+		for _, s := range states {
+			sv := blas64.Vector{N: len(s), Data: s, Inc: 1}
+			blas64.Axpy(-blas64.Dot(sv, v), sv, v)
+		}
+	}
+	blas64.Scal(1/blas64.Nrm2(v), v)
+}
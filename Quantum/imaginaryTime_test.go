@@ -0,0 +1,42 @@
+package Quantum
+
+import (
+	"GoProject/gridData"
+	"math"
+	"testing"
+)
+
+func TestImaginaryTimeSolver_LowestStates(t *testing.T) {
+	grid, err := gridData.NewFromLength(16., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	PotE := gridData.Harmonic[float64]{ForceConst: 1.}
+
+	full, err := NewHamil(grid, 1., PotE).Diagonalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	solver, err := NewImaginaryTime(grid, 1., PotE, 0.01)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	states, err := solver.LowestStates(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for n := 0; n < 3; n++ {
+		if math.Abs(states.Energies[n]-full.Energies[n]) > 1e-4 {
+			t.Errorf("expected E%d = %v, got %v", n, full.Energies[n], states.Energies[n])
+		}
+		overlap := 0.
+		for i, v := range states.State(n) {
+			overlap += v * full.Vectors.At(i, n)
+		}
+		if math.Abs(math.Abs(overlap)-1) > 1e-4 {
+			t.Errorf("expected state %d to match diagonalization, overlap %v", n, overlap)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package Quantum
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ComplexScaling builds the complex-rotated Hamiltonian H(θ) = T e^{-2iθ} + V(r e^{iθ})
+// on a sinc-DVR grid. Bound states keep their real energies, continuum states rotate
+// by -2θ into the lower half plane and resonances appear as θ-stationary eigenvalues
+// E = Er - iΓ/2.
+type ComplexScaling struct {
+	grid *gridData.RadGrid
+	kinE *OperatorAlgebra.KeDvrBasis
+	potE gridData.PotentialOp[complex128]
+	x    []float64
+
+	StationaryTol float64
+}
+
+// ThetaTrajectory follows one eigenvalue of H(θ) as the rotation angle changes
+type ThetaTrajectory struct {
+	Theta  []float64
+	Energy []complex128
+}
+
+// Resonance is a θ-stationary point of a trajectory, Energy = Position - i Width/2
+type Resonance struct {
+	Position float64
+	Width    float64
+	Energy   complex128
+	Theta    float64
+}
+
+// NewComplexScaling creates a complex-scaling solver for a potential that can be
+// evaluated at complex coordinates
+func NewComplexScaling(grid *gridData.RadGrid, mass float64, Pot gridData.PotentialOp[complex128]) *ComplexScaling {
+	return &ComplexScaling{
+		grid:          grid,
+		kinE:          OperatorAlgebra.NewKeDVR(grid, mass),
+		potE:          Pot,
+		x:             grid.RValues(),
+		StationaryTol: 0.05,
+	}
+}
+
+func (cs *ComplexScaling) Grid() *gridData.RadGrid                     { return cs.grid }
+func (cs *ComplexScaling) Kinetic() *OperatorAlgebra.KeDvrBasis        { return cs.kinE }
+func (cs *ComplexScaling) Potential() gridData.PotentialOp[complex128] { return cs.potE }
+
+// Mat assembles the complex-scaled Hamiltonian matrix H(θ)
+func (cs *ComplexScaling) Mat(theta float64) *mat.CDense {
+	hmat := cs.kinE.GetComplexMat(theta)
+	rotation := cmplx.Exp(complex(0, theta))
+	for i, r := range cs.x {
+		v := cs.grid.PotentialAtZ(cs.potE, complex(r, 0)*rotation)
+		hmat.Set(i, i, hmat.At(i, i)+v)
+	}
+	return hmat
+}
+
+// Eigenvalues diagonalizes H(θ) and returns its eigenvalues sorted by real part
+func (cs *ComplexScaling) Eigenvalues(theta float64) ([]complex128, error) {
+	ndims := int(cs.grid.NPoints())
+	energies := make([]complex128, ndims)
+	vectorsL := mat.NewCDense(ndims, ndims, nil)
+	vectorsR := mat.NewCDense(ndims, ndims, nil)
+
+	if err := OperatorAlgebra.ComplexGenDiagonalization(cs.Mat(theta), energies, vectorsL, vectorsR); err != nil {
+		return nil, fmt.Errorf("theta = %g: %w", theta, err)
+	}
+
+	sort.Slice(energies, func(i, j int) bool { return real(energies[i]) < real(energies[j]) })
+	return energies, nil
+}
+
+// Trajectories follows every eigenvalue whose real part lies in [eMin, eMax] at the
+// first angle through the remaining angles, matching each to its nearest neighbour
+func (cs *ComplexScaling) Trajectories(thetas []float64, eMin, eMax float64) ([]ThetaTrajectory, error) {
+	if len(thetas) < 2 {
+		return nil, fmt.Errorf("at least two rotation angles are needed, got %d", len(thetas))
+	}
+	if eMin >= eMax {
+		return nil, fmt.Errorf("energy window [%g, %g] is empty", eMin, eMax)
+	}
+
+	energies, err := cs.Eigenvalues(thetas[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var trajectories []ThetaTrajectory
+	for _, e := range energies {
+		if real(e) >= eMin && real(e) <= eMax {
+			trajectories = append(trajectories, ThetaTrajectory{
+				Theta:  append(make([]float64, 0, len(thetas)), thetas[0]),
+				Energy: append(make([]complex128, 0, len(thetas)), e),
+			})
+		}
+	}
+
+	for _, theta := range thetas[1:] {
+		energies, err = cs.Eigenvalues(theta)
+		if err != nil {
+			return nil, err
+		}
+
+		used := make([]bool, len(energies))
+		for n := range trajectories {
+			last := trajectories[n].Energy[len(trajectories[n].Energy)-1]
+			best := -1
+			for i, e := range energies {
+				if !used[i] && (best < 0 || cmplx.Abs(e-last) < cmplx.Abs(energies[best]-last)) {
+					best = i
+				}
+			}
+			used[best] = true
+			trajectories[n].Theta = append(trajectories[n].Theta, theta)
+			trajectories[n].Energy = append(trajectories[n].Energy, energies[best])
+		}
+	}
+	return trajectories, nil
+}
+
+// Resonances runs the θ-trajectories and returns, sorted by position, the eigenvalues
+// whose relative rate of change |dE/dθ| / |E| falls below StationaryTol. Bound states
+// are stationary too and show up with a width close to zero.
+func (cs *ComplexScaling) Resonances(thetas []float64, eMin, eMax float64) ([]Resonance, error) {
+	trajectories, err := cs.Trajectories(thetas, eMin, eMax)
+	if err != nil {
+		return nil, err
+	}
+
+	var resonances []Resonance
+	for _, traj := range trajectories {
+		best, bestRate := -1, math.Inf(1)
+		for k := 0; k+1 < len(traj.Theta); k++ {
+			rate := cmplx.Abs(traj.Energy[k+1]-traj.Energy[k]) / (traj.Theta[k+1] - traj.Theta[k])
+			if rate < bestRate {
+				best, bestRate = k, rate
+			}
+		}
+
+		energy := (traj.Energy[best] + traj.Energy[best+1]) / 2
+		if bestRate > cs.StationaryTol*cmplx.Abs(energy) || imag(energy) > 1e-8*cmplx.Abs(energy) {
+			continue
+		}
+		resonances = append(resonances, Resonance{
+			Position: real(energy),
+			Width:    math.Max(-2*imag(energy), 0),
+			Energy:   energy,
+			Theta:    (traj.Theta[best] + traj.Theta[best+1]) / 2,
+		})
+	}
+
+	sort.Slice(resonances, func(i, j int) bool { return resonances[i].Position < resonances[j].Position })
+	return resonances, nil
+}
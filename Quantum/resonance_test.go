@@ -0,0 +1,115 @@
+package Quantum
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+)
+
+func TestComplexScaling_Harmonic(t *testing.T) {
+	grid, err := gridData.NewFromLength(16., 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs := NewComplexScaling(grid, 1., gridData.Harmonic[complex128]{ForceConst: 1.})
+
+	for _, theta := range []float64{0, 0.1, 0.2} {
+		energies, err := cs.Eigenvalues(theta)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for n := 0; n < 4; n++ {
+			expected := complex(float64(n)+0.5, 0)
+			if cmplx.Abs(energies[n]-expected) > 1e-6 {
+				t.Errorf("theta = %v: expected E%d = %v, got %v", theta, n, expected, energies[n])
+			}
+		}
+	}
+}
+
+func TestComplexScaling_Resonances(t *testing.T) {
+	grid, err := gridData.NewFromLength(40., 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	barrier := gridData.MultiGaussian[complex128]{Sigma: 0.5, Strength: 5., NumGauss: 2, Gap: 4.}
+	cs := NewComplexScaling(grid, 1., barrier)
+
+	var positions [][]Resonance
+	for _, thetas := range [][]float64{{0.2, 0.25, 0.3, 0.35, 0.4}, {0.3, 0.35, 0.4, 0.45}} {
+		resonances, err := cs.Resonances(thetas, 0.1, 3.)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resonances) != 2 {
+			t.Fatalf("expected 2 resonances below the barrier, got %d: %+v", len(resonances), resonances)
+		}
+		positions = append(positions, resonances)
+	}
+
+	for n, r := range positions[0] {
+		if r.Width <= 0 || r.Width > 0.05 {
+			t.Errorf("expected a narrow resonance, got width %v at %v", r.Width, r.Position)
+		}
+		if math.Abs(r.Position-positions[1][n].Position) > 1e-4 {
+			t.Errorf("resonance %d moved with theta: %v vs %v", n, r.Position, positions[1][n].Position)
+		}
+	}
+	if positions[0][0].Width >= positions[0][1].Width {
+		t.Errorf("expected the lower resonance to be narrower, got %v and %v",
+			positions[0][0].Width, positions[0][1].Width)
+	}
+}
+
+func TestComplexScaling_ParabolicBarrier(t *testing.T) {
+	// the barrier V0 - x^2/2 has the Gamow resonances E_n = V0 - i (n + 1/2), which H(theta)
+	// reproduces exactly for every 0 < theta < pi/2
+	grid, err := gridData.NewFromLength(20., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	const v0 = 2.
+	barrier := gridData.Shift[complex128]{Energy: v0, Pot: gridData.Harmonic[complex128]{ForceConst: -1.}}
+	cs := NewComplexScaling(grid, 1., barrier)
+
+	thetas := []float64{0.5, 0.6, 0.7, 0.8}
+	trajectories, err := cs.Trajectories(thetas, v0-0.5, v0+0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for n := 0; n < 3; n++ {
+		expected := complex(v0, -(float64(n) + 0.5))
+		found := false
+		for _, traj := range trajectories {
+			if cmplx.Abs(traj.Energy[0]-expected) > 1e-8 {
+				continue
+			}
+			found = true
+			for k, e := range traj.Energy {
+				if cmplx.Abs(e-expected) > 1e-8 {
+					t.Errorf("E%d moved to %v at theta = %v", n, e, traj.Theta[k])
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected a trajectory starting at E%d = %v", n, expected)
+		}
+	}
+
+	resonances, err := cs.Resonances(thetas, v0-0.5, v0+0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	widths := make([]float64, len(resonances))
+	for i, r := range resonances {
+		widths[i] = r.Width
+	}
+	sort.Float64s(widths)
+	for n, expected := range []float64{1, 3, 5} {
+		if n >= len(widths) || math.Abs(widths[n]-expected) > 1e-8 {
+			t.Fatalf("expected the widths 1, 3, 5 of the lowest Gamow states, got %v", widths)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package Quantum
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Rovibrational builds the radial Hamiltonians of a rotating diatomic,
+// H_J = T + V(r) + J(J+1)/(2 mu r^2), on a radial grid with the half-line sinc-DVR
+// kinetic energy, so the wavefunctions vanish at r = 0
+type Rovibrational struct {
+	grid *gridData.RadGrid
+	mass float64
+	kinE *OperatorAlgebra.KeDvrBasis
+	potE gridData.PotentialOp[float64]
+}
+
+// RovibTable holds the rovibrational levels Energies[v][J] for v < NVib and J <= JMax
+type RovibTable struct {
+	JMax     int
+	NVib     int
+	Energies [][]float64
+}
+
+// RotationalConstants are the fitted term values of one vibrational level,
+// E(v, J) = G + B J(J+1) - D [J(J+1)]^2
+type RotationalConstants struct {
+	V int
+	G float64
+	B float64
+	D float64
+}
+
+// DunhamCoefficients holds Y[k][l] of E(v, J) = Sum_kl Y_kl (v + 1/2)^k [J(J+1)]^l.
+// Y10 ~ we, Y20 ~ -wexe, Y01 ~ Be, Y11 ~ -alpha_e and Y02 ~ -De.
+type DunhamCoefficients struct {
+	Y [][]float64
+}
+
+// NewRovibrational creates the rovibrational Hamiltonians for the reduced mass and
+// potential; the grid must start at a positive multiple of its spacing, as from NewRadialGrid
+func NewRovibrational(grid *gridData.RadGrid, mass float64, Pot gridData.PotentialOp[float64]) (*Rovibrational, error) {
+	kinE, err := OperatorAlgebra.NewKeDVRDomain(grid, mass, OperatorAlgebra.HalfLine)
+	if err != nil {
+		return nil, err
+	}
+	return &Rovibrational{
+		grid: grid,
+		mass: mass,
+		kinE: kinE,
+		potE: Pot,
+	}, nil
+}
+
+func (rv *Rovibrational) Grid() *gridData.RadGrid                  { return rv.grid }
+func (rv *Rovibrational) Mass() float64                            { return rv.mass }
+func (rv *Rovibrational) Kinetic() *OperatorAlgebra.KeDvrBasis     { return rv.kinE }
+func (rv *Rovibrational) Potential() gridData.PotentialOp[float64] { return rv.potE }
+
+// Hamiltonian returns H_J with the centrifugal term of angular momentum J added to the potential
+func (rv *Rovibrational) Hamiltonian(J int) (*HamiltonianOp, error) {
+	if J < 0 {
+		return nil, fmt.Errorf("angular momentum must be non-negative, got %d", J)
+	}
+	effective := gridData.Sum[float64]{Terms: []gridData.PotentialOp[float64]{
+		rv.potE,
+		gridData.Centrifugal[float64]{J: J, Mass: rv.mass},
+	}}
+	return NewHamilWithKinetic(rv.grid, rv.kinE, effective)
+}
+
+// Levels diagonalizes H_J for J = 0, ..., jMax and tabulates the nVib lowest levels of each
+func (rv *Rovibrational) Levels(jMax, nVib int) (*RovibTable, error) {
+	if jMax < 0 || nVib < 1 {
+		return nil, fmt.Errorf("need jMax >= 0 and nVib >= 1, got %d and %d", jMax, nVib)
+	}
+	if nVib > int(rv.grid.NPoints()) {
+		return nil, fmt.Errorf("asked for %d levels on a grid of %d points", nVib, rv.grid.NPoints())
+	}
+
+	table := &RovibTable{JMax: jMax, NVib: nVib, Energies: make([][]float64, nVib)}
+	for v := range table.Energies {
+		table.Energies[v] = make([]float64, jMax+1)
+	}
+	for J := 0; J <= jMax; J++ {
+		hamil, err := rv.Hamiltonian(J)
+		if err != nil {
+			return nil, err
+		}
+		states, err := hamil.Diagonalize()
+		if err != nil {
+			return nil, fmt.Errorf("J = %d: %w", J, err)
+		}
+		for v := 0; v < nVib; v++ {
+			table.Energies[v][J] = states.Energies[v]
+		}
+	}
+	return table, nil
+}
+
+// Energy returns E(v, J)
+func (t *RovibTable) Energy(v, J int) float64 { return t.Energies[v][J] }
+
+// RotationalConstants fits G_v, B_v and D_v to each vibrational level by least squares over J
+func (t *RovibTable) RotationalConstants() ([]RotationalConstants, error) {
+	if t.JMax < 2 {
+		return nil, fmt.Errorf("fitting B and D needs jMax >= 2, got %d", t.JMax)
+	}
+
+	design := mat.NewDense(t.JMax+1, 3, nil)
+	for J := 0; J <= t.JMax; J++ {
+		x := float64(J * (J + 1))
+		design.SetRow(J, []float64{1, x, -x * x})
+	}
+
+	constants := make([]RotationalConstants, t.NVib)
+	for v := range constants {
+		var fit mat.VecDense
+		if err := fit.SolveVec(design, mat.NewVecDense(t.JMax+1, append([]float64(nil), t.Energies[v]...))); err != nil {
+			return nil, fmt.Errorf("v = %d: %w", v, err)
+		}
+		constants[v] = RotationalConstants{V: v, G: fit.AtVec(0), B: fit.AtVec(1), D: fit.AtVec(2)}
+	}
+	return constants, nil
+}
+
+// DunhamFit fits the Dunham coefficients Y_kl for k <= kMax and l <= lMax to the whole table by least squares
+func (t *RovibTable) DunhamFit(kMax, lMax int) (*DunhamCoefficients, error) {
+	if kMax < 0 || lMax < 0 {
+		return nil, fmt.Errorf("need kMax, lMax >= 0, got %d and %d", kMax, lMax)
+	}
+	if t.NVib <= kMax || t.JMax < lMax {
+		return nil, fmt.Errorf("a %dx%d Dunham fit needs more than %d levels and jMax >= %d, got %d and %d",
+			kMax+1, lMax+1, kMax, lMax, t.NVib, t.JMax)
+	}
+
+	nCols := (kMax + 1) * (lMax + 1)
+	nRows := t.NVib * (t.JMax + 1)
+	design := mat.NewDense(nRows, nCols, nil)
+	energies := mat.NewVecDense(nRows, nil)
+	row := 0
+	for v := 0; v < t.NVib; v++ {
+		for J := 0; J <= t.JMax; J++ {
+			vib := float64(v) + 0.5
+			rot := float64(J * (J + 1))
+			vibPow := 1.
+			for k := 0; k <= kMax; k++ {
+				term := vibPow
+				for l := 0; l <= lMax; l++ {
+					design.Set(row, k*(lMax+1)+l, term)
+					term *= rot
+				}
+				vibPow *= vib
+			}
+			energies.SetVec(row, t.Energies[v][J])
+			row++
+		}
+	}
+
+	var fit mat.VecDense
+	if err := fit.SolveVec(design, energies); err != nil {
+		return nil, fmt.Errorf("dunham fit failed: %w", err)
+	}
+
+	Y := make([][]float64, kMax+1)
+	for k := range Y {
+		Y[k] = make([]float64, lMax+1)
+		for l := range Y[k] {
+			Y[k][l] = fit.AtVec(k*(lMax+1) + l)
+		}
+	}
+	return &DunhamCoefficients{Y: Y}, nil
+}
+
+// Energy evaluates the Dunham expansion at (v, J)
+func (d *DunhamCoefficients) Energy(v, J int) float64 {
+	vib := float64(v) + 0.5
+	rot := float64(J * (J + 1))
+	energy, vibPow := 0., 1.
+	for _, row := range d.Y {
+		term := vibPow
+		for _, y := range row {
+			energy += y * term
+			term *= rot
+		}
+		vibPow *= vib
+	}
+	return energy
+}
@@ -0,0 +1,98 @@
+package Quantum
+
+import (
+	"GoProject/gridData"
+	"math"
+	"testing"
+)
+
+func TestRovibrational_Morse(t *testing.T) {
+	// H2-like Morse oscillator in atomic units
+	const mu, De, alpha, re = 918., 0.17, 1., 1.4
+	grid, err := gridData.NewRadialGrid(5, 250)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rv, err := NewRovibrational(grid, mu, gridData.Morse[float64]{De: De, Alpha: alpha, Cen: re})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	table, err := rv.Levels(8, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	we := alpha * math.Sqrt(2*De/mu)
+	wexe := alpha * alpha / (2 * mu)
+	Be := 1 / (2 * mu * re * re)
+	// Pekeris' rotation-vibration coupling and the centrifugal distortion of the Morse oscillator
+	alphaE := 6*math.Sqrt(wexe*Be*Be*Be)/we - 6*Be*Be/we
+	centrifugalD := 4 * Be * Be * Be / (we * we)
+
+	for v := 0; v < table.NVib; v++ {
+		vib := float64(v) + 0.5
+		expected := we*vib - wexe*vib*vib
+		if got := table.Energy(v, 0); math.Abs(got-expected) > 1e-8 {
+			t.Errorf("expected E(%d, 0) = %v, got %v", v, expected, got)
+		}
+		for J := 1; J <= table.JMax; J++ {
+			if table.Energy(v, J) <= table.Energy(v, J-1) {
+				t.Errorf("expected E(%d, J) to increase with J, got %v", v, table.Energies[v])
+			}
+		}
+	}
+
+	dunham, err := table.DunhamFit(2, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checks := []struct {
+		name     string
+		got, exp float64
+		tol      float64
+	}{
+		{"Y10", dunham.Y[1][0], we, 1e-3},
+		{"Y20", dunham.Y[2][0], -wexe, 0.02},
+		{"Y01", dunham.Y[0][1], Be, 0.01},
+		{"Y11", dunham.Y[1][1], -alphaE, 0.05},
+		{"Y02", dunham.Y[0][2], -centrifugalD, 0.05},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.exp) > c.tol*math.Abs(c.exp) {
+			t.Errorf("expected %s = %v, got %v", c.name, c.exp, c.got)
+		}
+	}
+
+	for v := 0; v < table.NVib; v++ {
+		for J := 0; J <= table.JMax; J++ {
+			if diff := math.Abs(dunham.Energy(v, J) - table.Energy(v, J)); diff > 1e-3*Be {
+				t.Errorf("expected the Dunham expansion to reproduce E(%d, %d) = %v, off by %v", v, J, table.Energy(v, J), diff)
+			}
+		}
+	}
+
+	// B_v from the per-level fits agrees with B_v = Y01 + Y11 (v+1/2) + Y21 (v+1/2)^2 up to the
+	// neglected higher centrifugal terms
+	constants, err := table.RotationalConstants()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range constants {
+		vib := float64(c.V) + 0.5
+		expected := dunham.Y[0][1] + dunham.Y[1][1]*vib + dunham.Y[2][1]*vib*vib
+		if math.Abs(c.B-expected) > 0.005*Be {
+			t.Errorf("expected B_%d = %v, got %v", c.V, expected, c.B)
+		}
+	}
+
+	if _, err := table.DunhamFit(4, 2); err == nil {
+		t.Errorf("expected an error for more vibrational terms than levels")
+	}
+	line, err := gridData.NewFromLength(4, 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewRovibrational(line, mu, gridData.Morse[float64]{De: De}); err == nil {
+		t.Errorf("expected an error for a grid that is not radial")
+	}
+}
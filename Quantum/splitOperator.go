@@ -0,0 +1,174 @@
+package Quantum
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// SplitOperator propagates a wavepacket with the second-order Strang splitting
+// exp(-iV dt/2) exp(-iT dt) exp(-iV dt/2), the kinetic part applied in k-space by FFT
+type SplitOperator struct {
+	grid  *gridData.RadGrid
+	tgrid *gridData.TimeGrid
+	kinE  *OperatorAlgebra.FourierBasis
+	tdPot gridData.TDPotentialOp
+
+	dt       float64
+	x        []float64
+	vPot     []float64
+	absorber []complex128
+	halfExp  []complex128
+	work     []complex128
+}
+
+// PropagationReport summarises the wavepacket at the end of a macro step
+type PropagationReport struct {
+	Step   int
+	Time   float64
+	Norm   float64
+	Energy float64
+}
+
+// NewSplitOperator creates a split-operator propagator for a static potential
+func NewSplitOperator(grid *gridData.RadGrid, tgrid *gridData.TimeGrid, mass float64,
+	Pot gridData.PotentialOp[float64]) *SplitOperator {
+	so := newSplitOperator(grid, tgrid, mass)
+	so.vPot = grid.PotentialOnGrid(Pot)
+	so.updateHalfExp()
+	return so
+}
+
+// NewSplitOperatorTD creates a split-operator propagator for a time-dependent potential V(x, t)
+func NewSplitOperatorTD(grid *gridData.RadGrid, tgrid *gridData.TimeGrid, mass float64,
+	tdPot gridData.TDPotentialOp) *SplitOperator {
+	so := newSplitOperator(grid, tgrid, mass)
+	so.tdPot = tdPot
+	return so
+}
+
+func newSplitOperator(grid *gridData.RadGrid, tgrid *gridData.TimeGrid, mass float64) *SplitOperator {
+	nPoints := int(grid.NPoints())
+	return &SplitOperator{
+		grid:    grid,
+		tgrid:   tgrid,
+		kinE:    OperatorAlgebra.FFTInit(grid, mass),
+		dt:      tgrid.DeltaT(),
+		x:       grid.RValues(),
+		vPot:    make([]float64, nPoints),
+		halfExp: make([]complex128, nPoints),
+		work:    make([]complex128, nPoints),
+	}
+}
+
+func (so *SplitOperator) Kinetic() *OperatorAlgebra.FourierBasis { return so.kinE }
+func (so *SplitOperator) DeltaT() float64                        { return so.dt }
+
+// Close releases the FFT plans of the kinetic operator
+func (so *SplitOperator) Close() { so.kinE.Close() }
+
+// SetAbsorber adds a complex absorbing potential -iW(x) to the propagation, so that
+// flux reaching the grid edges is damped by exp(-W dt) instead of wrapping around
+func (so *SplitOperator) SetAbsorber(Cap gridData.PotentialOp[complex128]) {
+	so.absorber = so.grid.PotentialOnGridZ(Cap)
+	so.updateHalfExp()
+}
+
+// updateHalfExp caches exp(-i V dt/2) for the current potential
+func (so *SplitOperator) updateHalfExp() {
+	for i, v := range so.vPot {
+		vTot := complex(v, 0)
+		if so.absorber != nil {
+			vTot += so.absorber[i]
+		}
+		so.halfExp[i] = cmplx.Exp(complex(0, -0.5*so.dt) * vTot)
+	}
+}
+
+// potentialAt refreshes the potential for a time-dependent run
+func (so *SplitOperator) potentialAt(t float64) {
+	if so.tdPot != nil {
+		so.tdPot.EvaluateOnRGridInPlace(so.x, so.vPot, t)
+	}
+}
+
+// Step advances psi in place from t to t + dt. A time-dependent potential is
+// evaluated at the midpoint t + dt/2, which keeps the scheme second order.
+func (so *SplitOperator) Step(psi []complex128, t float64) error {
+	if len(psi) != len(so.x) {
+		return fmt.Errorf("wavefunction length %d doesn't match grid size %d", len(psi), len(so.x))
+	}
+
+	if so.tdPot != nil {
+		so.potentialAt(t + 0.5*so.dt)
+		so.updateHalfExp()
+	}
+
+	for i := range psi {
+		psi[i] *= so.halfExp[i]
+	}
+	if err := so.kinE.ExpIdtInPlace(so.dt, psi); err != nil {
+		return err
+	}
+	for i := range psi {
+		psi[i] *= so.halfExp[i]
+	}
+	return nil
+}
+
+// Propagate runs the full time grid, MicroSteps split-operator steps per macro step,
+// and reports norm and energy at t = 0 and after every macro step. The observer may be nil.
+func (so *SplitOperator) Propagate(psi []complex128,
+	observer func(report PropagationReport, psi []complex128)) ([]PropagationReport, error) {
+	macroSteps := int(so.tgrid.MacroSteps())
+	microSteps := int(so.tgrid.MicroSteps())
+	reports := make([]PropagationReport, 0, macroSteps+1)
+
+	tMin := so.tgrid.TMin()
+	t := tMin
+	for step := 0; ; step++ {
+		report := so.Report(psi, t)
+		report.Step = step
+		if math.IsNaN(report.Norm) || math.IsInf(report.Norm, 0) {
+			return reports, fmt.Errorf("wavefunction norm became invalid at t = %g", t)
+		}
+		reports = append(reports, report)
+		if observer != nil {
+			observer(report, psi)
+		}
+		if step == macroSteps {
+			break
+		}
+
+		for micro := 0; micro < microSteps; micro++ {
+			if err := so.Step(psi, t); err != nil {
+				return reports, err
+			}
+			t = tMin + float64(step*microSteps+micro+1)*so.dt
+		}
+	}
+	return reports, nil
+}
+
+// Report computes the norm and total energy of psi at time t
+func (so *SplitOperator) Report(psi []complex128, t float64) PropagationReport {
+	so.potentialAt(t)
+	so.kinE.LaplacianOp(psi, so.work)
+
+	dx := so.grid.DeltaR()
+	norm, kinetic, potential := 0., 0., 0.
+	for i, c := range psi {
+		density := real(c)*real(c) + imag(c)*imag(c)
+		norm += density
+		kinetic += real(cmplx.Conj(c) * so.work[i])
+		potential += so.vPot[i] * density
+	}
+
+	return PropagationReport{
+		Time:   t,
+		Norm:   norm * dx,
+		Energy: (kinetic + potential) / norm,
+	}
+}
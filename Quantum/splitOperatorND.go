@@ -0,0 +1,121 @@
+package Quantum
+
+import (
+	"GoProject/OperatorAlgebra"
+	"GoProject/gridData"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// SplitOperatorND is the Strang split-operator propagator on a 2D/3D product grid,
+// the kinetic part applied with one multi-dimensional FFT
+type SplitOperatorND struct {
+	grid  *gridData.ProductGrid
+	tgrid *gridData.TimeGrid
+	kinE  *OperatorAlgebra.FourierBasisND
+
+	dt      float64
+	vPot    []float64
+	halfExp []complex128
+	work    []complex128
+}
+
+// NewSplitOperatorND creates a split-operator propagator for a static potential, with one mass per axis
+func NewSplitOperatorND(grid *gridData.ProductGrid, tgrid *gridData.TimeGrid, masses []float64,
+	Pot gridData.PotentialNDOp[float64]) (*SplitOperatorND, error) {
+	kinE, err := OperatorAlgebra.FFTInitND(grid, masses...)
+	if err != nil {
+		return nil, err
+	}
+
+	nPoints := grid.NPoints()
+	so := &SplitOperatorND{
+		grid:    grid,
+		tgrid:   tgrid,
+		kinE:    kinE,
+		dt:      tgrid.DeltaT(),
+		vPot:    grid.PotentialOnGrid(Pot),
+		halfExp: make([]complex128, nPoints),
+		work:    make([]complex128, nPoints),
+	}
+	for i, v := range so.vPot {
+		so.halfExp[i] = cmplx.Exp(complex(0, -0.5*so.dt*v))
+	}
+	return so, nil
+}
+
+func (so *SplitOperatorND) Kinetic() *OperatorAlgebra.FourierBasisND { return so.kinE }
+func (so *SplitOperatorND) DeltaT() float64                          { return so.dt }
+
+// Close releases the FFT plans of the kinetic operator
+func (so *SplitOperatorND) Close() { so.kinE.Close() }
+
+// Step advances psi in place by one time step
+func (so *SplitOperatorND) Step(psi []complex128) error {
+	if len(psi) != len(so.vPot) {
+		return fmt.Errorf("wavefunction length %d doesn't match grid size %d", len(psi), len(so.vPot))
+	}
+
+	for i := range psi {
+		psi[i] *= so.halfExp[i]
+	}
+	if err := so.kinE.ExpIdtInPlace(so.dt, psi); err != nil {
+		return err
+	}
+	for i := range psi {
+		psi[i] *= so.halfExp[i]
+	}
+	return nil
+}
+
+// Propagate runs the full time grid like SplitOperator.Propagate. The observer may be nil.
+func (so *SplitOperatorND) Propagate(psi []complex128,
+	observer func(report PropagationReport, psi []complex128)) ([]PropagationReport, error) {
+	macroSteps := int(so.tgrid.MacroSteps())
+	microSteps := int(so.tgrid.MicroSteps())
+	reports := make([]PropagationReport, 0, macroSteps+1)
+
+	tMin := so.tgrid.TMin()
+	for step := 0; ; step++ {
+		t := tMin + float64(step*microSteps)*so.dt
+		report := so.Report(psi, t)
+		report.Step = step
+		if math.IsNaN(report.Norm) || math.IsInf(report.Norm, 0) {
+			return reports, fmt.Errorf("wavefunction norm became invalid at t = %g", t)
+		}
+		reports = append(reports, report)
+		if observer != nil {
+			observer(report, psi)
+		}
+		if step == macroSteps {
+			break
+		}
+
+		for micro := 0; micro < microSteps; micro++ {
+			if err := so.Step(psi); err != nil {
+				return reports, err
+			}
+		}
+	}
+	return reports, nil
+}
+
+// Report computes the norm and total energy of psi at time t
+func (so *SplitOperatorND) Report(psi []complex128, t float64) PropagationReport {
+	so.kinE.LaplacianOp(psi, so.work)
+
+	norm, kinetic, potential := 0., 0., 0.
+	for i, c := range psi {
+		density := real(c)*real(c) + imag(c)*imag(c)
+		norm += density
+		kinetic += real(cmplx.Conj(c) * so.work[i])
+		potential += so.vPot[i] * density
+	}
+
+	return PropagationReport{
+		Time:   t,
+		Norm:   norm * so.grid.DeltaV(),
+		Energy: (kinetic + potential) / norm,
+	}
+}
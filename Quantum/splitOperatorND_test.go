@@ -0,0 +1,75 @@
+package Quantum
+
+import (
+	"GoProject/gridData"
+	"math"
+	"testing"
+)
+
+func TestSplitOperatorND_Propagate(t *testing.T) {
+	xGrid, err := gridData.NewFromLength(16., 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yGrid, err := gridData.NewFromLength(16., 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := gridData.NewProductGrid(xGrid, yGrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tgrid, err := gridData.NewTimeGrid(0.5, 6, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pot := gridData.Separable[float64]{Terms: []gridData.PotentialOp[float64]{
+		gridData.Harmonic[float64]{ForceConst: 1.},
+		gridData.Harmonic[float64]{ForceConst: 1.},
+	}}
+	so, err := NewSplitOperatorND(grid, tgrid, []float64{1., 1.}, pot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer so.Close()
+
+	// product of coherent states displaced to (1, -0.5) with momentum (0, 1): E = (1 + 1)/2 + (1 + 1/4 + 1)/2
+	psiX := coherentState(xGrid.RValues(), 1., 0.)
+	psiY := coherentState(yGrid.RValues(), -0.5, 1.)
+	psi := make([]complex128, grid.NPoints())
+	for i := range psiX {
+		for j := range psiY {
+			psi[grid.Index(i, j)] = psiX[i] * psiY[j]
+		}
+	}
+
+	var xMean []float64
+	x := make([]float64, 2)
+	reports, err := so.Propagate(psi, func(report PropagationReport, psi []complex128) {
+		mean := 0.
+		for i, c := range psi {
+			grid.Point(i, x)
+			mean += x[0] * (real(c)*real(c) + imag(c)*imag(c))
+		}
+		xMean = append(xMean, mean*grid.DeltaV()/report.Norm)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 7 {
+		t.Fatalf("expected 7 reports, got %d", len(reports))
+	}
+
+	for n, r := range reports {
+		if math.Abs(r.Norm-1) > 1e-10 {
+			t.Errorf("expected norm 1 at t = %g, got %v", r.Time, r.Norm)
+		}
+		if math.Abs(r.Energy-2.125) > 1e-3 {
+			t.Errorf("expected energy 2.125 at t = %g, got %v", r.Time, r.Energy)
+		}
+		if math.Abs(xMean[n]-math.Cos(r.Time)) > 1e-3 {
+			t.Errorf("expected <x> = cos(t) = %v at t = %g, got %v", math.Cos(r.Time), r.Time, xMean[n])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package Quantum
+
+import (
+	"GoProject/gridData"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// drivenHarmonic is V(x, t) = x^2/2 + F x sin(w t)
+type drivenHarmonic struct {
+	field float64
+	omega float64
+}
+
+func (d drivenHarmonic) EvaluateAt(x float64, t float64) float64 {
+	return 0.5*x*x + d.field*x*math.Sin(d.omega*t)
+}
+
+func (d drivenHarmonic) EvaluateOnRGrid(x []float64, t float64) []float64 {
+	res := make([]float64, len(x))
+	d.EvaluateOnRGridInPlace(x, res, t)
+	return res
+}
+
+func (d drivenHarmonic) EvaluateOnRGridInPlace(x, res []float64, t float64) {
+	for i := range x {
+		res[i] = d.EvaluateAt(x[i], t)
+	}
+}
+
+func coherentState(x []float64, x0, p0 float64) []complex128 {
+	psi := make([]complex128, len(x))
+	for i, xi := range x {
+		amp := math.Pow(math.Pi, -0.25) * math.Exp(-0.5*(xi-x0)*(xi-x0))
+		psi[i] = complex(amp, 0) * cmplx.Exp(complex(0, p0*xi))
+	}
+	return psi
+}
+
+func TestSplitOperator_Propagate(t *testing.T) {
+	grid, err := gridData.NewFromLength(20., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tgrid, err := gridData.NewTimeGrid(0.5, 10, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	so := NewSplitOperator(grid, tgrid, 1., gridData.Harmonic[float64]{ForceConst: 1.})
+	psi := coherentState(grid.RValues(), 1., 0.)
+
+	reports, err := so.Propagate(psi, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 11 {
+		t.Fatalf("expected 11 reports, got %d", len(reports))
+	}
+
+	for _, r := range reports {
+		if math.Abs(r.Norm-1) > 1e-10 {
+			t.Errorf("expected norm 1 at t = %g, got %v", r.Time, r.Norm)
+		}
+		if math.Abs(r.Energy-1) > 1e-3 {
+			t.Errorf("expected energy 1 at t = %g, got %v", r.Time, r.Energy)
+		}
+	}
+	if math.Abs(reports[10].Time-5) > 1e-10 {
+		t.Errorf("expected final time 5, got %v", reports[10].Time)
+	}
+}
+
+func TestSplitOperator_PropagateTD(t *testing.T) {
+	grid, err := gridData.NewFromLength(20., 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tgrid, err := gridData.NewTimeGrid(0.5, 10, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	so := NewSplitOperatorTD(grid, tgrid, 1., drivenHarmonic{field: 0.1, omega: 0.5})
+	psi := coherentState(grid.RValues(), 0., 0.)
+
+	reports, err := so.Propagate(psi, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := reports[len(reports)-1]
+	if math.Abs(last.Norm-1) > 1e-10 {
+		t.Errorf("expected norm 1, got %v", last.Norm)
+	}
+	if last.Energy <= 0.5 {
+		t.Errorf("expected the drive to change the energy, got %v", last.Energy)
+	}
+}
+
+func TestSplitOperator_Absorber(t *testing.T) {
+	grid, err := gridData.NewFromLength(80., 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tgrid, err := gridData.NewTimeGrid(1., 40, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Cap, err := gridData.NewManolopoulosCAP(grid, 10., 1.)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	so := NewSplitOperator(grid, tgrid, 1., gridData.Harmonic[float64]{})
+	so.SetAbsorber(Cap)
+	psi := coherentState(grid.RValues(), 0., 3.)
+
+	reports, err := so.Propagate(psi, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(reports[5].Norm-1) > 1e-6 {
+		t.Errorf("expected no absorption before the packet reaches the edge, got norm %v", reports[5].Norm)
+	}
+	if final := reports[len(reports)-1].Norm; final > 1e-2 {
+		t.Errorf("expected the packet to be absorbed, got norm %v", final)
+	}
+}
@@ -1 +1,78 @@
 package classical
+
+import (
+	"GoProject/gridData"
+	"fmt"
+	"math"
+)
+
+// NewSystemInfo creates the phase-space point (position, momentum) of a particle
+func NewSystemInfo(position, momentum float64) SystemInfo {
+	return SystemInfo{position: position, momentum: momentum}
+}
+
+func (s SystemInfo) Position() float64   { return s.position }
+func (s SystemInfo) Momentum() float64   { return s.momentum }
+func (s SystemInfo) KineticE() float64   { return s.kinE }
+func (s SystemInfo) PotentialE() float64 { return s.potenE }
+func (s SystemInfo) TotalE() float64     { return s.kinE + s.potenE }
+
+// VelocityVerlet integrates Newton's equations in the potential with the symplectic velocity Verlet scheme
+type VelocityVerlet struct {
+	pot gridData.PotentialOp[float64]
+	dt  float64
+}
+
+func NewVelocityVerlet(pot gridData.PotentialOp[float64], dt float64) *VelocityVerlet {
+	return &VelocityVerlet{pot: pot, dt: dt}
+}
+
+// Potential returns the potential the integrator moves the particle in
+func (vv *VelocityVerlet) Potential() gridData.PotentialOp[float64] { return vv.pot }
+
+// Step advances the system by dt and returns the new state with its energies
+func (vv *VelocityVerlet) Step(s *ModelSystem) SystemInfo {
+	halfKick := 0.5 * vv.dt * vv.pot.ForceAt(s.state.position)
+	momentum := s.state.momentum + halfKick
+	position := s.state.position + vv.dt*momentum/s.mass
+	momentum += 0.5 * vv.dt * vv.pot.ForceAt(position)
+	return s.energies(vv.pot, position, momentum)
+}
+
+func (s *ModelSystem) State() SystemInfo { return s.state }
+
+// energies fills in the kinetic and potential energy of the phase-space point
+func (s *ModelSystem) energies(pot gridData.PotentialOp[float64], position, momentum float64) SystemInfo {
+	return SystemInfo{
+		position: position,
+		momentum: momentum,
+		kinE:     momentum * momentum / (2 * s.mass),
+		potenE:   pot.EvaluateAt(position),
+	}
+}
+
+// Run integrates over the whole time grid, MicroSteps integrator steps per macro step,
+// and calls the observer at t = 0 and after every macro step. The energies are taken in the
+// integrator's potential. The observer may be nil.
+func (s *ModelSystem) Run(integrator InitialValueIntegrator, observer func(t float64, state SystemInfo)) error {
+	s.state = s.energies(integrator.Potential(), s.state.position, s.state.momentum)
+
+	macroSteps := int(s.tgrid.MacroSteps())
+	microSteps := int(s.tgrid.MicroSteps())
+	dt := s.tgrid.DeltaT()
+	for step := 0; ; step++ {
+		t := s.tgrid.TMin() + float64(step*microSteps)*dt
+		if math.IsNaN(s.state.position) || math.IsInf(s.state.position, 0) {
+			return fmt.Errorf("trajectory became invalid at t = %g", t)
+		}
+		if observer != nil {
+			observer(t, s.state)
+		}
+		if step == macroSteps {
+			return nil
+		}
+		for micro := 0; micro < microSteps; micro++ {
+			s.state = integrator.Step(s)
+		}
+	}
+}
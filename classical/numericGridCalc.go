@@ -1,5 +1,9 @@
 package classical
 
+import "GoProject/gridData"
+
+// InitialValueIntegrator advances a ModelSystem in the potential it integrates in
 type InitialValueIntegrator interface {
 	Step(s *ModelSystem) SystemInfo
+	Potential() gridData.PotentialOp[float64]
 }
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"GoProject/Input"
+	"GoProject/OperatorAlgebra"
+	"GoProject/Quantum"
+	"GoProject/classical"
+	"GoProject/gridData"
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const inputFile = "simulation.inp"
+
+// runContext carries the directories of one command and collects its summary
+type runContext struct {
+	name    string
+	runDir  string
+	outDir  string
+	stdout  io.Writer
+	start   time.Time
+	summary [][2]string
+}
+
+func (rc *runContext) note(key, format string, args ...any) {
+	rc.summary = append(rc.summary, [2]string{key, fmt.Sprintf(format, args...)})
+	fmt.Fprintf(rc.stdout, "%-12s %s\n", key+":", fmt.Sprintf(format, args...))
+}
+
+func (rc *runContext) load() (*Input.Simulation, *gridData.RadGrid, error) {
+	rc.start = time.Now()
+	sim, err := Input.ReadFile(filepath.Join(rc.runDir, inputFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	grid, err := sim.RadGrid()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(rc.outDir, 0o755); err != nil {
+		return nil, nil, err
+	}
+
+	rc.note("command", "%s", rc.name)
+	rc.note("input", "%s", filepath.Join(rc.runDir, inputFile))
+	rc.note("grid", "%v", grid)
+	rc.note("mass", "%g", sim.Mass)
+	rc.note("potential", "%s: %v", sim.Potential.Type, sim.Potential.Op)
+	return sim, grid, nil
+}
+
+// writeSummary writes the collected `key: value` lines to summary.txt
+func (rc *runContext) writeSummary() error {
+	rc.note("wall time", "%v", time.Since(rc.start).Round(time.Millisecond))
+	return writeFile(filepath.Join(rc.outDir, "summary.txt"), func(w *bufio.Writer) error {
+		for _, line := range rc.summary {
+			if _, err := fmt.Fprintf(w, "%s: %s\n", line[0], line[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeTable writes rows of numbers under a '#' header, the format read back by NewTabulatedFromFile
+func writeTable(filename, header string, rows [][]float64) error {
+	return writeFile(filename, func(w *bufio.Writer) error {
+		if _, err := fmt.Fprintf(w, "# %s\n", header); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for j, v := range row {
+				sep := "\t"
+				if j == len(row)-1 {
+					sep = "\n"
+				}
+				if _, err := fmt.Fprintf(w, "%20.12e%s", v, sep); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func writeFile(filename string, write func(w *bufio.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// gridInfo prints the grids of a run, from simulation.inp or the rgrid.inp/tgrid.inp pair
+func gridInfo(rc *runContext) error {
+	var grid *gridData.RadGrid
+	var tgrid *gridData.TimeGrid
+
+	if _, err := os.Stat(filepath.Join(rc.runDir, inputFile)); err == nil {
+		sim, g, err := rc.load()
+		if err != nil {
+			return err
+		}
+		grid = g
+		if sim.Time != nil {
+			if tgrid, err = sim.TimeGrid(); err != nil {
+				return err
+			}
+		}
+	} else {
+		rc.start = time.Now()
+		if grid, err = gridData.NewRGridFromFile(rc.runDir); err != nil {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(rc.runDir, "tgrid.inp")); err == nil {
+			if tgrid, err = gridData.NewTGridFromFile(rc.runDir); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(rc.outDir, 0o755); err != nil {
+			return err
+		}
+		rc.note("command", "%s", rc.name)
+		rc.note("grid", "%v", grid)
+	}
+
+	rc.note("dr", "%g", grid.DeltaR())
+	rc.note("k range", "[%g, %g], dk = %g", grid.KMin(), grid.KMax(), grid.DeltaK())
+	rc.note("cutoff E", "%g", grid.CutoffE())
+	if tgrid != nil {
+		rc.note("time grid", "%v", tgrid)
+		rc.note("dt", "%g", tgrid.DeltaT())
+	}
+	return rc.writeSummary()
+}
+
+// eigen solves for the lowest eigenstates with the configured solver
+func eigen(rc *runContext) error {
+	sim, grid, err := rc.load()
+	if err != nil {
+		return err
+	}
+	kinE, err := sim.KineticOp(grid)
+	if err != nil {
+		return err
+	}
+	if closer, ok := kinE.(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+	hamil, err := Quantum.NewHamilWithKinetic(grid, kinE, sim.Potential.Op)
+	if err != nil {
+		return err
+	}
+	rc.note("kinetic", "%s", sim.Kinetic.Type)
+	rc.note("solver", "%s", sim.Solver.Type)
+
+	nStates := sim.Solver.NStates
+	ndims := int(grid.NPoints())
+	if nStates > ndims {
+		return fmt.Errorf("%d states requested on a grid of %d points", nStates, ndims)
+	}
+
+	var states *Quantum.EigenStates
+	switch sim.Solver.Type {
+	case "diagonalize":
+		states, err = hamil.Diagonalize()
+	case "lanczos", "davidson":
+		solver := OperatorAlgebra.NewLanczos(ndims, hamil.MulVec)
+		if sim.Solver.Type == "davidson" {
+			solver = OperatorAlgebra.NewDavidson(ndims, hamil.MulVec, hamil.Diagonal())
+		}
+		solver.Tolerance = sim.Solver.Tolerance
+		solver.MaxIter = sim.Solver.MaxIter
+		states, err = hamil.IterativeStates(solver, nStates)
+	case "imaginaryTime":
+		canKinE, ok := kinE.(OperatorAlgebra.CanKineticOp)
+		if !ok {
+			return fmt.Errorf("kinetic representation %s has no real exponential", sim.Kinetic.Type)
+		}
+		var it *Quantum.ImaginaryTimeSolver
+		it, err = Quantum.NewImaginaryTimeWithKinetic(grid, canKinE, sim.Potential.Op, sim.Solver.DTau)
+		if err != nil {
+			return err
+		}
+		it.Tolerance = sim.Solver.Tolerance
+		states, err = it.LowestStates(nStates)
+	default:
+		return fmt.Errorf("solver %s does not compute eigenstates", sim.Solver.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	energies := make([][]float64, nStates)
+	for n := range energies {
+		energies[n] = []float64{float64(n), states.Energies[n]}
+		rc.note(fmt.Sprintf("E%d", n), "%.12g", states.Energies[n])
+	}
+	if err := writeTable(filepath.Join(rc.outDir, "energies.dat"), "n\tenergy", energies); err != nil {
+		return err
+	}
+
+	rValues := grid.RValues()
+	wavefunctions := make([][]float64, ndims)
+	psi := make([][]float64, nStates)
+	for n := range psi {
+		psi[n] = states.Wavefunction(n)
+	}
+	for i, r := range rValues {
+		wavefunctions[i] = make([]float64, nStates+1)
+		wavefunctions[i][0] = r
+		for n := range psi {
+			wavefunctions[i][n+1] = psi[n][i]
+		}
+	}
+	if err := writeTable(filepath.Join(rc.outDir, "wavefunctions.dat"), "r\tpsi_0 ... psi_n", wavefunctions); err != nil {
+		return err
+	}
+	return rc.writeSummary()
+}
+
+// propagate runs a split-operator propagation of the Gaussian wavepacket of [initial]
+func propagate(rc *runContext) error {
+	sim, grid, err := rc.load()
+	if err != nil {
+		return err
+	}
+	// the split-operator propagator applies the kinetic energy in k-space by FFT
+	if sim.Kinetic.Type != "fourier" {
+		return fmt.Errorf("propagate needs the fourier kinetic representation, got %s", sim.Kinetic.Type)
+	}
+	tgrid, err := sim.TimeGrid()
+	if err != nil {
+		return err
+	}
+	rc.note("time grid", "%v", tgrid)
+	rc.note("initial", "x0 = %g, p0 = %g, sigma = %g", sim.Initial.X0, sim.Initial.P0, sim.Initial.Sigma)
+
+	rValues := grid.RValues()
+	psi := gaussianWavepacket(rValues, sim.Initial)
+	so := Quantum.NewSplitOperator(grid, tgrid, sim.Mass, sim.Potential.Op)
+	defer so.Close()
+
+	var rows [][]float64
+	reports, err := so.Propagate(psi, func(report Quantum.PropagationReport, psi []complex128) {
+		xMean := 0.
+		for i, c := range psi {
+			xMean += rValues[i] * (real(c)*real(c) + imag(c)*imag(c))
+		}
+		xMean *= grid.DeltaR() / report.Norm
+		rows = append(rows, []float64{report.Time, report.Norm, report.Energy, xMean})
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeTable(filepath.Join(rc.outDir, "propagation.dat"), "time\tnorm\tenergy\t<x>", rows); err != nil {
+		return err
+	}
+
+	density := make([][]float64, len(psi))
+	for i, c := range psi {
+		density[i] = []float64{rValues[i], real(c), imag(c), cmplx.Abs(c) * cmplx.Abs(c)}
+	}
+	if err := writeTable(filepath.Join(rc.outDir, "final.dat"), "r\tRe psi\tIm psi\t|psi|^2", density); err != nil {
+		return err
+	}
+
+	first, last := reports[0], reports[len(reports)-1]
+	rc.note("final time", "%g", last.Time)
+	rc.note("final norm", "%.12g", last.Norm)
+	rc.note("energy", "%.12g -> %.12g", first.Energy, last.Energy)
+	return rc.writeSummary()
+}
+
+// molecularDynamics integrates the classical trajectory starting from x0, p0 of [initial]
+func molecularDynamics(rc *runContext) error {
+	sim, grid, err := rc.load()
+	if err != nil {
+		return err
+	}
+	tgrid, err := sim.TimeGrid()
+	if err != nil {
+		return err
+	}
+	rc.note("time grid", "%v", tgrid)
+	rc.note("initial", "x0 = %g, p0 = %g", sim.Initial.X0, sim.Initial.P0)
+
+	system := classical.NewModelSystem(classical.NewSystemInfo(sim.Initial.X0, sim.Initial.P0),
+		sim.Mass, *grid, *tgrid)
+	integrator := classical.NewVelocityVerlet(sim.Potential.Op, tgrid.DeltaT())
+
+	var rows [][]float64
+	err = system.Run(integrator, func(t float64, s classical.SystemInfo) {
+		rows = append(rows, []float64{t, s.Position(), s.Momentum(), s.KineticE(), s.PotentialE(), s.TotalE()})
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeTable(filepath.Join(rc.outDir, "trajectory.dat"), "time\tx\tp\tEkin\tEpot\tEtot", rows); err != nil {
+		return err
+	}
+
+	drift := 0.
+	for _, row := range rows {
+		drift = math.Max(drift, math.Abs(row[5]-rows[0][5]))
+	}
+	final := system.State()
+	rc.note("final", "x = %.12g, p = %.12g", final.Position(), final.Momentum())
+	rc.note("energy", "%.12g, max drift %.3g", rows[0][5], drift)
+	return rc.writeSummary()
+}
+
+// gaussianWavepacket returns the normalized packet exp(-(x-x0)^2/(2 sigma^2) + i p0 x)
+func gaussianWavepacket(x []float64, initial Input.InitialInput) []complex128 {
+	norm := math.Pow(math.Pi*initial.Sigma*initial.Sigma, -0.25)
+	psi := make([]complex128, len(x))
+	for i, xi := range x {
+		y := (xi - initial.X0) / initial.Sigma
+		psi[i] = complex(norm*math.Exp(-0.5*y*y), 0) * cmplx.Exp(complex(0, initial.P0*xi))
+	}
+	return psi
+}
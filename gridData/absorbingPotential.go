@@ -0,0 +1,184 @@
+package gridData
+
+import (
+	"fmt"
+	"math"
+)
+
+// Complex absorbing potentials -iW(x) switched on outside [Left, Right] and acting on
+// the real coordinate: the imaginary part of a complex argument is ignored, so the
+// absorbers can be added to the real-space potential of a Hamiltonian or propagation.
+
+// manolopoulosC is the position of the singularity of the transmission-free absorber in reduced units
+const manolopoulosC = 2.62206
+
+// PolynomialCAP w(x)= Strength ((x - x0)/Width)^Order beyond the onsets x0 = Left, Right
+type PolynomialCAP struct {
+	Left     float64
+	Right    float64
+	Width    float64
+	Strength float64
+	Order    int
+}
+
+// NewPolynomialCAP places a polynomial absorber on the last width of either edge of the grid
+func NewPolynomialCAP(g *RadGrid, width, strength float64, order int) (PolynomialCAP, error) {
+	left, right, err := capOnsets(g, width)
+	if err != nil {
+		return PolynomialCAP{}, err
+	}
+	if order < 1 {
+		return PolynomialCAP{}, fmt.Errorf("absorber order must be positive, got %d", order)
+	}
+	return PolynomialCAP{Left: left, Right: right, Width: width, Strength: strength, Order: order}, nil
+}
+
+func (p PolynomialCAP) String() string {
+	return fmt.Sprintf("-i %g ((|x| - x0)/%g)^%d, where x0 = %g (left), %g (right)",
+		p.Strength, p.Width, p.Order, p.Left, p.Right)
+}
+
+func (p PolynomialCAP) EvaluateAt(x complex128) complex128 {
+	y, _ := capDepth(real(x), p.Left, p.Right)
+	if y <= 0 {
+		return 0
+	}
+	return complex(0, -p.Strength*math.Pow(y/p.Width, float64(p.Order)))
+}
+
+func (p PolynomialCAP) ForceAt(x complex128) complex128 {
+	y, sign := capDepth(real(x), p.Left, p.Right)
+	if y <= 0 {
+		return 0
+	}
+	order := float64(p.Order)
+	dw := p.Strength * order / p.Width * math.Pow(y/p.Width, order-1) * sign
+	return complex(0, dw)
+}
+
+func (p PolynomialCAP) EvaluateOnGrid(x []complex128) []complex128 { return onGrid(p.EvaluateAt, x) }
+func (p PolynomialCAP) ForceOnGrid(x []complex128) []complex128    { return onGrid(p.ForceAt, x) }
+
+// ManolopoulosCAP is the transmission-free absorber of D. E. Manolopoulos, J. Chem. Phys. 117, 9552 (2002):
+// w(x)= Emin [a y - b y^3 + 4/(c-y)^2 - 4/(c+y)^2], y = c (|x| - x0)/Width,
+// which diverges at the far end of the absorbing region and absorbs every energy above
+// Emin = kMin^2/2m with kMin = c/(2 Delta Width), Delta = 0.2
+type ManolopoulosCAP struct {
+	Left  float64
+	Right float64
+	Width float64
+	Mass  float64
+}
+
+// NewManolopoulosCAP places a transmission-free absorber on the last width of either edge,
+// with the singularity one grid spacing beyond the edge
+func NewManolopoulosCAP(g *RadGrid, width, mass float64) (ManolopoulosCAP, error) {
+	left, right, err := capOnsets(g, width)
+	if err != nil {
+		return ManolopoulosCAP{}, err
+	}
+	if mass <= 0 {
+		return ManolopoulosCAP{}, fmt.Errorf("mass must be positive, got %g", mass)
+	}
+	return ManolopoulosCAP{Left: left, Right: right, Width: width + g.DeltaR(), Mass: mass}, nil
+}
+
+func (m ManolopoulosCAP) String() string {
+	return fmt.Sprintf("-i Emin [a y - b y^3 + 4/(c-y)^2 - 4/(c+y)^2], where Emin = %g, x0 = %g (left), %g (right), width = %g",
+		m.EMin(), m.Left, m.Right, m.Width)
+}
+
+// KMin is the lowest wavenumber absorbed with less than ~1% reflection
+func (m ManolopoulosCAP) KMin() float64 { return manolopoulosC / (2 * 0.2 * m.Width) }
+func (m ManolopoulosCAP) EMin() float64 { return m.KMin() * m.KMin() / (2 * m.Mass) }
+
+func (m ManolopoulosCAP) EvaluateAt(x complex128) complex128 {
+	depth, _ := capDepth(real(x), m.Left, m.Right)
+	if depth <= 0 {
+		return 0
+	}
+	c := manolopoulosC
+	y := math.Min(c*depth/m.Width, c*(1-1e-12))
+	a := 1 - 16/(c*c*c)
+	b := (1 - 17/(c*c*c)) / (c * c)
+	w := a*y - b*y*y*y + 4/((c-y)*(c-y)) - 4/((c+y)*(c+y))
+	return complex(0, -m.EMin()*w)
+}
+
+func (m ManolopoulosCAP) ForceAt(x complex128) complex128 {
+	depth, sign := capDepth(real(x), m.Left, m.Right)
+	if depth <= 0 {
+		return 0
+	}
+	c := manolopoulosC
+	y := math.Min(c*depth/m.Width, c*(1-1e-12))
+	a := 1 - 16/(c*c*c)
+	b := (1 - 17/(c*c*c)) / (c * c)
+	dw := a - 3*b*y*y + 8/((c-y)*(c-y)*(c-y)) + 8/((c+y)*(c+y)*(c+y))
+	return complex(0, m.EMin()*dw*c/m.Width*sign)
+}
+
+func (m ManolopoulosCAP) EvaluateOnGrid(x []complex128) []complex128 { return onGrid(m.EvaluateAt, x) }
+func (m ManolopoulosCAP) ForceOnGrid(x []complex128) []complex128    { return onGrid(m.ForceAt, x) }
+
+// RissMeyerCAP w(x)= Eta (|x| - x0)^2, the quadratic absorber of U. V. Riss and H.-D. Meyer,
+// J. Phys. B 26, 4503 (1993), whose strength Eta is scanned in eta-trajectories
+type RissMeyerCAP struct {
+	Left  float64
+	Right float64
+	Eta   float64
+}
+
+// NewRissMeyerCAP places a quadratic absorber on the last width of either edge of the grid
+func NewRissMeyerCAP(g *RadGrid, width, eta float64) (RissMeyerCAP, error) {
+	left, right, err := capOnsets(g, width)
+	if err != nil {
+		return RissMeyerCAP{}, err
+	}
+	return RissMeyerCAP{Left: left, Right: right, Eta: eta}, nil
+}
+
+func (rm RissMeyerCAP) String() string {
+	return fmt.Sprintf("-i %g (|x| - x0)^2, where x0 = %g (left), %g (right)", rm.Eta, rm.Left, rm.Right)
+}
+
+func (rm RissMeyerCAP) EvaluateAt(x complex128) complex128 {
+	y, _ := capDepth(real(x), rm.Left, rm.Right)
+	if y <= 0 {
+		return 0
+	}
+	return complex(0, -rm.Eta*y*y)
+}
+
+func (rm RissMeyerCAP) ForceAt(x complex128) complex128 {
+	y, sign := capDepth(real(x), rm.Left, rm.Right)
+	if y <= 0 {
+		return 0
+	}
+	return complex(0, 2*rm.Eta*y*sign)
+}
+
+func (rm RissMeyerCAP) EvaluateOnGrid(x []complex128) []complex128 { return onGrid(rm.EvaluateAt, x) }
+func (rm RissMeyerCAP) ForceOnGrid(x []complex128) []complex128    { return onGrid(rm.ForceAt, x) }
+
+// capDepth returns how far x lies inside an absorbing region and the sign of d(depth)/dx
+func capDepth(x, left, right float64) (float64, float64) {
+	if x > right {
+		return x - right, 1
+	}
+	if x < left {
+		return left - x, -1
+	}
+	return 0, 0
+}
+
+// capOnsets returns the inner edges of absorbing regions of the given width at both grid edges
+func capOnsets(g *RadGrid, width float64) (float64, float64, error) {
+	if width <= 0 {
+		return 0, 0, fmt.Errorf("absorber width must be positive, got %g", width)
+	}
+	if 2*width >= g.RMax()-g.RMin() {
+		return 0, 0, fmt.Errorf("absorbers of width %g overlap on a grid of length %g", width, g.RMax()-g.RMin())
+	}
+	return g.RMin() + width, g.RMax() - width, nil
+}
@@ -0,0 +1,54 @@
+package gridData
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestAbsorbingPotential_ForceAt(t *testing.T) {
+	grid, err := NewFromLength(20., 201)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	poly, err := NewPolynomialCAP(grid, 4., 2., 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	manolopoulos, err := NewManolopoulosCAP(grid, 4., 1.)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rissMeyer, err := NewRissMeyerCAP(grid, 4., 0.1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	caps := map[string]PotentialOp[complex128]{
+		"Polynomial":   poly,
+		"Manolopoulos": manolopoulos,
+		"RissMeyer":    rissMeyer,
+	}
+
+	const h = 1e-6
+	for name, Cap := range caps {
+		if v := Cap.EvaluateAt(0); v != 0 {
+			t.Errorf("%s: expected no absorption in the interaction region, got %v", name, v)
+		}
+		for _, x := range []float64{-9.5, -7., 7., 9.5} {
+			v := Cap.EvaluateAt(complex(x, 0))
+			if real(v) != 0 || imag(v) >= 0 {
+				t.Errorf("%s: expected -iW with W > 0 at x = %v, got %v", name, x, v)
+			}
+			numeric := -(Cap.EvaluateAt(complex(x+h, 0)) - Cap.EvaluateAt(complex(x-h, 0))) / (2 * h)
+			force := Cap.ForceAt(complex(x, 0))
+			if cmplx.Abs(force-numeric) > 1e-5*max(1, cmplx.Abs(force)) {
+				t.Errorf("%s: expected force %v at x = %v, got %v", name, numeric, x, force)
+			}
+		}
+	}
+
+	if _, err := NewPolynomialCAP(grid, 10., 1., 2); err == nil {
+		t.Errorf("expected an error for overlapping absorbers")
+	}
+}
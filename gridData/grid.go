@@ -1,12 +1,8 @@
 package gridData
 
 import (
-	"bufio"
 	"fmt"
 	"math"
-	"os"
-	"strconv"
-	"strings"
 )
 
 // RadGrid Represents a real-space grid
@@ -50,51 +46,25 @@ func NewRGrid(rMin, rMax float64, nPoints uint32) (*RadGrid, error) {
 }
 
 func NewRGridFromFile(dirPath string) (*RadGrid, error) {
-	info, err := os.Stat(dirPath)
+	input, err := readInputFile(dirPath, "rgrid.inp", "rMin", "rMax", "nPoints")
 	if err != nil {
-		return nil, fmt.Errorf("directory check failed: %w", err)
-	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("%s is not a directory", dirPath)
+		return nil, err
 	}
 
-	filePath := dirPath + "/rgrid.inp"
-
-	file, err := os.Open(filePath)
+	rmin, err := input.float("rMin")
+	if err != nil {
+		return nil, err
+	}
+	rmax, err := input.float("rMax")
 	if err != nil {
-		return nil, fmt.Errorf("could not open %s: %w", filePath, err)
+		return nil, err
 	}
-
-	var rmin, rmax float64
-	var nPoints int
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.Split(line, ":")
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "rMin":
-			rmin, _ = strconv.ParseFloat(value, 64)
-		case "rMax":
-			rmax, _ = strconv.ParseFloat(value, 64)
-		case "nPoints":
-			nPoints, _ = strconv.Atoi(value)
-		}
+	nPoints, err := input.count("nPoints")
+	if err != nil {
+		return nil, err
 	}
 
-	return NewRGrid(rmin, rmax, uint32(nPoints))
+	return NewRGrid(rmin, rmax, nPoints)
 }
 
 func NewFromLength(length float64, nPoints uint32) (*RadGrid, error) {
@@ -105,6 +75,16 @@ func NewFromLength(length float64, nPoints uint32) (*RadGrid, error) {
 	return NewRGrid(-halfLength, halfLength, nPoints)
 }
 
+// NewRadialGrid creates the half-line grid r = dr, 2 dr, ..., rMax with dr = rMax/nPoints,
+// the points of the [0, infinity) sinc DVR, which leaves out r = 0 where psi vanishes
+func NewRadialGrid(rMax float64, nPoints uint32) (*RadGrid, error) {
+	if rMax <= 0 || nPoints == 0 {
+		return nil, fmt.Errorf("a radial grid needs rMax > 0 and at least one point")
+	}
+	dr := rMax / float64(nPoints)
+	return NewRGrid(dr, rMax+dr, nPoints)
+}
+
 func (g *RadGrid) redefine(rMin, rMax float64, nPoints uint32) error {
 	igridData, err := createGrid(rMin, rMax, nPoints, "Rgrid")
 	if err != nil {
@@ -164,6 +144,16 @@ func (g *RadGrid) ForceOnGrid(pot PotentialOp[float64]) []float64 {
 	return pot.ForceOnGrid(g.RValues())
 }
 
+// PotentialOnGridZ evaluates a complex potential, such as an absorber, on the real grid points
+func (g *RadGrid) PotentialOnGridZ(pot PotentialOp[complex128]) []complex128 {
+	rValues := g.RValues()
+	zValues := make([]complex128, len(rValues))
+	for i, r := range rValues {
+		zValues[i] = complex(r, 0)
+	}
+	return pot.EvaluateOnGrid(zValues)
+}
+
 func (g *RadGrid) DisplayPotentialRe(Pot PotentialOp[float64], format string) {
 	displayFunc(g, Pot, format, g.PotentialAtR)
 }
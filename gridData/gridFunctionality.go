@@ -144,13 +144,16 @@ func generatePoints(g getGridData) []float64 {
 }
 
 func generateConjugatePoints(g getGridData) []float64 {
-	nby2 := g.getNgrid() / 2
-	values := make([]float64, g.getNgrid())
-	values[0] = 0.
-	values[g.getNgrid()/2] = -float64(nby2) * g.getdCS()
-	for i := uint32(1); i < nby2; i++ {
-		values[i] = -float64(i) * g.getdCS()
-		values[i+nby2] = float64(nby2-i) * g.getdCS()
+	// FFT order, k_i = i dk for the forward transform exp(-i k x); for odd N the frequencies
+	// run to +-(N-1)/2, for even N the Nyquist term is -N/2
+	n := g.getNgrid()
+	values := make([]float64, n)
+	for i := uint32(1); i < n; i++ {
+		if 2*i < n {
+			values[i] = float64(i) * g.getdCS()
+		} else {
+			values[i] = -float64(n-i) * g.getdCS()
+		}
 	}
 	return values
 }
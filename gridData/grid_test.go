@@ -1,6 +1,10 @@
 package gridData
 
 import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -38,6 +42,26 @@ func TestNewFromLength(t *testing.T) {
 	}
 }
 
+func TestRadGrid_KValues(t *testing.T) {
+	for n, expected := range map[uint32][]float64{
+		4: {0, 1, -2, -1},
+		5: {0, 1, 2, -2, -1},
+	} {
+		grid, err := NewFromLength(2*math.Pi, n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// dk = 2 pi/L = 1
+		k := grid.KValues()
+		for i := range expected {
+			if math.Abs(k[i]-expected[i]) > 1e-12 {
+				t.Errorf("%d points: expected k = %v, got %v", n, expected, k)
+				break
+			}
+		}
+	}
+}
+
 func TestTimeGridFromLength(t *testing.T) {
 	grid, err := NewTimeGrid(10, 100, 100)
 	if err != nil {
@@ -92,3 +116,22 @@ func TestNewTGridFromFile(t *testing.T) {
 
 	grid.DisplayInfo()
 }
+
+func TestNewRGridFromFile_Errors(t *testing.T) {
+	cases := map[string]string{
+		"rMin: -3.\nrMax: three\nnPoints: 18\n":       "rgrid.inp:2: rMax must be a number",
+		"rMin: -3.\nrMax: 3.\n":                       "missing key \"nPoints\"",
+		"rMin: -3.\nrMax: 3.\nnPoints: 18\nrmin: 1\n": "rgrid.inp:4: unknown key \"rmin\"",
+		"rMin -3.\n": "rgrid.inp:1: expected `key: value`",
+	}
+	for content, expected := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "rgrid.inp"), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := NewRGridFromFile(dir)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %v", expected, err)
+		}
+	}
+}
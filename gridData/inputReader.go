@@ -0,0 +1,99 @@
+package gridData
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// inputEntry is one `key: value` line of an input file
+type inputEntry struct {
+	value string
+	line  int
+}
+
+// inputFile holds the entries of a `key: value` input file for strict, line-numbered parsing
+type inputFile struct {
+	path    string
+	entries map[string]inputEntry
+}
+
+// readInputFile reads a `key: value` file with '#' comments. Malformed lines, unknown
+// and repeated keys are errors, as is any of the allowed keys missing.
+func readInputFile(dirPath, fileName string, keys ...string) (*inputFile, error) {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("directory check failed: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dirPath)
+	}
+
+	filePath := dirPath + "/" + fileName
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	allowed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		allowed[key] = true
+	}
+
+	input := &inputFile{path: filePath, entries: make(map[string]inputEntry)}
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected `key: value`, got %q", filePath, lineNo, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if !allowed[key] {
+			return nil, fmt.Errorf("%s:%d: unknown key %q", filePath, lineNo, key)
+		}
+		if prev, ok := input.entries[key]; ok {
+			return nil, fmt.Errorf("%s:%d: %s already set on line %d", filePath, lineNo, key, prev.line)
+		}
+		input.entries[key] = inputEntry{value: value, line: lineNo}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", filePath, err)
+	}
+
+	for _, key := range keys {
+		if _, ok := input.entries[key]; !ok {
+			return nil, fmt.Errorf("%s: missing key %q", filePath, key)
+		}
+	}
+	return input, nil
+}
+
+func (in *inputFile) float(key string) (float64, error) {
+	entry := in.entries[key]
+	value, err := strconv.ParseFloat(entry.value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%d: %s must be a number, got %q", in.path, entry.line, key, entry.value)
+	}
+	return value, nil
+}
+
+func (in *inputFile) count(key string) (uint32, error) {
+	entry := in.entries[key]
+	value, err := strconv.ParseUint(entry.value, 10, 32)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("%s:%d: %s must be a positive integer, got %q", in.path, entry.line, key, entry.value)
+	}
+	return uint32(value), nil
+}
@@ -711,3 +711,22 @@ func (p PolynomialZ64) forceAt(x complex128) complex128 {
 	}
 	return -result
 }
+
+// Centrifugal v(r)= J(J+1) / (2 Mass r^2), the rotational barrier of a diatomic with angular momentum J
+type Centrifugal[T VarType] struct {
+	J    int
+	Mass float64
+}
+
+func (c Centrifugal[T]) String() string {
+	return fmt.Sprintf("J(J+1) / (2 m r^2), where, J: %d, m: %g", c.J, c.Mass)
+}
+
+// rotational returns J(J+1)/m
+func (c Centrifugal[T]) rotational() float64 { return float64(c.J*(c.J+1)) / c.Mass }
+
+func (c Centrifugal[T]) EvaluateAt(x T) T { return fromReal[T](c.rotational()/2) / (x * x) }
+func (c Centrifugal[T]) ForceAt(x T) T    { return fromReal[T](c.rotational()) / (x * x * x) }
+
+func (c Centrifugal[T]) EvaluateOnGrid(x []T) []T { return onGrid(c.EvaluateAt, x) }
+func (c Centrifugal[T]) ForceOnGrid(x []T) []T    { return onGrid(c.ForceAt, x) }
@@ -0,0 +1,106 @@
+package gridData
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sum v(x)= Sum_i v_i(x)
+type Sum[T VarType] struct {
+	Terms []PotentialOp[T]
+}
+
+func (s Sum[T]) String() string {
+	if len(s.Terms) == 0 {
+		return "0"
+	}
+	terms := make([]string, len(s.Terms))
+	for i, term := range s.Terms {
+		terms[i] = fmt.Sprintf("[%v]", term)
+	}
+	return strings.Join(terms, " + ")
+}
+
+func (s Sum[T]) EvaluateAt(x T) T {
+	var result T
+	for _, term := range s.Terms {
+		result += term.EvaluateAt(x)
+	}
+	return result
+}
+
+func (s Sum[T]) ForceAt(x T) T {
+	var result T
+	for _, term := range s.Terms {
+		result += term.ForceAt(x)
+	}
+	return result
+}
+
+func (s Sum[T]) EvaluateOnGrid(x []T) []T { return onGrid(s.EvaluateAt, x) }
+func (s Sum[T]) ForceOnGrid(x []T) []T    { return onGrid(s.ForceAt, x) }
+
+// Scale v(x)= Factor u(x)
+type Scale[T VarType] struct {
+	Factor float64
+	Pot    PotentialOp[T]
+}
+
+func (s Scale[T]) String() string { return fmt.Sprintf("%g [%v]", s.Factor, s.Pot) }
+
+func (s Scale[T]) EvaluateAt(x T) T { return fromReal[T](s.Factor) * s.Pot.EvaluateAt(x) }
+func (s Scale[T]) ForceAt(x T) T    { return fromReal[T](s.Factor) * s.Pot.ForceAt(x) }
+
+func (s Scale[T]) EvaluateOnGrid(x []T) []T { return onGrid(s.EvaluateAt, x) }
+func (s Scale[T]) ForceOnGrid(x []T) []T    { return onGrid(s.ForceAt, x) }
+
+// Shift v(x)= u(x - Offset) + Energy, moving u by Offset along x and by Energy in value
+type Shift[T VarType] struct {
+	Offset float64
+	Energy float64
+	Pot    PotentialOp[T]
+}
+
+func (s Shift[T]) String() string {
+	return fmt.Sprintf("[%v](x - %g) + %g", s.Pot, s.Offset, s.Energy)
+}
+
+func (s Shift[T]) EvaluateAt(x T) T {
+	return s.Pot.EvaluateAt(x-fromReal[T](s.Offset)) + fromReal[T](s.Energy)
+}
+
+func (s Shift[T]) ForceAt(x T) T { return s.Pot.ForceAt(x - fromReal[T](s.Offset)) }
+
+func (s Shift[T]) EvaluateOnGrid(x []T) []T { return onGrid(s.EvaluateAt, x) }
+func (s Shift[T]) ForceOnGrid(x []T) []T    { return onGrid(s.ForceAt, x) }
+
+// Product v(x)= u(x) w(x), with force -(u w)' = F_u w + u F_w
+type Product[T VarType] struct {
+	Left  PotentialOp[T]
+	Right PotentialOp[T]
+}
+
+func (p Product[T]) String() string { return fmt.Sprintf("[%v] * [%v]", p.Left, p.Right) }
+
+func (p Product[T]) EvaluateAt(x T) T { return p.Left.EvaluateAt(x) * p.Right.EvaluateAt(x) }
+
+func (p Product[T]) ForceAt(x T) T {
+	return p.Left.ForceAt(x)*p.Right.EvaluateAt(x) + p.Left.EvaluateAt(x)*p.Right.ForceAt(x)
+}
+
+func (p Product[T]) EvaluateOnGrid(x []T) []T { return onGrid(p.EvaluateAt, x) }
+func (p Product[T]) ForceOnGrid(x []T) []T    { return onGrid(p.ForceAt, x) }
+
+// fromReal converts a real parameter to the potential's value type
+func fromReal[T VarType](x float64) T {
+	var result any
+	switch any(*new(T)).(type) {
+	case float64:
+		result = x
+	case complex128:
+		result = complex(x, 0)
+	default:
+		panic("unsupported type")
+	}
+	return result.(T)
+}
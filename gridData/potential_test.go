@@ -1,6 +1,9 @@
 package gridData
 
 import (
+	"math"
+	"math/cmplx"
+	"strings"
 	"testing"
 )
 
@@ -20,3 +23,40 @@ func TestGaussian_ForceAt(t *testing.T) {
 		t.Errorf("expected nPoints = %v, got %v", 200, grid.nPoints)
 	}
 }
+
+func TestPotentialAlgebra(t *testing.T) {
+	wellF64 := Sum[float64]{Terms: []PotentialOp[float64]{
+		Harmonic[float64]{ForceConst: 0.5},
+		Scale[float64]{Factor: 2, Pot: Shift[float64]{Offset: 1, Energy: -3, Pot: Morse[float64]{De: 4, Alpha: 0.7}}},
+	}}
+	productF64 := Product[float64]{Left: Harmonic[float64]{Cen: 0.5, ForceConst: 1}, Right: Morse[float64]{De: 2, Alpha: 1}}
+
+	const h = 1e-6
+	for _, pot := range []PotentialOp[float64]{wellF64, productF64, Centrifugal[float64]{J: 3, Mass: 2}} {
+		for _, x := range []float64{-1.5, 0.3, 2.} {
+			numeric := -(pot.EvaluateAt(x+h) - pot.EvaluateAt(x-h)) / (2 * h)
+			if math.Abs(pot.ForceAt(x)-numeric) > 1e-6 {
+				t.Errorf("%v: expected force %v at x = %v, got %v", pot, numeric, x, pot.ForceAt(x))
+			}
+		}
+	}
+
+	expected := 0.25*0.09 + 2*(4*math.Pow(1-math.Exp(-0.7*(0.3-1)), 2)-3)
+	if got := wellF64.EvaluateAt(0.3); math.Abs(got-expected) > 1e-12 {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	productZ64 := Product[complex128]{Left: Harmonic[complex128]{Cen: 0.5, ForceConst: 1}, Right: Morse[complex128]{De: 2, Alpha: 1}}
+	z := complex(0.8, 0.3)
+	numeric := -(productZ64.EvaluateAt(z+h) - productZ64.EvaluateAt(z-h)) / (2 * h)
+	if cmplx.Abs(productZ64.ForceAt(z)-numeric) > 1e-6 {
+		t.Errorf("expected force %v at z = %v, got %v", numeric, z, productZ64.ForceAt(z))
+	}
+	if got := productZ64.EvaluateAt(0.8); cmplx.Abs(got-complex(productF64.EvaluateAt(0.8), 0)) > 1e-12 {
+		t.Errorf("expected complex and real products to agree on the real axis, got %v", got)
+	}
+
+	if !strings.Contains(wellF64.String(), " + ") {
+		t.Errorf("expected a readable sum, got %q", wellF64.String())
+	}
+}
@@ -0,0 +1,160 @@
+package gridData
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProductGrid is the tensor product of one RadGrid per axis. Points are flattened in
+// row-major order, the last axis running fastest.
+type ProductGrid struct {
+	axes    []*RadGrid
+	shape   []int
+	strides []int
+	size    int
+}
+
+// PotentialNDOp is a potential V(x, y, z, ...) on a product grid; ForceAt fills
+// force[i] = -dV/dx_i
+type PotentialNDOp[T VarType] interface {
+	EvaluateAt(x []T) T
+	ForceAt(x []T, force []T)
+}
+
+func NewProductGrid(axes ...*RadGrid) (*ProductGrid, error) {
+	if len(axes) == 0 {
+		return nil, fmt.Errorf("a product grid needs at least one axis")
+	}
+
+	shape := make([]int, len(axes))
+	strides := make([]int, len(axes))
+	size := 1
+	for i := len(axes) - 1; i >= 0; i-- {
+		if axes[i] == nil {
+			return nil, fmt.Errorf("axis %d is nil", i)
+		}
+		shape[i] = int(axes[i].NPoints())
+		strides[i] = size
+		size *= shape[i]
+	}
+
+	return &ProductGrid{axes: axes, shape: shape, strides: strides, size: size}, nil
+}
+
+func (g *ProductGrid) String() string {
+	axes := make([]string, len(g.axes))
+	for i, axis := range g.axes {
+		axes[i] = axis.String()
+	}
+	return fmt.Sprintf("ProductGrid{%s}", strings.Join(axes, " x "))
+}
+
+func (g *ProductGrid) NDims() int          { return len(g.axes) }
+func (g *ProductGrid) NPoints() int        { return g.size }
+func (g *ProductGrid) Axis(i int) *RadGrid { return g.axes[i] }
+func (g *ProductGrid) Shape() []int        { return append([]int(nil), g.shape...) }
+func (g *ProductGrid) Strides() []int      { return append([]int(nil), g.strides...) }
+
+func (g *ProductGrid) RValues(axis int) []float64 { return g.axes[axis].RValues() }
+func (g *ProductGrid) KValues(axis int) []float64 { return g.axes[axis].KValues() }
+
+// DeltaV is the volume element, the product of the axis spacings
+func (g *ProductGrid) DeltaV() float64 {
+	dv := 1.
+	for _, axis := range g.axes {
+		dv *= axis.DeltaR()
+	}
+	return dv
+}
+
+// Index flattens one index per axis into a position in the product grid
+func (g *ProductGrid) Index(idx ...int) int {
+	if len(idx) != len(g.axes) {
+		panic(fmt.Sprintf("expected %d indices, got %d", len(g.axes), len(idx)))
+	}
+	flat := 0
+	for i, j := range idx {
+		if j < 0 || j >= g.shape[i] {
+			panic(fmt.Sprintf("index %d out of range [0, %d) on axis %d", j, g.shape[i], i))
+		}
+		flat += j * g.strides[i]
+	}
+	return flat
+}
+
+// MultiIndex writes the per-axis indices of a flattened position into idx
+func (g *ProductGrid) MultiIndex(flat int, idx []int) {
+	for i, stride := range g.strides {
+		idx[i] = flat / stride
+		flat %= stride
+	}
+}
+
+// Point writes the coordinates of a flattened position into x
+func (g *ProductGrid) Point(flat int, x []float64) {
+	for i, stride := range g.strides {
+		j := flat / stride
+		flat %= stride
+		x[i] = g.axes[i].RMin() + float64(j)*g.axes[i].DeltaR()
+	}
+}
+
+// PotentialOnGrid evaluates V at every point, in flattened order
+func (g *ProductGrid) PotentialOnGrid(pot PotentialNDOp[float64]) []float64 {
+	values := make([]float64, g.size)
+	x := make([]float64, len(g.axes))
+	for flat := range values {
+		g.Point(flat, x)
+		values[flat] = pot.EvaluateAt(x)
+	}
+	return values
+}
+
+// Separable v(x_1, ..., x_n)= Sum_i v_i(x_i)
+type Separable[T VarType] struct {
+	Terms []PotentialOp[T]
+}
+
+func (s Separable[T]) String() string {
+	terms := make([]string, len(s.Terms))
+	for i, term := range s.Terms {
+		terms[i] = fmt.Sprintf("[%v](x%d)", term, i+1)
+	}
+	return strings.Join(terms, " + ")
+}
+
+func (s Separable[T]) EvaluateAt(x []T) T {
+	var result T
+	for i, term := range s.Terms {
+		result += term.EvaluateAt(x[i])
+	}
+	return result
+}
+
+func (s Separable[T]) ForceAt(x []T, force []T) {
+	for i, term := range s.Terms {
+		force[i] = term.ForceAt(x[i])
+	}
+}
+
+// HenonHeiles v(x, y)= (x^2 + y^2)/2 + Lambda (x^2 y - y^3/3), two nonlinearly coupled oscillators
+type HenonHeiles[T VarType] struct {
+	Lambda float64
+}
+
+func (hh HenonHeiles[T]) String() string {
+	return fmt.Sprintf("(x^2 + y^2)/2 + %g (x^2 y - y^3/3)", hh.Lambda)
+}
+
+func (hh HenonHeiles[T]) EvaluateAt(x []T) T {
+	px, py := x[0], x[1]
+	half, third := fromReal[T](0.5), fromReal[T](1./3.)
+	return half*(px*px+py*py) + fromReal[T](hh.Lambda)*(px*px*py-third*py*py*py)
+}
+
+func (hh HenonHeiles[T]) ForceAt(x []T, force []T) {
+	px, py := x[0], x[1]
+	lambda := fromReal[T](hh.Lambda)
+	force[0] = -px - 2*lambda*px*py
+	force[1] = -py - lambda*(px*px-py*py)
+}
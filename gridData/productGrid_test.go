@@ -0,0 +1,89 @@
+package gridData
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestProductGrid_Indexing(t *testing.T) {
+	xGrid, err := NewRGrid(-2, 2, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yGrid, err := NewRGrid(0, 3, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zGrid, err := NewFromLength(10, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := NewProductGrid(xGrid, yGrid, zGrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if grid.NPoints() != 60 || !slices.Equal(grid.Shape(), []int{4, 3, 5}) || !slices.Equal(grid.Strides(), []int{15, 5, 1}) {
+		t.Fatalf("unexpected layout %v, strides %v", grid.Shape(), grid.Strides())
+	}
+	if math.Abs(grid.DeltaV()-xGrid.DeltaR()*yGrid.DeltaR()*zGrid.DeltaR()) > 1e-15 {
+		t.Errorf("unexpected volume element %v", grid.DeltaV())
+	}
+	if !slices.Equal(grid.KValues(2), zGrid.KValues()) {
+		t.Errorf("expected the per-axis k grid of the z axis")
+	}
+
+	idx := make([]int, 3)
+	x := make([]float64, 3)
+	for flat := 0; flat < grid.NPoints(); flat++ {
+		grid.MultiIndex(flat, idx)
+		if got := grid.Index(idx...); got != flat {
+			t.Fatalf("expected index %d, got %d for %v", flat, got, idx)
+		}
+		grid.Point(flat, x)
+		for axis, j := range idx {
+			if x[axis] != grid.RValues(axis)[j] {
+				t.Fatalf("point %d: expected x%d = %v, got %v", flat, axis, grid.RValues(axis)[j], x[axis])
+			}
+		}
+	}
+}
+
+func TestProductGrid_Potentials(t *testing.T) {
+	axis, err := NewFromLength(6, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := NewProductGrid(axis, axis)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	separable := Separable[float64]{Terms: []PotentialOp[float64]{
+		Harmonic[float64]{ForceConst: 1}, Morse[float64]{De: 2, Alpha: 1},
+	}}
+	values := grid.PotentialOnGrid(separable)
+	x := make([]float64, 2)
+	grid.Point(grid.Index(3, 7), x)
+	expected := 0.5*x[0]*x[0] + 2*math.Pow(1-math.Exp(-x[1]), 2)
+	if math.Abs(values[grid.Index(3, 7)]-expected) > 1e-12 {
+		t.Errorf("expected %v, got %v", expected, values[grid.Index(3, 7)])
+	}
+
+	const h = 1e-6
+	force := make([]float64, 2)
+	for _, pot := range []PotentialNDOp[float64]{separable, HenonHeiles[float64]{Lambda: 0.1118}} {
+		point := []float64{0.4, -0.7}
+		pot.ForceAt(point, force)
+		for i := range point {
+			plus, minus := slices.Clone(point), slices.Clone(point)
+			plus[i] += h
+			minus[i] -= h
+			numeric := -(pot.EvaluateAt(plus) - pot.EvaluateAt(minus)) / (2 * h)
+			if math.Abs(force[i]-numeric) > 1e-6 {
+				t.Errorf("%v: expected force %v along x%d, got %v", pot, numeric, i, force[i])
+			}
+		}
+	}
+}
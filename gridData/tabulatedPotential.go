@@ -0,0 +1,173 @@
+package gridData
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/interp"
+)
+
+// Interpolation selects the piecewise-cubic interpolant of a tabulated potential
+type Interpolation int
+
+const (
+	CubicSpline Interpolation = iota // natural cubic spline, continuous second derivative
+	AkimaSpline                      // Akima spline, no overshoot near kinks in the data
+)
+
+// Extrapolation selects how a tabulated potential continues beyond its first or last point
+type Extrapolation int
+
+const (
+	ExtrapolateConstant Extrapolation = iota // hold the end value, zero force
+	ExtrapolateLinear                        // continue along the end tangent of the spline
+)
+
+// Tabulated is a potential interpolated from (r, V) points, e.g. an ab initio scan.
+// ForceAt uses the analytic derivative of the spline.
+type Tabulated struct {
+	r      []float64
+	v      []float64
+	method Interpolation
+	spline interp.DerivativePredictor
+
+	Left  Extrapolation
+	Right Extrapolation
+}
+
+// NewTabulated fits a spline through the points (r[i], v[i]), which need not be sorted
+func NewTabulated(r, v []float64, method Interpolation) (*Tabulated, error) {
+	if len(r) != len(v) {
+		return nil, fmt.Errorf("got %d grid points but %d potential values", len(r), len(v))
+	}
+	if len(r) < 3 {
+		return nil, fmt.Errorf("at least 3 points are needed for a spline, got %d", len(r))
+	}
+
+	order := make([]int, len(r))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return r[order[i]] < r[order[j]] })
+
+	rSorted := make([]float64, len(r))
+	vSorted := make([]float64, len(v))
+	for i, idx := range order {
+		rSorted[i], vSorted[i] = r[idx], v[idx]
+		if i > 0 && rSorted[i] == rSorted[i-1] {
+			return nil, fmt.Errorf("duplicate grid point r = %g", rSorted[i])
+		}
+	}
+
+	var spline interface {
+		interp.DerivativePredictor
+		Fit(xs, ys []float64) error
+	}
+	switch method {
+	case CubicSpline:
+		spline = &interp.NaturalCubic{}
+	case AkimaSpline:
+		spline = &interp.AkimaSpline{}
+	default:
+		return nil, fmt.Errorf("unknown interpolation method %d", method)
+	}
+	if err := spline.Fit(rSorted, vSorted); err != nil {
+		return nil, err
+	}
+
+	return &Tabulated{r: rSorted, v: vSorted, method: method, spline: spline}, nil
+}
+
+// NewTabulatedFromFile reads two whitespace-separated columns r and V, skipping blank
+// lines and lines starting with '#', the format written by PrintPotentToFileRe
+func NewTabulatedFromFile(filename string, method Interpolation) (*Tabulated, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r, v []float64
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected two columns, got %q", filename, lineNo, line)
+		}
+		ri, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid grid point %q", filename, lineNo, fields[0])
+		}
+		vi, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid potential value %q", filename, lineNo, fields[1])
+		}
+		r = append(r, ri)
+		v = append(v, vi)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	tab, err := NewTabulated(r, v, method)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return tab, nil
+}
+
+func (t *Tabulated) String() string {
+	name := "cubic spline"
+	if t.method == AkimaSpline {
+		name = "Akima spline"
+	}
+	return fmt.Sprintf("%s through %d points in [%g, %g]", name, len(t.r), t.RMin(), t.RMax())
+}
+
+func (t *Tabulated) RMin() float64 { return t.r[0] }
+func (t *Tabulated) RMax() float64 { return t.r[len(t.r)-1] }
+
+func (t *Tabulated) EvaluateAt(x float64) float64 {
+	if edge, mode, outside := t.outside(x); outside {
+		if mode == ExtrapolateLinear {
+			return t.spline.Predict(edge) + t.spline.PredictDerivative(edge)*(x-edge)
+		}
+		return t.spline.Predict(edge)
+	}
+	return t.spline.Predict(x)
+}
+
+func (t *Tabulated) ForceAt(x float64) float64 {
+	if edge, mode, outside := t.outside(x); outside {
+		if mode == ExtrapolateLinear {
+			return -t.spline.PredictDerivative(edge)
+		}
+		return 0
+	}
+	return -t.spline.PredictDerivative(x)
+}
+
+func (t *Tabulated) EvaluateOnGrid(x []float64) []float64 { return onGrid(t.EvaluateAt, x) }
+func (t *Tabulated) ForceOnGrid(x []float64) []float64    { return onGrid(t.ForceAt, x) }
+
+// outside reports whether x lies beyond the table, with the nearest end point and its extrapolation
+func (t *Tabulated) outside(x float64) (float64, Extrapolation, bool) {
+	if x < t.RMin() {
+		return t.RMin(), t.Left, true
+	}
+	if x > t.RMax() {
+		return t.RMax(), t.Right, true
+	}
+	return 0, 0, false
+}
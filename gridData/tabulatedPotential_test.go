@@ -0,0 +1,59 @@
+package gridData
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestTabulated_FromFile(t *testing.T) {
+	grid, err := NewRGrid(-2., 10., 121)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	morse := Morse[float64]{De: 5., Alpha: 0.8}
+	filename := filepath.Join(t.TempDir(), "morse.dat")
+	if err := grid.PrintPotentToFileRe(morse, filename, "%20.12e"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, method := range []Interpolation{CubicSpline, AkimaSpline} {
+		tab, err := NewTabulatedFromFile(filename, method)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tab.RMin() != -2 || math.Abs(tab.RMax()-(grid.RMax()-grid.DeltaR())) > 1e-6 {
+			t.Errorf("%v: unexpected table range", tab)
+		}
+
+		for x := -1.45; x < 9.5; x += 0.5 {
+			if diff := math.Abs(tab.EvaluateAt(x) - morse.EvaluateAt(x)); diff > 1e-3*max(1, math.Abs(morse.EvaluateAt(x))) {
+				t.Errorf("%v: expected V(%v) = %v, got %v", tab, x, morse.EvaluateAt(x), tab.EvaluateAt(x))
+			}
+			if diff := math.Abs(tab.ForceAt(x) - morse.ForceAt(x)); diff > 5e-2*max(1, math.Abs(morse.ForceAt(x))) {
+				t.Errorf("%v: expected F(%v) = %v, got %v", tab, x, morse.ForceAt(x), tab.ForceAt(x))
+			}
+		}
+
+		if tab.EvaluateAt(20) != tab.EvaluateAt(tab.RMax()) || tab.ForceAt(20) != 0 {
+			t.Errorf("%v: expected constant extrapolation by default", tab)
+		}
+		tab.Left = ExtrapolateLinear
+		slope := -tab.ForceAt(-2)
+		if got := tab.EvaluateAt(-3); math.Abs(got-(tab.EvaluateAt(-2)-slope)) > 1e-12 {
+			t.Errorf("%v: expected linear extrapolation, got %v", tab, got)
+		}
+	}
+}
+
+func TestTabulated_Errors(t *testing.T) {
+	if _, err := NewTabulated([]float64{0, 1}, []float64{0, 1}, CubicSpline); err == nil {
+		t.Errorf("expected an error for too few points")
+	}
+	if _, err := NewTabulated([]float64{0, 1, 1}, []float64{0, 1, 2}, CubicSpline); err == nil {
+		t.Errorf("expected an error for duplicate points")
+	}
+	if _, err := NewTabulatedFromFile(filepath.Join(t.TempDir(), "missing.dat"), CubicSpline); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
@@ -1,11 +1,7 @@
 package gridData
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 )
 
 // TimeGrid represents a time-grid definition for time-dependent differential equation solver
@@ -39,53 +35,25 @@ func NewTimeGrid(MacroDT float64, MacroSteps, MicroSteps uint32) (*TimeGrid, err
 }
 
 func NewTGridFromFile(dirPath string) (*TimeGrid, error) {
-	info, err := os.Stat(dirPath)
+	input, err := readInputFile(dirPath, "tgrid.inp", "MacroDT", "MacroSteps", "MicroSteps")
 	if err != nil {
-		return nil, fmt.Errorf("directory check failed: %w", err)
-	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("%s is not a directory", dirPath)
+		return nil, err
 	}
 
-	filePath := dirPath + "/tgrid.inp"
-
-	file, err := os.Open(filePath)
+	MacroDt, err := input.float("MacroDT")
 	if err != nil {
-		return nil, fmt.Errorf("could not open %s: %w", filePath, err)
+		return nil, err
 	}
-
-	var MacroDt float64
-	var MacroSteps int
-	var MicroSteps int
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.Split(line, ":")
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "MacroDT":
-			MacroDt, _ = strconv.ParseFloat(value, 64)
-		case "MacroSteps":
-
-			MacroSteps, _ = strconv.Atoi(value)
-		case "MicroSteps":
-			MicroSteps, _ = strconv.Atoi(value)
-		}
+	MacroSteps, err := input.count("MacroSteps")
+	if err != nil {
+		return nil, err
+	}
+	MicroSteps, err := input.count("MicroSteps")
+	if err != nil {
+		return nil, err
 	}
 
-	return NewTimeGrid(MacroDt, uint32(MacroSteps), uint32(MicroSteps))
+	return NewTimeGrid(MacroDt, MacroSteps, MicroSteps)
 }
 
 func (t *TimeGrid) getMin() float64  { return t.tMin }
@@ -120,6 +88,9 @@ func (t *TimeGrid) Length() float64    { return t.gridData.length }
 func (t *TimeGrid) DOmega() float64    { return t.gridData.deltaCS }
 func (t *TimeGrid) OmegaMin() float64  { return t.gridData.cMin }
 func (t *TimeGrid) OmegaMax() float64  { return t.gridData.cMax }
+func (t *TimeGrid) MacroDt() float64   { return t.macroDt }
+func (t *TimeGrid) MacroSteps() uint32 { return t.macroSteps }
+func (t *TimeGrid) MicroSteps() uint32 { return t.microSteps }
 func (t *TimeGrid) TValues() []float64 { return generatePoints(t) }
 func (t *TimeGrid) WValues() []float64 { return generateConjugatePoints(t) }
 func (t *TimeGrid) DisplayTimeGrid()   { displayGrid(t.TValues) }
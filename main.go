@@ -1,5 +1,62 @@
 package main
 
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const usage = `usage: GoProject <command> [-run dir] [-out dir]
+
+commands:
+  grid-info   print the real-space and time grids of a run
+  eigen       solve for the lowest eigenstates of the Hamiltonian
+  propagate   propagate a Gaussian wavepacket with the split-operator method
+  md          integrate a classical trajectory with velocity Verlet
+
+The run directory holds simulation.inp, or for grid-info only, rgrid.inp and tgrid.inp.
+Results and a summary.txt are written to the output directory (default <run>/output).
+`
+
 func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+
+	commands := map[string]func(*runContext) error{
+		"grid-info": gridInfo,
+		"eigen":     eigen,
+		"propagate": propagate,
+		"md":        molecularDynamics,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	runDir := flags.String("run", ".", "run directory containing the input files")
+	outDir := flags.String("out", "", "output directory (default <run>/output)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	if *outDir == "" {
+		*outDir = filepath.Join(*runDir, "output")
+	}
 
+	return command(&runContext{name: args[0], runDir: *runDir, outDir: *outDir, stdout: stdout})
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const harmonicInput = `[grid]
+rMin = -8.
+rMax = 8.
+nPoints = 64
+
+[time]
+macroDt = 0.5
+macroSteps = 4
+microSteps = 20
+
+[system]
+mass = 1.
+
+[kinetic]
+type = fourier
+
+[potential]
+type = harmonic
+ForceConst = 1.
+
+[solver]
+type = lanczos
+nStates = 3
+
+[initial]
+x0 = 1.
+`
+
+func TestRun(t *testing.T) {
+	runDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(runDir, inputFile), []byte(harmonicInput), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outputs := map[string]string{
+		"grid-info": "summary.txt",
+		"eigen":     "energies.dat",
+		"propagate": "propagation.dat",
+		"md":        "trajectory.dat",
+	}
+	for command, output := range outputs {
+		var stdout bytes.Buffer
+		outDir := filepath.Join(runDir, command)
+		if err := run([]string{command, "-run", runDir, "-out", outDir}, &stdout); err != nil {
+			t.Fatalf("%s: unexpected error: %v", command, err)
+		}
+		for _, name := range []string{output, "summary.txt"} {
+			if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+				t.Errorf("%s: expected %s to be written: %v", command, name, err)
+			}
+		}
+		if command == "eigen" && !strings.Contains(stdout.String(), "E2:          2.5") {
+			t.Errorf("eigen: expected E2 = 2.5 in the output, got\n%s", stdout.String())
+		}
+	}
+
+	// the split-operator propagator has no DVR kinetic energy
+	dvrInput := strings.Replace(harmonicInput, "type = fourier", "type = dvr", 1)
+	if err := os.WriteFile(filepath.Join(runDir, inputFile), []byte(dvrInput), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := run([]string{"propagate", "-run", runDir, "-out", t.TempDir()}, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for propagate with the dvr kinetic representation")
+	}
+
+	if err := run([]string{"optimize"}, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for an unknown command")
+	}
+}